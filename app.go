@@ -0,0 +1,456 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// App represents an application which stores its files under the application named sub directory of
+// the XDG base directories.
+//
+// The accessors which create the directories, such as DataDir and CacheFile, return the error which matches to
+// ErrNoHome if the base directory is not absolute, such as the default ".cache" without the user home directory,
+// instead of creating it under the current directory.
+type App struct {
+	// x is the XDG which resolves the base directories.
+	x *XDG
+	// elems is the segments of application name, such as {"acme", "widgetd"}.
+	elems []string
+	// path is the application directory path relative to the base directories.
+	path string
+
+	// mu guards migrations.
+	mu sync.Mutex
+	// migrations is the registered config migrations keyed by the source version. See RegisterMigration.
+	migrations map[int]configMigration
+}
+
+// NewApp returns the App of name application which resolves the base directories from the real environment.
+//
+// The name can be the vendor-qualified multi segments such as NewApp("acme", "widgetd"). In that case,
+// all path helpers nest the directory accordingly, e.g. $XDG_CONFIG_HOME/acme/widgetd.
+//
+// The leading and trailing white spaces of each segment are trimmed. Each segment must not be empty,
+// and must not contain path separators or be a "." or ".." path element.
+func NewApp(name ...string) (*App, error) {
+	return std.App(name...)
+}
+
+// App returns the App of name application which resolves the base directories by x.
+//
+// See NewApp for the name.
+func (x *XDG) App(name ...string) (*App, error) {
+	if len(name) == 0 {
+		return nil, errors.New("xdgbasedir: empty application name")
+	}
+
+	elems := make([]string, len(name))
+	for i, elem := range name {
+		elem, err := appName(elem)
+		if err != nil {
+			return nil, err
+		}
+		elems[i] = elem
+	}
+
+	return &App{
+		x:     x,
+		elems: elems,
+		path:  filepath.Join(elems...),
+	}, nil
+}
+
+// Name returns the application name. The segments of vendor-qualified name are joined by slash, such as "acme/widgetd".
+func (a *App) Name() string {
+	return strings.Join(a.elems, "/")
+}
+
+// DataHome returns the $XDG_DATA_HOME/<app> directory path. It does not create the directory.
+func (a *App) DataHome() string {
+	return filepath.Join(a.x.DataHome(), a.path)
+}
+
+// ConfigHome returns the $XDG_CONFIG_HOME/<app> directory path. It does not create the directory.
+func (a *App) ConfigHome() string {
+	return filepath.Join(a.x.ConfigHome(), a.path)
+}
+
+// CacheHome returns the $XDG_CACHE_HOME/<app> directory path. It does not create the directory.
+func (a *App) CacheHome() string {
+	return filepath.Join(a.x.CacheHome(), a.path)
+}
+
+// StateHome returns the $XDG_STATE_HOME/<app> directory path. It does not create the directory.
+func (a *App) StateHome() string {
+	return filepath.Join(a.x.StateHome(), a.path)
+}
+
+// RuntimeDir returns the $XDG_RUNTIME_DIR/<app> directory path. It does not create nor validate the directory.
+func (a *App) RuntimeDir() string {
+	return filepath.Join(a.x.RuntimeDir(), a.path)
+}
+
+// DataDir returns the sub directory path under $XDG_DATA_HOME/<app>, and creates it with 0700 if not exists.
+//
+// If sub is empty, DataDir returns the $XDG_DATA_HOME/<app> directory itself.
+func (a *App) DataDir(sub string) (string, error) {
+	return a.dir(a.x.DataHome(), sub)
+}
+
+// DataFile returns the rel file path under $XDG_DATA_HOME/<app>, and creates its parent directories with 0700.
+func (a *App) DataFile(rel string) (string, error) {
+	return a.file(a.x.DataHome(), rel)
+}
+
+// ConfigDir returns the sub directory path under $XDG_CONFIG_HOME/<app>, and creates it with 0700 if not exists.
+//
+// If sub is empty, ConfigDir returns the $XDG_CONFIG_HOME/<app> directory itself.
+func (a *App) ConfigDir(sub string) (string, error) {
+	return a.dir(a.x.ConfigHome(), sub)
+}
+
+// ConfigFile returns the rel file path under $XDG_CONFIG_HOME/<app>, and creates its parent directories with 0700.
+func (a *App) ConfigFile(rel string) (string, error) {
+	return a.file(a.x.ConfigHome(), rel)
+}
+
+// CacheDir returns the sub directory path under $XDG_CACHE_HOME/<app>, and creates it with 0700 if not exists.
+//
+// If sub is empty, CacheDir returns the $XDG_CACHE_HOME/<app> directory itself.
+func (a *App) CacheDir(sub string) (string, error) {
+	return a.dir(a.x.CacheHome(), sub)
+}
+
+// CacheFile returns the rel file path under $XDG_CACHE_HOME/<app>, and creates its parent directories with 0700.
+//
+// CacheFile is safe to call concurrently.
+func (a *App) CacheFile(rel string) (string, error) {
+	return a.file(a.x.CacheHome(), rel)
+}
+
+// StateDir returns the sub directory path under $XDG_STATE_HOME/<app>, and creates it with 0700 if not exists.
+//
+// If sub is empty, StateDir returns the $XDG_STATE_HOME/<app> directory itself.
+func (a *App) StateDir(sub string) (string, error) {
+	return a.dir(a.x.StateHome(), sub)
+}
+
+// StateFile returns the rel file path under $XDG_STATE_HOME/<app>, and creates its parent directories with 0700.
+//
+// It is suitable for the actions history, logs and the application state database.
+func (a *App) StateFile(rel string) (string, error) {
+	return a.file(a.x.StateHome(), rel)
+}
+
+// RuntimeFile returns the rel file path under $XDG_RUNTIME_DIR/<app>, and creates its parent directories with 0700.
+//
+// RuntimeFile is stricter than the other helpers, because the runtime directory is for sockets, named pipes and lock files.
+// It validates the runtime directory is owned by the user and its access mode is 0700, and creates the <app> directory
+// with 0700 without following symlinks. If no secure runtime directory is available, it returns the *RuntimeDirError.
+func (a *App) RuntimeFile(rel string) (string, error) {
+	rel, err := cleanRel(rel)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := a.ensureRuntimeDir()
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, rel)
+	// the <app> directory is only writable by the user, so the symlink can't be placed by the others
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// RuntimePath is same as RuntimeFile, but also verifies the resulting path fits in the unix domain socket address
+// limit (104 bytes on darwin and BSDs, 108 bytes on linux). It returns the error wraps ErrSocketPathTooLong if not fits.
+func (a *App) RuntimePath(rel string) (string, error) {
+	path, err := a.RuntimeFile(rel)
+	if err != nil {
+		return "", err
+	}
+	if err := checkSocketPath(runtime.GOOS, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// ensureRuntimeDir validates the runtime directory, and creates the $XDG_RUNTIME_DIR/<app> directory with 0700
+// without following symlinks.
+func (a *App) ensureRuntimeDir() (string, error) {
+	dir := a.x.RuntimeDir()
+	if err := validateRuntimeDir(dir); err != nil {
+		return "", err
+	}
+	for _, elem := range a.elems {
+		dir = filepath.Join(dir, elem)
+		if err := mkdirSecure(dir); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// SocketPath returns the unix domain socket path $XDG_RUNTIME_DIR/<app>/<name>.sock, and creates the <app> directory
+// with 0700. The ".sock" extension is not appended if name already has it.
+//
+// The runtime directory is validated same as RuntimeFile. If the resulting path exceeds the platform's sun_path limit,
+// SocketPath returns the error wraps ErrSocketPathTooLong, so the callers can fall back to the abstract socket or
+// the shorter location instead of the baffling "invalid argument" from net.Listen.
+func (a *App) SocketPath(name string) (string, error) {
+	if filepath.Ext(name) != ".sock" {
+		name += ".sock"
+	}
+	return a.RuntimePath(name)
+}
+
+// SearchDataFile searches the rel file in $XDG_DATA_HOME/<app> first, and then each entry of $XDG_DATA_DIRS/<app>
+// in order, and returns the first existing regular file path. It follows symlinks.
+//
+// If the file is not found, SearchDataFile returns the *NotFoundError which carries the list of paths tried.
+func (a *App) SearchDataFile(rel string) (string, error) {
+	return a.search(a.x.DataDirsAll, Finder.SearchDataFile, rel)
+}
+
+// SearchConfigFile searches the rel file in $XDG_CONFIG_HOME/<app> first, and then each entry of $XDG_CONFIG_DIRS/<app>
+// in order, and returns the first existing regular file path. It follows symlinks.
+//
+// The empty and relative entries of $XDG_CONFIG_DIRS are ignored. If the file is not found, SearchConfigFile returns
+// the *NotFoundError which carries the list of candidate paths.
+func (a *App) SearchConfigFile(rel string) (string, error) {
+	return a.search(a.x.ConfigDirsAll, Finder.SearchConfigFile, rel)
+}
+
+// FindDataFiles returns all existing regular files of rel in $XDG_DATA_HOME/<app> and each entry of
+// $XDG_DATA_DIRS/<app>.
+//
+// The result is ordered most-important-first, the same precedence as SearchDataFile. So the first element is the
+// file which SearchDataFile returns, and the callers merging the layered files should apply them in reverse order.
+// The nonexistent entries are skipped, and the same directory appeared twice in the environment is searched once.
+// FindDataFiles returns the empty result without error if no file is found.
+func (a *App) FindDataFiles(rel string) ([]string, error) {
+	return a.find(a.x.DataDirsAll, Finder.FindDataFiles, rel)
+}
+
+// FindConfigFiles returns all existing regular files of rel in $XDG_CONFIG_HOME/<app> and each entry of
+// $XDG_CONFIG_DIRS/<app>.
+//
+// The result is ordered most-important-first, the same precedence as SearchConfigFile. See FindDataFiles for details.
+func (a *App) FindConfigFiles(rel string) ([]string, error) {
+	return a.find(a.x.ConfigDirsAll, Finder.FindConfigFiles, rel)
+}
+
+// ReadDataFile searches the rel file same as SearchDataFile, and returns its contents and the path it came from.
+//
+// If the file is not found, ReadDataFile returns the *NotFoundError which carries the list of candidate paths.
+func (a *App) ReadDataFile(rel string) ([]byte, string, error) {
+	return a.read(a.x.DataDirsAll, Finder.SearchDataFile, rel)
+}
+
+// ReadConfigFile searches the rel file same as SearchConfigFile, and returns its contents and the path it came from,
+// such as "/etc/xdg/myapp/config.toml" for the log message.
+//
+// If the file is not found, ReadConfigFile returns the *NotFoundError which carries the list of candidate paths.
+func (a *App) ReadConfigFile(rel string) ([]byte, string, error) {
+	return a.read(a.x.ConfigDirsAll, Finder.SearchConfigFile, rel)
+}
+
+// DataFS returns the read-only union filesystem over $XDG_DATA_HOME/<app> and each entry of $XDG_DATA_DIRS/<app>.
+//
+// Opening a file tries $XDG_DATA_HOME/<app> first and then each data directory in order, so the home files shadow
+// the system files. The returned fs.FS also implements fs.ReadDirFS, which merges the directory listings across
+// the layers with shadowing and de-duplication, fs.StatFS, fs.ReadFileFS and fs.GlobFS.
+func (a *App) DataFS() fs.FS {
+	return a.x.unionFS(a.dirs(a.x.DataDirsAll()))
+}
+
+// ConfigFS returns the read-only union filesystem over $XDG_CONFIG_HOME/<app> and each entry of $XDG_CONFIG_DIRS/<app>.
+//
+// See DataFS for the semantics.
+func (a *App) ConfigFS() fs.FS {
+	return a.x.unionFS(a.dirs(a.x.ConfigDirsAll()))
+}
+
+// dirs returns the application directory of each base directories.
+func (a *App) dirs(bases []string) []string {
+	dirs := make([]string, len(bases))
+	for i, base := range bases {
+		dirs[i] = filepath.Join(base, a.path)
+	}
+	return dirs
+}
+
+// find returns all rel files in the application directory of each dirs, or by via if WithFinder is configured.
+func (a *App) find(dirs func() []string, via func(Finder, string) ([]string, error), rel string) ([]string, error) {
+	clean, err := cleanRel(rel)
+	if err != nil {
+		return nil, err
+	}
+	if a.x.finder != nil {
+		return via(a.x.finder, filepath.ToSlash(filepath.Join(a.path, clean)))
+	}
+	return a.x.findFiles(dirs(), filepath.Join(a.path, clean)), nil
+}
+
+// search searches the rel file in the application directory of each dirs, or by via if WithFinder is configured.
+func (a *App) search(dirs func() []string, via func(Finder, string) (string, error), rel string) (string, error) {
+	clean, err := cleanRel(rel)
+	if err != nil {
+		return "", err
+	}
+	if a.x.finder != nil {
+		return via(a.x.finder, filepath.ToSlash(filepath.Join(a.path, clean)))
+	}
+	return a.x.searchFile(dirs(), rel, filepath.Join(a.path, clean))
+}
+
+// read searches the rel file same as search, and reads it.
+func (a *App) read(dirs func() []string, via func(Finder, string) (string, error), rel string) ([]byte, string, error) {
+	path, err := a.search(dirs, via, rel)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := a.x.readFile(path)
+	if err != nil {
+		return nil, path, err
+	}
+	return data, path, nil
+}
+
+// dir returns the sub directory path of the application directory under base, and creates it.
+func (a *App) dir(base, sub string) (string, error) {
+	if err := checkBase(base); err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, a.path)
+	if sub != "" {
+		rel, err := cleanRel(sub)
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(dir, rel)
+	}
+
+	// os.MkdirAll is tolerant of the concurrent creation of same directories, so any error is a real error
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// file returns the rel file path of the application directory under base, and creates its parent directories.
+func (a *App) file(base, rel string) (string, error) {
+	if err := checkBase(base); err != nil {
+		return "", err
+	}
+	rel, err := cleanRel(rel)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(base, a.path, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// checkBase checks the base directory is absolute, so the application directory is never created relative to
+// the current directory.
+func checkBase(base string) error {
+	if !filepath.IsAbs(base) {
+		return fmt.Errorf("xdgbasedir: base directory %q is not absolute: %w", base, ErrNoHome)
+	}
+	return nil
+}
+
+// validName reports whether the name is usable as the application directory name.
+func validName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("xdgbasedir: invalid application name %q", name)
+	}
+	return nil
+}
+
+// appName trims the leading and trailing white spaces of the application name segment, and validates it.
+func appName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if err := validName(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// appHome returns the app directory under base. It returns the error if app is not a valid application name.
+func appHome(base, app string) (string, error) {
+	app, err := appName(app)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, app), nil
+}
+
+// cleanRel validates the relative path rel and returns the cleaned rel. It is the single sanitization layer of
+// all rel path taking helpers, because rel may come from the untrusted input such as the plugin provided names.
+//
+// See cleanRelOS for the rules.
+func cleanRel(rel string) (string, error) {
+	clean, err := cleanRelOS(runtime.GOOS, rel)
+	if err != nil {
+		return "", err
+	}
+	return filepath.FromSlash(clean), nil
+}
+
+// cleanRelOS validates the relative path rel on goos and returns the cleaned slash separated rel.
+//
+// cleanRelOS rejects the empty path, the path which contains NUL, the absolute or rooted path, the drive letter
+// prefix such as "C:" on any goos for the portability, and the path which escapes the base directory after
+// cleaning such as "a/../../b". The "." path is also rejected because it is the base directory itself.
+// The backslash is the path separator on windows, so it is checked same as the slash; on the other goos,
+// the backslash is rejected because the same rel would escape on windows, such as `..\x`.
+func cleanRelOS(goos, rel string) (string, error) {
+	invalid := func() (string, error) {
+		return "", fmt.Errorf("xdgbasedir: invalid relative path %q", rel)
+	}
+
+	if rel == "" || strings.ContainsRune(rel, 0) {
+		return invalid()
+	}
+	s := rel
+	if strings.ContainsRune(s, '\\') {
+		if goos != "windows" {
+			return invalid()
+		}
+		s = strings.ReplaceAll(s, `\`, "/")
+	}
+	// the rooted path, including the UNC path such as "//server/share"
+	if s[0] == '/' {
+		return invalid()
+	}
+	// the drive letter prefix such as "C:" and "C:/"
+	if len(s) >= 2 && s[1] == ':' && ('a' <= s[0] && s[0] <= 'z' || 'A' <= s[0] && s[0] <= 'Z') {
+		return invalid()
+	}
+
+	clean := path.Clean(s)
+	if clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return invalid()
+	}
+	return clean, nil
+}
@@ -0,0 +1,560 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestNewApp(t *testing.T) {
+	tests := []struct {
+		name    string
+		app     string
+		wantErr bool
+	}{
+		{
+			name: "valid name",
+			app:  "myapp",
+		},
+		{
+			name:    "empty name",
+			app:     "",
+			wantErr: true,
+		},
+		{
+			name:    "current directory",
+			app:     ".",
+			wantErr: true,
+		},
+		{
+			name:    "parent directory",
+			app:     "..",
+			wantErr: true,
+		},
+		{
+			name:    "contains slash",
+			app:     "foo/bar",
+			wantErr: true,
+		},
+		{
+			name:    "contains backslash",
+			app:     `foo\bar`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, err := NewApp(tt.app)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewApp(%q) error = %v, wantErr %v", tt.app, err, tt.wantErr)
+			}
+			if err == nil && app.Name() != tt.app {
+				t.Errorf("NewApp(%q).Name() = %v, want %v", tt.app, app.Name(), tt.app)
+			}
+		})
+	}
+}
+
+func TestNewAppVendor(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	tests := []struct {
+		name     string
+		app      []string
+		wantName string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "single name",
+			app:      []string{"widgetd"},
+			wantName: "widgetd",
+			want:     filepath.Join(configHome, "widgetd", "config.toml"),
+		},
+		{
+			name:     "vendor qualified",
+			app:      []string{"acme", "widgetd"},
+			wantName: "acme/widgetd",
+			want:     filepath.Join(configHome, "acme", "widgetd", "config.toml"),
+		},
+		{
+			name:    "no name",
+			app:     nil,
+			wantErr: true,
+		},
+		{
+			name:    "separator inside segment",
+			app:     []string{"acme/widgetd"},
+			wantErr: true,
+		},
+		{
+			name:    "traversal segment",
+			app:     []string{"acme", ".."},
+			wantErr: true,
+		},
+		{
+			name:    "empty vendor",
+			app:     []string{"", "widgetd"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, err := NewApp(tt.app...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewApp(%q) error = %v, wantErr %v", tt.app, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := app.Name(); got != tt.wantName {
+				t.Errorf("Name() = %v, want %v", got, tt.wantName)
+			}
+			got, err := app.ConfigFile("config.toml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ConfigFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXDG_App(t *testing.T) {
+	x := New(WithLookupEnv(mapLookupEnv(map[string]string{
+		"XDG_DATA_HOME":   filepath.Join("/tmp", "data"),
+		"XDG_CONFIG_HOME": filepath.Join("/tmp", "config"),
+		"XDG_CACHE_HOME":  filepath.Join("/tmp", "cache"),
+		"XDG_STATE_HOME":  filepath.Join("/tmp", "state"),
+		"XDG_RUNTIME_DIR": filepath.Join("/tmp", "runtime"),
+	})))
+
+	app, err := x.App(" myapp ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := app.Name(), "myapp"; got != want {
+		t.Errorf("Name() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "DataHome", got: app.DataHome(), want: filepath.Join("/tmp", "data", "myapp")},
+		{name: "ConfigHome", got: app.ConfigHome(), want: filepath.Join("/tmp", "config", "myapp")},
+		{name: "CacheHome", got: app.CacheHome(), want: filepath.Join("/tmp", "cache", "myapp")},
+		{name: "StateHome", got: app.StateHome(), want: filepath.Join("/tmp", "state", "myapp")},
+		{name: "RuntimeDir", got: app.RuntimeDir(), want: filepath.Join("/tmp", "runtime", "myapp")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("%s() = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+
+	for _, name := range []string{"", "  ", "my/app", "..", " .. "} {
+		if _, err := x.App(name); err == nil {
+			t.Errorf("App(%q) expected error", name)
+		}
+	}
+}
+
+func TestApp_CacheFile(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		rel     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "file",
+			rel:  "index.db",
+			want: filepath.Join(cacheHome, "myapp", "index.db"),
+		},
+		{
+			name: "nested file",
+			rel:  "objects/ab/cdef",
+			want: filepath.Join(cacheHome, "myapp", "objects", "ab", "cdef"),
+		},
+		{
+			name:    "empty",
+			rel:     "",
+			wantErr: true,
+		},
+		{
+			name:    "absolute",
+			rel:     filepath.Join(cacheHome, "index.db"),
+			wantErr: true,
+		},
+		{
+			name:    "traversal",
+			rel:     "../other/index.db",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := app.CacheFile(tt.rel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CacheFile(%q) error = %v, wantErr %v", tt.rel, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CacheFile(%q) = %v, want %v", tt.rel, got, tt.want)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			fi, err := os.Stat(filepath.Dir(got))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !fi.IsDir() {
+				t.Errorf("%s is not directory", filepath.Dir(got))
+			}
+			if runtime.GOOS != "windows" && fi.Mode().Perm() != 0700 {
+				t.Errorf("%s mode = %v, want %v", filepath.Dir(got), fi.Mode().Perm(), os.FileMode(0700))
+			}
+		})
+	}
+}
+
+func TestApp_CacheDir(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		sub     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "app directory",
+			sub:  "",
+			want: filepath.Join(cacheHome, "myapp"),
+		},
+		{
+			name: "sub directory",
+			sub:  "thumbnails/large",
+			want: filepath.Join(cacheHome, "myapp", "thumbnails", "large"),
+		},
+		{
+			name:    "traversal",
+			sub:     "..",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := app.CacheDir(tt.sub)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CacheDir(%q) error = %v, wantErr %v", tt.sub, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CacheDir(%q) = %v, want %v", tt.sub, got, tt.want)
+			}
+			if tt.wantErr {
+				return
+			}
+			if fi, err := os.Stat(got); err != nil || !fi.IsDir() {
+				t.Errorf("%s is not created: %v", got, err)
+			}
+		})
+	}
+}
+
+func TestApp_StateFile(t *testing.T) {
+	stateHome := t.TempDir()
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		env     string
+		rel     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "set env",
+			env:  stateHome,
+			rel:  "history",
+			want: filepath.Join(stateHome, "myapp", "history"),
+		},
+		{
+			name: "nested file",
+			env:  stateHome,
+			rel:  "db/usage.sqlite",
+			want: filepath.Join(stateHome, "myapp", "db", "usage.sqlite"),
+		},
+		{
+			name:    "traversal",
+			env:     stateHome,
+			rel:     "../../history",
+			wantErr: true,
+		},
+		{
+			name:    "absolute",
+			env:     stateHome,
+			rel:     filepath.Join(stateHome, "history"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_STATE_HOME", tt.env)
+
+			got, err := app.StateFile(tt.rel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("StateFile(%q) error = %v, wantErr %v", tt.rel, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("StateFile(%q) = %v, want %v", tt.rel, got, tt.want)
+			}
+			if tt.wantErr {
+				return
+			}
+			if fi, err := os.Stat(filepath.Dir(got)); err != nil || !fi.IsDir() {
+				t.Errorf("%s is not created: %v", filepath.Dir(got), err)
+			}
+		})
+	}
+}
+
+func TestApp_StateDirDefault(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("default state home is the $HOME/.local/state only on unix")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Cleanup(Refresh)
+	Refresh()
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := filepath.Join(home, ".local", "state", "myapp")
+	dir, err := app.StateDir("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir != want {
+		t.Errorf("StateDir() = %v, want %v", dir, want)
+	}
+	file, err := app.StateFile("history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(want, "history"); file != want {
+		t.Errorf("StateFile() = %v, want %v", file, want)
+	}
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		t.Errorf("StateDir() did not create %v: %v", dir, err)
+	}
+}
+
+func TestApp_CacheFileConcurrent(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rels := []string{"shared/a/one", "shared/a/two"}
+	errs := make([]error, len(rels))
+	var wg sync.WaitGroup
+	for i, rel := range rels {
+		wg.Add(1)
+		go func(i int, rel string) {
+			defer wg.Done()
+			_, errs[i] = app.CacheFile(rel)
+		}(i, rel)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("CacheFile(%q): %v", rels[i], err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(cacheHome, "myapp", "shared", "a")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestApp_CacheFileError(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+
+	// a regular file in place of the application directory must be reported as is
+	if err := os.WriteFile(filepath.Join(cacheHome, "myapp"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.CacheFile("index.db"); err == nil {
+		t.Error("CacheFile: expected error")
+	}
+}
+
+func TestApp_NoHome(t *testing.T) {
+	// no home variable, so the default base directories are relative such as ".cache"
+	x := New(WithLookupEnv(mapLookupEnv(map[string]string{})))
+	app, err := x.App("xdgbasedir-nohome")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		fn   func() (string, error)
+	}{
+		{name: "DataDir", fn: func() (string, error) { return app.DataDir("") }},
+		{name: "DataFile", fn: func() (string, error) { return app.DataFile("data.db") }},
+		{name: "ConfigDir", fn: func() (string, error) { return app.ConfigDir("conf.d") }},
+		{name: "ConfigFile", fn: func() (string, error) { return app.ConfigFile("config.toml") }},
+		{name: "CacheDir", fn: func() (string, error) { return app.CacheDir("") }},
+		{name: "CacheFile", fn: func() (string, error) { return app.CacheFile("index.db") }},
+		{name: "StateDir", fn: func() (string, error) { return app.StateDir("") }},
+		{name: "StateFile", fn: func() (string, error) { return app.StateFile("history") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := tt.fn(); !errors.Is(err, ErrNoHome) {
+				t.Errorf("%s() = %q, %v, want ErrNoHome", tt.name, got, err)
+			}
+		})
+	}
+	if _, err := os.Stat(filepath.Join(".cache", "xdgbasedir-nohome")); !os.IsNotExist(err) {
+		t.Errorf("the relative cache directory is created: %v", err)
+	}
+}
+
+func Test_cleanRelOS(t *testing.T) {
+	tests := []struct {
+		name    string
+		goos    string
+		rel     string
+		want    string
+		wantErr bool
+	}{
+		{name: "file", goos: "linux", rel: "config.toml", want: "config.toml"},
+		{name: "nested", goos: "linux", rel: "a/b/c", want: "a/b/c"},
+		{name: "current dir element", goos: "linux", rel: "./a/./b", want: "a/b"},
+		{name: "inner parent", goos: "linux", rel: "a/b/../c", want: "a/c"},
+		{name: "trailing slash", goos: "linux", rel: "a/b/", want: "a/b"},
+		{name: "double slash", goos: "linux", rel: "a//b", want: "a/b"},
+		{name: "dot prefixed name", goos: "linux", rel: "..a", want: "..a"},
+		{name: "dot suffixed name", goos: "linux", rel: "a..", want: "a.."},
+		{name: "empty", goos: "linux", rel: "", wantErr: true},
+		{name: "current", goos: "linux", rel: ".", wantErr: true},
+		{name: "cancel out", goos: "linux", rel: "a/..", wantErr: true},
+		{name: "parent", goos: "linux", rel: "..", wantErr: true},
+		{name: "parent file", goos: "linux", rel: "../x", wantErr: true},
+		{name: "escape after inner", goos: "linux", rel: "a/../../b", wantErr: true},
+		{name: "escape with current", goos: "linux", rel: "a/./../..", wantErr: true},
+		{name: "absolute", goos: "linux", rel: "/etc/passwd", wantErr: true},
+		{name: "nul", goos: "linux", rel: "a\x00b", wantErr: true},
+		{name: "backslash on unix", goos: "linux", rel: `a\b`, wantErr: true},
+		{name: "backslash parent on unix", goos: "darwin", rel: `..\x`, wantErr: true},
+		{name: "drive letter on unix", goos: "linux", rel: "C:x", wantErr: true},
+		{name: "backslash separator", goos: "windows", rel: `a\b`, want: "a/b"},
+		{name: "mixed separator", goos: "windows", rel: `a\b/c`, want: "a/b/c"},
+		{name: "backslash parent", goos: "windows", rel: `..\x`, wantErr: true},
+		{name: "backslash escape after inner", goos: "windows", rel: `a\..\..\b`, wantErr: true},
+		{name: "rooted backslash", goos: "windows", rel: `\Windows`, wantErr: true},
+		{name: "drive absolute", goos: "windows", rel: `C:\Windows`, wantErr: true},
+		{name: "drive relative", goos: "windows", rel: "c:x", wantErr: true},
+		{name: "UNC", goos: "windows", rel: `\\server\share\x`, wantErr: true},
+		{name: "UNC slash", goos: "windows", rel: "//server/share/x", wantErr: true},
+		{name: "device path", goos: "windows", rel: `\\?\C:\x`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cleanRelOS(tt.goos, tt.rel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("cleanRelOS(%q, %q) error = %v, wantErr %v", tt.goos, tt.rel, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("cleanRelOS(%q, %q) = %q, want %q", tt.goos, tt.rel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppRelValidation(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(root, "data"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "config"))
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	helpers := []struct {
+		name string
+		fn   func(rel string) error
+	}{
+		{name: "DataFile", fn: func(rel string) error { _, err := app.DataFile(rel); return err }},
+		{name: "ConfigFile", fn: func(rel string) error { _, err := app.ConfigFile(rel); return err }},
+		{name: "SearchDataFile", fn: func(rel string) error { _, err := app.SearchDataFile(rel); return err }},
+		{name: "FindConfigFiles", fn: func(rel string) error { _, err := app.FindConfigFiles(rel); return err }},
+		{name: "ReadConfigFile", fn: func(rel string) error { _, _, err := app.ReadConfigFile(rel); return err }},
+		{name: "WriteConfigFile", fn: func(rel string) error { return app.WriteConfigFile(rel, nil, 0600) }},
+	}
+	for _, h := range helpers {
+		for _, rel := range []string{"", ".", "a/../../b", "a/./../..", `..\x`, filepath.Join(root, "x")} {
+			if err := h.fn(rel); err == nil {
+				t.Errorf("%s(%q) expected error", h.name, rel)
+			}
+		}
+	}
+
+	fsys := app.DataFS()
+	for _, name := range []string{"../x", "/x", "a/../b"} {
+		if _, err := fsys.Open(name); err == nil || !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("DataFS().Open(%q) error = %v, want fs.ErrInvalid", name, err)
+		}
+	}
+}
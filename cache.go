@@ -0,0 +1,270 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// cacheObjectsDir is the sub directory name of the content-addressed cache objects under $XDG_CACHE_HOME/<app>.
+const cacheObjectsDir = "objects"
+
+// CachePolicy represents the policy of App.PurgeCache.
+type CachePolicy struct {
+	// MaxAge removes the files not modified within MaxAge. Zero means no age limit.
+	MaxAge time.Duration
+	// MaxTotalSize removes the oldest modified files until the total size of the cache is under MaxTotalSize bytes.
+	// Zero means no size limit.
+	MaxTotalSize int64
+	// Keep reports whether the file should never be removed. The rel is the slash separated path relative to
+	// the application cache directory. nil keeps nothing.
+	Keep func(rel string, fi fs.FileInfo) bool
+	// DryRun reports the files which would be removed without removing them.
+	DryRun bool
+}
+
+// PurgeStats is the statistics of App.PurgeCache.
+type PurgeStats struct {
+	// Files is the number of removed files.
+	Files int
+	// Bytes is the total size of removed files.
+	Bytes int64
+	// Removed is the paths of removed files, or the files which would be removed in DryRun.
+	Removed []string
+}
+
+// cacheEntry is the purge candidate file.
+type cacheEntry struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// PurgeCache removes the files in the $XDG_CACHE_HOME/<app> directory according to policy.
+//
+// The files older than MaxAge are removed first, and then the oldest modified files are removed until the total
+// size is under MaxTotalSize. The symlinks are never followed, and the files deleted concurrently by the other
+// process are ignored. The directories are kept as is.
+func (a *App) PurgeCache(policy CachePolicy) (*PurgeStats, error) {
+	dir := a.CacheHome()
+
+	var (
+		total      int64
+		candidates []cacheEntry
+	)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		fi, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		total += fi.Size()
+
+		if policy.Keep != nil {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			if policy.Keep(filepath.ToSlash(rel), fi) {
+				return nil
+			}
+		}
+		candidates = append(candidates, cacheEntry{path: path, size: fi.Size(), modTime: fi.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].modTime.Before(candidates[j].modTime) })
+
+	stats := new(PurgeStats)
+	remove := func(e cacheEntry) error {
+		if !policy.DryRun {
+			if err := os.Remove(e.path); err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					total -= e.size
+					return nil
+				}
+				return err
+			}
+		}
+		total -= e.size
+		stats.Files++
+		stats.Bytes += e.size
+		stats.Removed = append(stats.Removed, e.path)
+		return nil
+	}
+
+	rest := candidates[:0]
+	if policy.MaxAge > 0 {
+		deadline := time.Now().Add(-policy.MaxAge)
+		for _, e := range candidates {
+			if !e.modTime.Before(deadline) {
+				rest = append(rest, e)
+				continue
+			}
+			if err := remove(e); err != nil {
+				return stats, err
+			}
+		}
+		candidates = rest
+	}
+
+	if policy.MaxTotalSize > 0 {
+		for _, e := range candidates {
+			if total <= policy.MaxTotalSize {
+				break
+			}
+			if err := remove(e); err != nil {
+				return stats, err
+			}
+		}
+	}
+	return stats, nil
+}
+
+// tempDir is the sub directory name of the temporary files under $XDG_CACHE_HOME/<app>.
+const tempDir = "tmp"
+
+// TempFile creates the new temporary file under $XDG_CACHE_HOME/<app>/tmp with 0600, same as os.CreateTemp.
+//
+// The large intermediate artifacts are on the same filesystem as their final destination under the XDG base
+// directories, unlike os.TempDir which is often a small tmpfs, so the final rename is atomic.
+func (a *App) TempFile(pattern string) (*os.File, error) {
+	dir, err := a.CacheDir(tempDir)
+	if err != nil {
+		return nil, err
+	}
+	return os.CreateTemp(dir, pattern)
+}
+
+// TempDir creates the new temporary directory under $XDG_CACHE_HOME/<app>/tmp with 0700, same as os.MkdirTemp.
+func (a *App) TempDir(pattern string) (string, error) {
+	dir, err := a.CacheDir(tempDir)
+	if err != nil {
+		return "", err
+	}
+	return os.MkdirTemp(dir, pattern)
+}
+
+// CleanTemp removes the temporary files and directories under $XDG_CACHE_HOME/<app>/tmp not modified within
+// olderThan, which are the leftovers from the crashed runs.
+//
+// The entries removed concurrently by the other process are ignored.
+func (a *App) CleanTemp(olderThan time.Duration) error {
+	dir := filepath.Join(a.CacheHome(), tempDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	deadline := time.Now().Add(-olderThan)
+	for _, entry := range entries {
+		fi, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		if !fi.ModTime().Before(deadline) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CachePathForKey returns the content-addressed file path of key under $XDG_CACHE_HOME/<app>/objects, such as for
+// the caches keyed by URL or content hash, and creates its shard directory with 0700.
+//
+// The file name is the lower-case hex SHA-256 of key, and the shard directory is its first two characters, such as
+// $XDG_CACHE_HOME/<app>/objects/0f/0f115db062b7c0dd030b16878c99dea5c354b49dc37b38eb8846179c7783e9d7 for
+// "https://example.com/", so no directory has more than about 1/256 of the objects. The layout is stable across
+// runs and platforms. CachePathForKey does not create the file. Use WalkCacheObjects to iterate the objects.
+func (a *App) CachePathForKey(key string) (string, error) {
+	sum := sha256.Sum256([]byte(key))
+	hash := hex.EncodeToString(sum[:])
+	return a.CacheFile(path.Join(cacheObjectsDir, hash[:2], hash))
+}
+
+// WalkCacheObjects calls fn for each object file created via CachePathForKey, with its hash and path, in the order
+// of the hash.
+//
+// The entries which don't follow the layout, such as the temporary files, are skipped. If fn returns fs.SkipAll,
+// the walk stops and WalkCacheObjects returns nil. Any other error stops the walk and is returned as is. It returns
+// nil if no object has been created. The objects removed by fn are fine, such as the purge logic.
+func (a *App) WalkCacheObjects(fn func(hash, path string) error) error {
+	dir := filepath.Join(a.x.CacheHome(), a.path, cacheObjectsDir)
+	shards, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	for _, shard := range shards {
+		if !shard.IsDir() || len(shard.Name()) != 2 || !isLowerHex(shard.Name()) {
+			continue
+		}
+		objects, err := os.ReadDir(filepath.Join(dir, shard.Name()))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		for _, object := range objects {
+			hash := object.Name()
+			if !object.Type().IsRegular() || len(hash) != sha256.Size*2 || hash[:2] != shard.Name() || !isLowerHex(hash) {
+				continue
+			}
+			if err := fn(hash, filepath.Join(dir, shard.Name(), hash)); err != nil {
+				if err == fs.SkipAll {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isLowerHex reports whether s consists of the lower-case hex digits.
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
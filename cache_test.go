@@ -0,0 +1,354 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupCache creates the cache files which have the size and age in days, and returns the app.
+func setupCache(t *testing.T, files map[string]struct {
+	size int
+	days int
+}) *App {
+	t.Helper()
+
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rel, f := range files {
+		path := filepath.Join(app.CacheHome(), filepath.FromSlash(rel))
+		writeFileContent(t, path, strings.Repeat("x", f.size))
+		mtime := time.Now().Add(-time.Duration(f.days) * 24 * time.Hour)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return app
+}
+
+func TestApp_PurgeCache(t *testing.T) {
+	files := map[string]struct {
+		size int
+		days int
+	}{
+		"old":          {size: 10, days: 30},
+		"objects/mid":  {size: 20, days: 10},
+		"objects/new":  {size: 40, days: 1},
+		"keep/pinned":  {size: 80, days: 60},
+		"objects/last": {size: 5, days: 0},
+	}
+	keep := func(rel string, fi fs.FileInfo) bool { return strings.HasPrefix(rel, "keep/") }
+
+	tests := []struct {
+		name       string
+		policy     CachePolicy
+		wantFiles  []string
+		wantBytes  int64
+		wantRemain bool
+	}{
+		{
+			name:      "max age",
+			policy:    CachePolicy{MaxAge: 7 * 24 * time.Hour, Keep: keep},
+			wantFiles: []string{"objects/mid", "old"},
+			wantBytes: 30,
+		},
+		{
+			name:      "max total size",
+			policy:    CachePolicy{MaxTotalSize: 130, Keep: keep},
+			wantFiles: []string{"objects/mid", "old"},
+			wantBytes: 30,
+		},
+		{
+			name:      "age and size",
+			policy:    CachePolicy{MaxAge: 20 * 24 * time.Hour, MaxTotalSize: 100, Keep: keep},
+			wantFiles: []string{"objects/mid", "objects/new", "old"},
+			wantBytes: 70,
+		},
+		{
+			name:      "without keep",
+			policy:    CachePolicy{MaxAge: 40 * 24 * time.Hour},
+			wantFiles: []string{"keep/pinned"},
+			wantBytes: 80,
+		},
+		{
+			name:       "dry run",
+			policy:     CachePolicy{MaxAge: 7 * 24 * time.Hour, Keep: keep, DryRun: true},
+			wantFiles:  []string{"objects/mid", "old"},
+			wantBytes:  30,
+			wantRemain: true,
+		},
+		{
+			name:   "no limit",
+			policy: CachePolicy{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := setupCache(t, files)
+
+			stats, err := app.PurgeCache(tt.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, path := range stats.Removed {
+				rel, err := filepath.Rel(app.CacheHome(), path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, filepath.ToSlash(rel))
+
+				_, err = os.Stat(path)
+				if exist := err == nil; exist != tt.wantRemain {
+					t.Errorf("%s exists = %v, want %v", rel, exist, tt.wantRemain)
+				}
+			}
+			sort.Strings(got)
+
+			if strings.Join(got, ",") != strings.Join(tt.wantFiles, ",") {
+				t.Errorf("PurgeCache() removed %v, want %v", got, tt.wantFiles)
+			}
+			if stats.Files != len(tt.wantFiles) || stats.Bytes != tt.wantBytes {
+				t.Errorf("PurgeCache() = %d files %d bytes, want %d files %d bytes", stats.Files, stats.Bytes, len(tt.wantFiles), tt.wantBytes)
+			}
+		})
+	}
+}
+
+func TestApp_PurgeCacheSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlink requires the privilege on windows")
+	}
+
+	app := setupCache(t, nil)
+	outside := t.TempDir()
+	writeFileContent(t, filepath.Join(outside, "precious"), "data")
+	if err := os.MkdirAll(app.CacheHome(), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(app.CacheHome(), "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := app.PurgeCache(CachePolicy{MaxTotalSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Files != 0 {
+		t.Errorf("PurgeCache() removed %v, want nothing", stats.Removed)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "precious")); err != nil {
+		t.Errorf("file outside the cache is removed: %v", err)
+	}
+}
+
+func TestApp_PurgeCacheNotExist(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(t.TempDir(), "not-exist"))
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := app.PurgeCache(CachePolicy{MaxAge: time.Hour})
+	if err != nil || stats.Files != 0 {
+		t.Errorf("PurgeCache() = %+v, %v", stats, err)
+	}
+}
+
+func TestApp_TempFile(t *testing.T) {
+	app := setupCache(t, nil)
+	tmp := filepath.Join(app.CacheHome(), "tmp")
+
+	f, err := app.TempFile("artifact-*.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if got := filepath.Dir(f.Name()); got != tmp {
+		t.Errorf("TempFile() dir = %v, want %v", got, tmp)
+	}
+	if !strings.HasPrefix(filepath.Base(f.Name()), "artifact-") || filepath.Ext(f.Name()) != ".bin" {
+		t.Errorf("TempFile() = %v, want artifact-*.bin", f.Name())
+	}
+
+	dir, err := app.TempDir("work-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := filepath.Dir(dir); got != tmp {
+		t.Errorf("TempDir() parent = %v, want %v", got, tmp)
+	}
+
+	if runtime.GOOS != "windows" {
+		for path, want := range map[string]os.FileMode{f.Name(): 0600, dir: 0700, tmp: 0700} {
+			fi, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fi.Mode().Perm() != want {
+				t.Errorf("%s mode = %#o, want %#o", path, fi.Mode().Perm(), want)
+			}
+		}
+	}
+}
+
+func TestApp_CleanTemp(t *testing.T) {
+	app := setupCache(t, map[string]struct {
+		size int
+		days int
+	}{
+		"tmp/stale-file":       {size: 1, days: 2},
+		"tmp/fresh-file":       {size: 1, days: 0},
+		"tmp/stale-dir/nested": {size: 1, days: 2},
+		"index.db":             {size: 1, days: 2},
+	})
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(filepath.Join(app.CacheHome(), "tmp", "stale-dir"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.CleanTemp(24 * time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		rel   string
+		exist bool
+	}{
+		{rel: "tmp/stale-file", exist: false},
+		{rel: "tmp/stale-dir", exist: false},
+		{rel: "tmp/fresh-file", exist: true},
+		{rel: "index.db", exist: true},
+	}
+	for _, tt := range tests {
+		_, err := os.Stat(filepath.Join(app.CacheHome(), filepath.FromSlash(tt.rel)))
+		if exist := err == nil; exist != tt.exist {
+			t.Errorf("%s exists = %v, want %v", tt.rel, exist, tt.exist)
+		}
+	}
+
+	// no tmp directory
+	if err := setupCache(t, nil).CleanTemp(time.Hour); err != nil {
+		t.Errorf("CleanTemp() error = %v", err)
+	}
+}
+
+func TestApp_CachePathForKey(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{
+			name: "empty",
+			key:  "",
+			want: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		},
+		{
+			name: "url",
+			key:  "https://example.com/",
+			want: "0f115db062b7c0dd030b16878c99dea5c354b49dc37b38eb8846179c7783e9d7",
+		},
+		{
+			name: "digest",
+			key:  "sha256:abc",
+			want: "67e9bc3cfd2163c2978358dfe00d2f912cd4ee0c99f077c3583b39b48aebb124",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := app.CachePathForKey(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := filepath.Join(cacheHome, "myapp", "objects", tt.want[:2], tt.want)
+			if got != want {
+				t.Errorf("CachePathForKey(%q) = %q, want %q", tt.key, got, want)
+			}
+			if fi, err := os.Stat(filepath.Dir(got)); err != nil || !fi.IsDir() {
+				t.Errorf("shard directory is not created: %v", err)
+			}
+			if _, err := os.Stat(got); !os.IsNotExist(err) {
+				t.Errorf("object file is created: %v", err)
+			}
+		})
+	}
+}
+
+func TestApp_WalkCacheObjects(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.WalkCacheObjects(func(hash, path string) error {
+		t.Errorf("unexpected object %s", path)
+		return nil
+	}); err != nil {
+		t.Fatalf("WalkCacheObjects() without objects error = %v", err)
+	}
+
+	want := map[string]string{}
+	for _, key := range []string{"https://example.com/", "", "sha256:abc"} {
+		path, err := app.CachePathForKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeFileContent(t, path, key)
+		want[filepath.Base(path)] = path
+	}
+	objects := filepath.Join(app.CacheHome(), "objects")
+	// the entries which don't follow the layout
+	writeFileContent(t, filepath.Join(objects, "0f", "0f115db0.tmp"), "")
+	writeFileContent(t, filepath.Join(objects, "0f", strings.Repeat("a", 64)), "")
+	writeFileContent(t, filepath.Join(objects, "zz", strings.Repeat("z", 64)), "")
+	writeFileContent(t, filepath.Join(objects, "README"), "")
+
+	var hashes []string
+	if err := app.WalkCacheObjects(func(hash, path string) error {
+		if want[hash] != path {
+			t.Errorf("WalkCacheObjects() path of %s = %q, want %q", hash, path, want[hash])
+		}
+		hashes = append(hashes, hash)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	wantHashes := []string{
+		"0f115db062b7c0dd030b16878c99dea5c354b49dc37b38eb8846179c7783e9d7",
+		"67e9bc3cfd2163c2978358dfe00d2f912cd4ee0c99f077c3583b39b48aebb124",
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	}
+	if strings.Join(hashes, ",") != strings.Join(wantHashes, ",") {
+		t.Errorf("WalkCacheObjects() = %v, want %v", hashes, wantHashes)
+	}
+
+	var n int
+	if err := app.WalkCacheObjects(func(hash, path string) error {
+		n++
+		return fs.SkipAll
+	}); err != nil || n != 1 {
+		t.Errorf("WalkCacheObjects() with SkipAll = %d calls, %v, want 1 call, nil", n, err)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// configBackupDir is the sub directory name of the pre-migration config backups under $XDG_STATE_HOME/<app>.
+const configBackupDir = "config-backup"
+
+// configMigration is the registered config migration step.
+type configMigration struct {
+	to int
+	fn func([]byte) ([]byte, error)
+}
+
+// ConfigMigration is the result of App.MigrateConfig.
+type ConfigMigration struct {
+	// Path is the migrated user config file path under $XDG_CONFIG_HOME/<app>.
+	Path string
+	// From is the version of the file before the migration.
+	From int
+	// To is the version of the file after the migration. It equals From if no migration is applied.
+	To int
+	// Backup is the backup file path of the pre-migration file under $XDG_STATE_HOME/<app>.
+	// It is empty if no migration is applied, or in PreviewMigrateConfig.
+	Backup string
+	// Data is the migrated content.
+	Data []byte
+}
+
+// RegisterMigration registers the fn config migration which converts the content of version from to version to.
+//
+// The migrations are chained by MigrateConfig, so registering 1 to 2 and 2 to 3 migrates the version 1 file to 3.
+// RegisterMigration panics if to is not greater than from, fn is nil, or the migration from the same version
+// is already registered.
+func (a *App) RegisterMigration(from, to int, fn func([]byte) ([]byte, error)) {
+	if to <= from {
+		panic(fmt.Sprintf("xdgbasedir: invalid migration from %d to %d", from, to))
+	}
+	if fn == nil {
+		panic("xdgbasedir: nil migration")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, dup := a.migrations[from]; dup {
+		panic(fmt.Sprintf("xdgbasedir: multiple migrations from %d", from))
+	}
+	if a.migrations == nil {
+		a.migrations = make(map[int]configMigration)
+	}
+	a.migrations[from] = configMigration{to: to, fn: fn}
+}
+
+// MigrateConfig applies the registered migrations to the rel user config file under $XDG_CONFIG_HOME/<app>
+// in sequence, starting from the version currentVersion reports. The system-provided files in $XDG_CONFIG_DIRS
+// are never touched.
+//
+// The migrated content is written atomically same as WriteConfigFile, and the pre-migration file is kept under
+// $XDG_STATE_HOME/<app>/config-backup with the version and timestamp suffix. If any migration fails, nothing is
+// written and the original file is left as is. MigrateConfig is idempotent: if no migration is registered for
+// the current version, it does nothing. Each migrated content must report the version of its migration by
+// currentVersion, otherwise the error is returned.
+//
+// If the file does not exist, MigrateConfig returns the error which satisfies errors.Is(err, fs.ErrNotExist).
+// Use PreviewMigrateConfig for the dry-run.
+func (a *App) MigrateConfig(rel string, currentVersion func([]byte) (int, error)) (*ConfigMigration, error) {
+	return a.migrateConfig(false, rel, currentVersion)
+}
+
+// PreviewMigrateConfig returns what MigrateConfig would write without writing the file and its backup.
+func (a *App) PreviewMigrateConfig(rel string, currentVersion func([]byte) (int, error)) (*ConfigMigration, error) {
+	return a.migrateConfig(true, rel, currentVersion)
+}
+
+// migrateConfig migrates the rel user config file, or only computes the result if dryRun.
+func (a *App) migrateConfig(dryRun bool, rel string, currentVersion func([]byte) (int, error)) (*ConfigMigration, error) {
+	rel, err := cleanRel(rel)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(a.ConfigHome(), rel)
+
+	orig, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	from, err := currentVersion(orig)
+	if err != nil {
+		return nil, fmt.Errorf("xdgbasedir: version of %s: %w", path, err)
+	}
+
+	a.mu.Lock()
+	migrations := make(map[int]configMigration, len(a.migrations))
+	for v, m := range a.migrations {
+		migrations[v] = m
+	}
+	a.mu.Unlock()
+
+	// the migration may modify data in place, and orig is written to the backup
+	data, version := bytes.Clone(orig), from
+	for {
+		m, ok := migrations[version]
+		if !ok {
+			break
+		}
+		if data, err = m.fn(data); err != nil {
+			return nil, fmt.Errorf("xdgbasedir: migrate %s from %d to %d: %w", path, version, m.to, err)
+		}
+		got, err := currentVersion(data)
+		if err != nil {
+			return nil, fmt.Errorf("xdgbasedir: version of %s migrated to %d: %w", path, m.to, err)
+		}
+		if got != m.to {
+			return nil, fmt.Errorf("xdgbasedir: migrate %s from %d to %d: got version %d", path, version, m.to, got)
+		}
+		version = m.to
+	}
+
+	result := &ConfigMigration{Path: path, From: from, To: version, Data: data}
+	if dryRun || version == from {
+		return result, nil
+	}
+
+	name := fmt.Sprintf("%s.v%d.%s", rel, from, time.Now().UTC().Format("20060102T150405.000000000Z"))
+	backup, err := a.StateFile(filepath.Join(configBackupDir, name))
+	if err != nil {
+		return nil, err
+	}
+	w, err := createAtomic(backup, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeAtomic(w, orig); err != nil {
+		return nil, err
+	}
+	result.Backup = backup
+
+	w, err = createAtomic(path, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeAtomic(w, data); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,223 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// configVersion parses the "version=N" first line of the test config.
+func configVersion(data []byte) (int, error) {
+	line, _, _ := strings.Cut(string(data), "\n")
+	v, ok := strings.CutPrefix(line, "version=")
+	if !ok {
+		return 0, errors.New("no version")
+	}
+	return strconv.Atoi(v)
+}
+
+// bumpVersion returns the migration which replaces the version line with to, and appends the line.
+func bumpVersion(from, to int, line string) func([]byte) ([]byte, error) {
+	return func(data []byte) ([]byte, error) {
+		data = bytes.Replace(data, []byte("version="+strconv.Itoa(from)), []byte("version="+strconv.Itoa(to)), 1)
+		return append(data, line+"\n"...), nil
+	}
+}
+
+func TestApp_MigrateConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		preview    bool
+		register   func(*App)
+		want       string
+		wantFrom   int
+		wantTo     int
+		wantBackup bool
+		wantErr    bool
+	}{
+		{
+			name:    "chain",
+			content: "version=1\n",
+			register: func(app *App) {
+				app.RegisterMigration(2, 3, bumpVersion(2, 3, "b"))
+				app.RegisterMigration(1, 2, bumpVersion(1, 2, "a"))
+			},
+			want:       "version=3\na\nb\n",
+			wantFrom:   1,
+			wantTo:     3,
+			wantBackup: true,
+		},
+		{
+			name:    "in place",
+			content: "version=1\n",
+			register: func(app *App) {
+				app.RegisterMigration(1, 2, func(data []byte) ([]byte, error) {
+					// the backup must not see the edit
+					data[len("version=")] = '2'
+					return data, nil
+				})
+			},
+			want:       "version=2\n",
+			wantFrom:   1,
+			wantTo:     2,
+			wantBackup: true,
+		},
+		{
+			name:    "up to date",
+			content: "version=3\n",
+			register: func(app *App) {
+				app.RegisterMigration(1, 2, bumpVersion(1, 2, "a"))
+			},
+			want:     "version=3\n",
+			wantFrom: 3,
+			wantTo:   3,
+		},
+		{
+			name:    "preview",
+			content: "version=1\n",
+			preview: true,
+			register: func(app *App) {
+				app.RegisterMigration(1, 2, bumpVersion(1, 2, "a"))
+			},
+			want:     "version=1\n",
+			wantFrom: 1,
+			wantTo:   2,
+		},
+		{
+			name:    "partial failure",
+			content: "version=1\n",
+			register: func(app *App) {
+				app.RegisterMigration(1, 2, bumpVersion(1, 2, "a"))
+				app.RegisterMigration(2, 3, func([]byte) ([]byte, error) { return nil, errors.New("broken") })
+			},
+			want:    "version=1\n",
+			wantErr: true,
+		},
+		{
+			name:    "version not bumped",
+			content: "version=1\n",
+			register: func(app *App) {
+				app.RegisterMigration(1, 2, func(data []byte) ([]byte, error) { return data, nil })
+			},
+			want:    "version=1\n",
+			wantErr: true,
+		},
+		{
+			name:     "unknown version",
+			content:  "[core]\n",
+			register: func(*App) {},
+			want:     "[core]\n",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "config"))
+			t.Setenv("XDG_STATE_HOME", filepath.Join(root, "state"))
+			path := filepath.Join(root, "config", "myapp", "config.toml")
+			writeFileContent(t, path, tt.content)
+
+			app, err := NewApp("myapp")
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.register(app)
+
+			migrate := app.MigrateConfig
+			if tt.preview {
+				migrate = app.PreviewMigrateConfig
+			}
+			got, err := migrate("config.toml", configVersion)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MigrateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if s := readString(t, path); s != tt.want {
+				t.Errorf("content = %q, want %q", s, tt.want)
+			}
+			if _, err := os.Stat(filepath.Join(root, "state", "myapp", configBackupDir)); (err == nil) != tt.wantBackup {
+				t.Errorf("backup exists = %v, want %v", err == nil, tt.wantBackup)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got.Path != path || got.From != tt.wantFrom || got.To != tt.wantTo {
+				t.Errorf("MigrateConfig() = %+v, want %s from %d to %d", got, path, tt.wantFrom, tt.wantTo)
+			}
+			if (got.Backup != "") != tt.wantBackup {
+				t.Errorf("Backup = %q, want backup %v", got.Backup, tt.wantBackup)
+			}
+			if tt.wantBackup {
+				if s := readString(t, got.Backup); s != tt.content {
+					t.Errorf("backup content = %q, want %q", s, tt.content)
+				}
+				if filepath.Dir(got.Backup) != filepath.Join(root, "state", "myapp", configBackupDir) ||
+					!strings.HasPrefix(filepath.Base(got.Backup), "config.toml.v1.") {
+					t.Errorf("Backup = %q", got.Backup)
+				}
+
+				// idempotent
+				again, err := app.MigrateConfig("config.toml", configVersion)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if again.From != tt.wantTo || again.To != tt.wantTo || again.Backup != "" {
+					t.Errorf("second MigrateConfig() = %+v, want no migration", again)
+				}
+			}
+		})
+	}
+}
+
+func TestApp_MigrateConfigNotExist(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.MigrateConfig("config.toml", configVersion); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("MigrateConfig() error = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestApp_RegisterMigrationPanic(t *testing.T) {
+	noop := func(data []byte) ([]byte, error) { return data, nil }
+
+	tests := []struct {
+		name     string
+		register func(*App)
+	}{
+		{name: "not forward", register: func(app *App) { app.RegisterMigration(2, 2, noop) }},
+		{name: "nil", register: func(app *App) { app.RegisterMigration(1, 2, nil) }},
+		{name: "duplicate", register: func(app *App) {
+			app.RegisterMigration(1, 2, noop)
+			app.RegisterMigration(1, 3, noop)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, err := NewApp("myapp")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				if recover() == nil {
+					t.Error("RegisterMigration() expected panic")
+				}
+			}()
+			tt.register(app)
+		})
+	}
+}
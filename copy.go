@@ -0,0 +1,120 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+type copyConfig struct {
+	overwrite bool
+}
+
+// CopyOption configures CopyToDataHome and CopyToConfigHome.
+type CopyOption func(*copyConfig)
+
+// Overwrite returns the CopyOption which replaces the existing destination file.
+func Overwrite() CopyOption {
+	return func(c *copyConfig) {
+		c.overwrite = true
+	}
+}
+
+// CopyToDataHome copies the src file into $XDG_DATA_HOME/<rel> and returns the destination path, such as
+// promoting the read-only system data file found by SearchDataFile into the user editable copy.
+//
+// The copy is atomic same as WriteConfigFile, and the parent directories are created with 0700 as needed.
+// The permission bits of src are preserved, but the setuid, setgid and sticky bits are never copied.
+// If the destination exists, CopyToDataHome returns the error which matches to fs.ErrExist unless Overwrite is
+// passed. If src is already inside $XDG_DATA_HOME, it is returned unchanged without copying.
+func CopyToDataHome(src, rel string, opts ...CopyOption) (string, error) {
+	return std.CopyToDataHome(src, rel, opts...)
+}
+
+// CopyToDataHome copies the src file into the data home directory.
+//
+// See the package level CopyToDataHome function for details.
+func (x *XDG) CopyToDataHome(src, rel string, opts ...CopyOption) (string, error) {
+	return copyTo(x.DataHome(), src, rel, opts)
+}
+
+// CopyToConfigHome copies the src file into $XDG_CONFIG_HOME/<rel> and returns the destination path, such as
+// promoting the system default "/etc/xdg/myapp/config.toml" into the user configuration.
+//
+// See CopyToDataHome for the semantics.
+func CopyToConfigHome(src, rel string, opts ...CopyOption) (string, error) {
+	return std.CopyToConfigHome(src, rel, opts...)
+}
+
+// CopyToConfigHome copies the src file into the configuration home directory.
+//
+// See the package level CopyToConfigHome function for details.
+func (x *XDG) CopyToConfigHome(src, rel string, opts ...CopyOption) (string, error) {
+	return copyTo(x.ConfigHome(), src, rel, opts)
+}
+
+// copyTo copies the src file into the rel file under home atomically.
+func copyTo(home, src, rel string, opts []CopyOption) (string, error) {
+	var c copyConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	rel, err := cleanRel(rel)
+	if err != nil {
+		return "", err
+	}
+	if home == "" {
+		return "", fmt.Errorf("xdgbasedir: home directory is not defined")
+	}
+	src = filepath.Clean(src)
+	if r, err := filepath.Rel(home, src); err == nil && r != "." && filepath.IsLocal(r) {
+		return src, nil
+	}
+	dst := filepath.Join(home, rel)
+
+	f, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if !fi.Mode().IsRegular() {
+		return "", &fs.PathError{Op: "copy", Path: src, Err: errNotRegular}
+	}
+
+	// Perm drops the setuid, setgid and sticky bits
+	perm := fi.Mode().Perm()
+	if perm == 0 {
+		perm = 0600
+	}
+	w, err := createAtomic(dst, perm)
+	if err != nil {
+		return "", err
+	}
+	w.exclusive = !c.overwrite
+	if _, err := io.Copy(w, f); err != nil {
+		if w.err == nil {
+			w.err = err
+		}
+		w.Close()
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return "", &fs.PathError{Op: "copy", Path: dst, Err: fs.ErrExist}
+		}
+		return "", err
+	}
+	return dst, nil
+}
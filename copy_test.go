@@ -0,0 +1,191 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestCopyToDataHome(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	system := filepath.Join(root, "system")
+	t.Setenv("XDG_DATA_HOME", home)
+	t.Setenv("XDG_DATA_DIRS", system)
+
+	src := filepath.Join(system, "myapp", "themes", "dark.yaml")
+	writeFileContent(t, src, "system dark")
+	if err := os.Chmod(src, 0640); err != nil {
+		t.Fatal(err)
+	}
+	existing := filepath.Join(home, "myapp", "themes", "light.yaml")
+	writeFileContent(t, existing, "home light")
+
+	tests := []struct {
+		name     string
+		src      string
+		rel      string
+		opts     []CopyOption
+		want     string
+		wantData string
+		wantErr  error
+	}{
+		{
+			name:     "promote",
+			src:      src,
+			rel:      "myapp/themes/dark.yaml",
+			want:     filepath.Join(home, "myapp", "themes", "dark.yaml"),
+			wantData: "system dark",
+		},
+		{
+			name:    "refuse overwrite",
+			src:     src,
+			rel:     "myapp/themes/light.yaml",
+			wantErr: fs.ErrExist,
+		},
+		{
+			name:     "overwrite",
+			src:      src,
+			rel:      "myapp/themes/light.yaml",
+			opts:     []CopyOption{Overwrite()},
+			want:     existing,
+			wantData: "system dark",
+		},
+		{
+			name:     "already in home",
+			src:      existing,
+			rel:      "myapp/other.yaml",
+			want:     existing,
+			wantData: "system dark",
+		},
+		{
+			name:    "missing src",
+			src:     filepath.Join(system, "missing"),
+			rel:     "missing",
+			wantErr: fs.ErrNotExist,
+		},
+		{
+			name:    "directory src",
+			src:     system,
+			rel:     "system",
+			wantErr: errNotRegular,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CopyToDataHome(tt.src, tt.rel, tt.opts...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CopyToDataHome() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("CopyToDataHome() = %q, want %q", got, tt.want)
+			}
+			if data := readString(t, got); data != tt.wantData {
+				t.Errorf("copied data = %q, want %q", data, tt.wantData)
+			}
+		})
+	}
+
+	if runtime.GOOS != "windows" {
+		fi, err := os.Stat(filepath.Join(home, "myapp", "themes", "dark.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := fi.Mode().Perm(); perm != 0640 {
+			t.Errorf("copied mode = %v, want %v", perm, os.FileMode(0640))
+		}
+	}
+	if _, err := CopyToDataHome(src, "../escape"); err == nil {
+		t.Error("CopyToDataHome(../escape) expected error")
+	}
+}
+
+func TestCopyToConfigHomeSetuid(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("windows has no setuid bit")
+	}
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	t.Setenv("XDG_CONFIG_HOME", home)
+
+	src := filepath.Join(root, "etc", "xdg", "myapp", "hook")
+	writeFileContent(t, src, "#!/bin/sh")
+	if err := os.Chmod(src, 0755|os.ModeSetuid|os.ModeSetgid); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := CopyToConfigHome(src, "myapp/hook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, "myapp", "hook"); got != want {
+		t.Errorf("CopyToConfigHome() = %q, want %q", got, want)
+	}
+	fi, err := os.Stat(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := fi.Mode(); mode != 0755 {
+		t.Errorf("copied mode = %v, want %v", mode, os.FileMode(0755))
+	}
+}
+
+func TestCopyToDataHomeConcurrent(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	t.Setenv("XDG_DATA_HOME", home)
+
+	const n = 8
+	srcs := make([]string, n)
+	for i := range srcs {
+		srcs[i] = filepath.Join(root, "system", strconv.Itoa(i))
+		writeFileContent(t, srcs[i], strconv.Itoa(i))
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := range srcs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = CopyToDataHome(srcs[i], "myapp/theme.yaml")
+		}(i)
+	}
+	wg.Wait()
+
+	winner := -1
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			if winner != -1 {
+				t.Fatalf("copies %d and %d both succeeded", winner, i)
+			}
+			winner = i
+		case !errors.Is(err, fs.ErrExist):
+			t.Errorf("CopyToDataHome(%d) error = %v, want %v", i, err, fs.ErrExist)
+		}
+	}
+	if winner == -1 {
+		t.Fatal("no copy succeeded")
+	}
+	dir := filepath.Join(home, "myapp")
+	if got, want := readString(t, filepath.Join(dir, "theme.yaml")), strconv.Itoa(winner); got != want {
+		t.Errorf("copied data = %q, want %q of the succeeded copy", got, want)
+	}
+	assertNoTemp(t, dir)
+}
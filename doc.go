@@ -3,7 +3,8 @@
 // license that can be found in the LICENSE file.
 
 // Package xdgbasedir implements a freedesktop.org XDG Base Directory Specification.
-//  https://specifications.freedesktop.org/basedir-spec/latest/
+//
+//	https://specifications.freedesktop.org/basedir-spec/latest/
 //
 // The XDG Base Directory Specification is based on the following concepts:
 //
@@ -16,4 +17,6 @@
 // - There is a set of preference ordered base directories relative to which configuration files should be searched. This set of directories is defined by the environment variable $XDG_CONFIG_DIRS.
 //
 // - There is a single base directory relative to which user-specific non-essential (cached) data should be written. This directory is defined by the environment variable $XDG_CACHE_HOME.
+//
+// - There is a single base directory relative to which user-specific state data should be written. This directory is defined by the environment variable $XDG_STATE_HOME.
 package xdgbasedir // import "github.com/zchee/go-xdgbasedir"
@@ -0,0 +1,69 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"os"
+	"runtime"
+)
+
+// envVar is the captured environment variable.
+type envVar struct {
+	key   string
+	value string
+	ok    bool
+}
+
+// Env is the snapshot of the environment variables which the package reads. See Snapshot.
+type Env struct {
+	vars []envVar
+}
+
+// Snapshot captures the current values of all XDG environment variables and the user home directory variable,
+// such as $HOME, and the $APPDATA and $LOCALAPPDATA on windows.
+//
+// It is useful for the test harnesses which mutate the environment and then restore it by Env.Restore.
+func Snapshot() Env {
+	keys := []string{
+		EnvDataHome, EnvConfigHome, EnvDataDirs, EnvConfigDirs,
+		EnvCacheHome, EnvStateHome, EnvBinHome, EnvRuntimeDir,
+		homeEnv(),
+	}
+	if runtime.GOOS == "windows" {
+		keys = append(keys, "APPDATA", "LOCALAPPDATA")
+	}
+
+	vars := make([]envVar, len(keys))
+	for i, key := range keys {
+		value, ok := os.LookupEnv(key)
+		vars[i] = envVar{key: key, value: value, ok: ok}
+	}
+	return Env{vars: vars}
+}
+
+// Lookup returns the captured value of the key environment variable, and whether it was set.
+func (e Env) Lookup(key string) (string, bool) {
+	for _, v := range e.vars {
+		if v.key == key {
+			return v.value, v.ok
+		}
+	}
+	return "", false
+}
+
+// Restore puts the captured environment variables back. The variables which were not set are unset, not emptied.
+//
+// Restore also clears the cached user home directory same as Refresh, because the user home directory variable
+// may be changed.
+func (e Env) Restore() {
+	for _, v := range e.vars {
+		if v.ok {
+			os.Setenv(v.key, v.value)
+		} else {
+			os.Unsetenv(v.key)
+		}
+	}
+	Refresh()
+}
@@ -0,0 +1,56 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	// the outer Setenv restores the real environment after the test
+	for _, key := range []string{"XDG_CONFIG_HOME", "XDG_DATA_HOME", "XDG_CACHE_HOME", homeEnv()} {
+		t.Setenv(key, "")
+	}
+	t.Cleanup(Refresh)
+
+	os.Setenv("XDG_CONFIG_HOME", "/config")
+	os.Unsetenv("XDG_DATA_HOME")
+	home := t.TempDir()
+	os.Setenv(homeEnv(), home)
+
+	snapshot := Snapshot()
+	if v, ok := snapshot.Lookup("XDG_CONFIG_HOME"); v != "/config" || !ok {
+		t.Errorf("Lookup(XDG_CONFIG_HOME) = %q, %v, want %q, true", v, ok, "/config")
+	}
+	if _, ok := snapshot.Lookup("XDG_DATA_HOME"); ok {
+		t.Error("Lookup(XDG_DATA_HOME) is set")
+	}
+	if v, ok := snapshot.Lookup("XDG_CACHE_HOME"); v != "" || !ok {
+		t.Errorf("Lookup(XDG_CACHE_HOME) = %q, %v, want empty and set", v, ok)
+	}
+
+	os.Setenv("XDG_CONFIG_HOME", "/changed")
+	os.Setenv("XDG_DATA_HOME", "/changed")
+	os.Unsetenv("XDG_CACHE_HOME")
+	os.Setenv(homeEnv(), t.TempDir())
+	Refresh()
+	_ = userHome()
+
+	snapshot.Restore()
+
+	if v := os.Getenv("XDG_CONFIG_HOME"); v != "/config" {
+		t.Errorf("restored XDG_CONFIG_HOME = %q, want %q", v, "/config")
+	}
+	if _, ok := os.LookupEnv("XDG_DATA_HOME"); ok {
+		t.Error("restored XDG_DATA_HOME is set, want unset")
+	}
+	if v, ok := os.LookupEnv("XDG_CACHE_HOME"); v != "" || !ok {
+		t.Errorf("restored XDG_CACHE_HOME = %q, %v, want empty and set", v, ok)
+	}
+	if got := userHome(); got != home {
+		t.Errorf("userHome() after Restore = %q, want %q", got, home)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// FindExecutable searches the name command in $XDG_BIN_HOME first, and then in each directory of $PATH, and returns
+// the path, such as for the launchers which prefer the user installed commands in $HOME/.local/bin.
+//
+// The file must be the regular file with any executable bit. On windows, the suffixes of $PATHEXT are tried if name
+// has no extension. $PATH and $PATHEXT are read from the same environment as $XDG_BIN_HOME, so they follow WithEnv
+// same as PrependBinToPath. The empty and relative entries of $PATH are skipped. If name contains the path separator,
+// it is checked as is. If the command is not found, FindExecutable returns the error which matches to
+// exec.ErrNotFound via errors.Is.
+func FindExecutable(name string) (string, error) {
+	return std.FindExecutable(name)
+}
+
+// FindExecutable searches the name command in the bin home directory and $PATH.
+//
+// See the package level FindExecutable function for details.
+func (x *XDG) FindExecutable(name string) (string, error) {
+	return x.SearchExecutable(name)
+}
+
+type execConfig struct {
+	binHomeOnly bool
+}
+
+// ExecOption configures SearchExecutable.
+type ExecOption func(*execConfig)
+
+// BinHomeOnly returns the ExecOption which searches $XDG_BIN_HOME only, without falling back to $PATH.
+func BinHomeOnly() ExecOption {
+	return func(c *execConfig) {
+		c.binHomeOnly = true
+	}
+}
+
+// SearchExecutable searches the name command in $XDG_BIN_HOME first, which is $HOME/.local/bin by default even if
+// the user forgot to add it to $PATH, and then in $PATH, such as for the TryExec key of the desktop entries and
+// the plugin loaders.
+//
+// The directories and the files without any executable bit are never matched on unix. With BinHomeOnly, $PATH is
+// not searched. The name which contains the path separator is checked as is regardless of BinHomeOnly.
+// See FindExecutable for the other semantics, which is SearchExecutable without options.
+func SearchExecutable(name string, opts ...ExecOption) (string, error) {
+	return std.SearchExecutable(name, opts...)
+}
+
+// SearchExecutable searches the name command in the bin home directory and $PATH.
+//
+// See the package level SearchExecutable function for details.
+func (x *XDG) SearchExecutable(name string, opts ...ExecOption) (string, error) {
+	var c execConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	if name == "" {
+		return "", &exec.Error{Name: name, Err: exec.ErrNotFound}
+	}
+	names := x.executableNames(name)
+	if strings.ContainsAny(name, `/`+string(filepath.Separator)) {
+		if path, err := x.FirstExisting(names, RequireExecutable()); err == nil {
+			return path, nil
+		}
+		return "", &exec.Error{Name: name, Err: exec.ErrNotFound}
+	}
+
+	binHome := x.BinHome()
+	if binHome != "" {
+		if path, err := x.FirstExisting(joinNames(binHome, names), RequireExecutable()); err == nil {
+			return path, nil
+		}
+	}
+	if c.binHomeOnly {
+		return "", fmt.Errorf("xdgbasedir: executable %q not found in %s: %w", name, binHome, exec.ErrNotFound)
+	}
+
+	var paths []string
+	for _, dir := range filepath.SplitList(x.getenv("PATH")) {
+		// same as exec.LookPath, the command is never resolved relative to the current directory
+		if !filepath.IsAbs(dir) {
+			continue
+		}
+		paths = append(paths, joinNames(dir, names)...)
+	}
+	if path, err := x.FirstExisting(paths, RequireExecutable()); err == nil {
+		return path, nil
+	}
+	return "", fmt.Errorf("xdgbasedir: executable %q not found in %s or $PATH: %w", name, binHome, exec.ErrNotFound)
+}
+
+// PrependBinToPath returns $PATH with $XDG_BIN_HOME prepended using filepath.ListSeparator, such as for spawning
+// the subprocesses which see the user installed commands first.
+//
+// If $PATH already contains $XDG_BIN_HOME, that entry is moved to the front instead of duplicated. $PATH is returned
+// as is if $XDG_BIN_HOME is not resolvable.
+func PrependBinToPath() string {
+	return std.PrependBinToPath()
+}
+
+// PrependBinToPath returns $PATH with the bin home directory prepended.
+//
+// See the package level PrependBinToPath function for details.
+func (x *XDG) PrependBinToPath() string {
+	path := x.getenv("PATH")
+	binHome := x.BinHome()
+	if binHome == "" {
+		return path
+	}
+
+	list := []string{binHome}
+	for _, dir := range filepath.SplitList(path) {
+		if dir != "" && equalPath(filepath.Clean(dir), filepath.Clean(binHome)) {
+			continue
+		}
+		list = append(list, dir)
+	}
+	return strings.Join(list, string(filepath.ListSeparator))
+}
+
+// joinNames returns the paths of names in dir.
+func joinNames(dir string, names []string) []string {
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = filepath.Join(dir, name)
+	}
+	return paths
+}
+
+// executableNames returns the candidate filenames of the name command, which have the $PATHEXT suffixes on windows.
+func (x *XDG) executableNames(name string) []string {
+	if runtime.GOOS != "windows" || filepath.Ext(name) != "" {
+		return []string{name}
+	}
+
+	pathext := x.getenv("PATHEXT")
+	if pathext == "" {
+		pathext = ".com;.exe;.bat;.cmd"
+	}
+	var names []string
+	for _, ext := range strings.Split(pathext, ";") {
+		if ext == "" {
+			continue
+		}
+		names = append(names, name+ext)
+	}
+	return names
+}
@@ -0,0 +1,211 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestFindExecutable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("windows has no executable bit")
+	}
+	root := t.TempDir()
+	binHome := filepath.Join(root, "bin")
+	system := filepath.Join(root, "usr", "bin")
+	t.Setenv("XDG_BIN_HOME", binHome)
+	t.Setenv("PATH", system)
+
+	for path, perm := range map[string]os.FileMode{
+		filepath.Join(binHome, "tool"):   0755,
+		filepath.Join(binHome, "plain"):  0644,
+		filepath.Join(system, "tool"):    0755,
+		filepath.Join(system, "plain"):   0755,
+		filepath.Join(system, "system"):  0755,
+		filepath.Join(binHome, "hidden"): 0644,
+	} {
+		writeFile(t, path)
+		if err := os.Chmod(path, perm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "tool", want: filepath.Join(binHome, "tool")},
+		{name: "plain", want: filepath.Join(system, "plain")},
+		{name: "system", want: filepath.Join(system, "system")},
+		{name: "hidden", wantErr: true},
+		{name: "missing", wantErr: true},
+		{name: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindExecutable(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindExecutable(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, exec.ErrNotFound) {
+				t.Errorf("FindExecutable(%q) error = %v, want exec.ErrNotFound", tt.name, err)
+			}
+			if got != tt.want {
+				t.Errorf("FindExecutable(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchExecutable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("windows has no executable bit")
+	}
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	system := filepath.Join(root, "usr", "bin")
+	// $HOME/.local/bin is searched even if it is not in $PATH
+	t.Setenv("XDG_BIN_HOME", "")
+	t.Setenv("PATH", system)
+	binHome := filepath.Join(home, ".local", "bin")
+
+	writeFile(t, filepath.Join(binHome, "dir", "file"))
+	for path, perm := range map[string]os.FileMode{
+		filepath.Join(binHome, "tool"):  0755,
+		filepath.Join(system, "tool"):   0755,
+		filepath.Join(system, "system"): 0755,
+	} {
+		writeFile(t, path)
+		if err := os.Chmod(path, perm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(system, "dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cmd     string
+		opts    []ExecOption
+		want    string
+		wantErr bool
+	}{
+		{name: "bin home", cmd: "tool", want: filepath.Join(binHome, "tool")},
+		{name: "path", cmd: "system", want: filepath.Join(system, "system")},
+		{name: "bin home only", cmd: "system", opts: []ExecOption{BinHomeOnly()}, wantErr: true},
+		{name: "bin home only found", cmd: "tool", opts: []ExecOption{BinHomeOnly()}, want: filepath.Join(binHome, "tool")},
+		{name: "directory", cmd: "dir", wantErr: true},
+		{name: "absolute", cmd: filepath.Join(system, "system"), opts: []ExecOption{BinHomeOnly()}, want: filepath.Join(system, "system")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := New(WithHome(home))
+			got, err := x.SearchExecutable(tt.cmd, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SearchExecutable(%q) error = %v, wantErr %v", tt.cmd, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, exec.ErrNotFound) {
+				t.Errorf("SearchExecutable(%q) error = %v, want exec.ErrNotFound", tt.cmd, err)
+			}
+			if got != tt.want {
+				t.Errorf("SearchExecutable(%q) = %q, want %q", tt.cmd, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchExecutableEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("windows has no executable bit")
+	}
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	system := filepath.Join(root, "usr", "bin")
+	other := filepath.Join(root, "other")
+	// the process $PATH must not be searched
+	t.Setenv("PATH", other)
+
+	for _, path := range []string{filepath.Join(system, "tool"), filepath.Join(other, "other")} {
+		writeFile(t, path)
+		if err := os.Chmod(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	x := New(WithHome(home), WithEnv(map[string]string{
+		"PATH": strings.Join([]string{"", "relative", system}, string(filepath.ListSeparator)),
+	}))
+	got, err := x.SearchExecutable("tool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(system, "tool"); got != want {
+		t.Errorf("SearchExecutable(tool) = %q, want %q", got, want)
+	}
+	if _, err := x.SearchExecutable("other"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("SearchExecutable(other) error = %v, want exec.ErrNotFound", err)
+	}
+
+	// PrependBinToPath sees the same $PATH
+	want := strings.Join([]string{filepath.Join(home, ".local", "bin"), "", "relative", system}, string(filepath.ListSeparator))
+	if got := x.PrependBinToPath(); got != want {
+		t.Errorf("PrependBinToPath() = %q, want %q", got, want)
+	}
+}
+
+func TestExecutableNames(t *testing.T) {
+	x := New(WithEnv(map[string]string{"PATHEXT": ".EXE;.CMD"}))
+
+	want := []string{"tool"}
+	if runtime.GOOS == "windows" {
+		want = []string{"tool.EXE", "tool.CMD"}
+	}
+	if got := x.executableNames("tool"); !reflect.DeepEqual(got, want) {
+		t.Errorf("executableNames(tool) = %q, want %q", got, want)
+	}
+	if got := x.executableNames("tool.exe"); !reflect.DeepEqual(got, []string{"tool.exe"}) {
+		t.Errorf("executableNames(tool.exe) = %q, want %q", got, []string{"tool.exe"})
+	}
+}
+
+func TestPrependBinToPath(t *testing.T) {
+	root := t.TempDir()
+	binHome := filepath.Join(root, "bin")
+	usrBin := filepath.Join(root, "usr", "bin")
+	bin := filepath.Join(root, "bin2")
+	list := func(dirs ...string) string {
+		return strings.Join(dirs, string(filepath.ListSeparator))
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "prepend", path: list(usrBin, bin), want: list(binHome, usrBin, bin)},
+		{name: "empty", path: "", want: binHome},
+		{name: "already first", path: list(binHome, usrBin), want: list(binHome, usrBin)},
+		{name: "moved to front", path: list(usrBin, binHome+string(filepath.Separator), bin), want: list(binHome, usrBin, bin)},
+		{name: "keep empty entry", path: list(usrBin, ""), want: list(binHome, usrBin, "")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_BIN_HOME", binHome)
+			t.Setenv("PATH", tt.path)
+			if got := PrependBinToPath(); got != tt.want {
+				t.Errorf("PrependBinToPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"runtime"
+)
+
+var (
+	errNotRegular    = errors.New("not a regular file")
+	errNotDir        = errors.New("not a directory")
+	errNotExecutable = errors.New("not executable")
+)
+
+// require is the file type required by FirstExisting.
+type require int
+
+const (
+	requireAny require = iota
+	requireFile
+	requireDir
+	requireExecutable
+)
+
+type existsConfig struct {
+	nofollow bool
+	require  require
+	probe    func(path string, err error)
+}
+
+// ExistsOption configures FirstExisting.
+type ExistsOption func(*existsConfig)
+
+// FollowSymlinks returns the ExistsOption which controls whether the symlink candidates are resolved. By default,
+// the symlinks are followed, and the broken ones are rejected. Otherwise, the symlink itself is checked, which is
+// neither the regular file nor the directory.
+//
+// The filesystem of WithFS has no symlinks, so it's not affected.
+func FollowSymlinks(follow bool) ExistsOption {
+	return func(c *existsConfig) {
+		c.nofollow = !follow
+	}
+}
+
+// RequireFile returns the ExistsOption which accepts only the regular file.
+func RequireFile() ExistsOption {
+	return func(c *existsConfig) {
+		c.require = requireFile
+	}
+}
+
+// RequireDir returns the ExistsOption which accepts only the directory.
+func RequireDir() ExistsOption {
+	return func(c *existsConfig) {
+		c.require = requireDir
+	}
+}
+
+// RequireExecutable returns the ExistsOption which accepts only the regular file with any executable bit.
+// On windows, which has no executable bit, it accepts any regular file.
+func RequireExecutable() ExistsOption {
+	return func(c *existsConfig) {
+		c.require = requireExecutable
+	}
+}
+
+// WithProbe returns the ExistsOption which calls fn for each probed candidate in order, with the reason of
+// the rejection such as the error which matches to fs.ErrNotExist, or nil for the accepted one.
+func WithProbe(fn func(path string, err error)) ExistsOption {
+	return func(c *existsConfig) {
+		c.probe = fn
+	}
+}
+
+// FirstExisting returns the first path of paths which exists and satisfies the options, such as
+// FirstExisting(candidates, RequireDir()).
+//
+// The candidates are probed in order, and the probing stops at the accepted one. If no candidate is accepted,
+// FirstExisting returns the *NotFoundError which carries every path tried. The search functions such as
+// SearchConfigFile are built on it with RequireFile.
+func FirstExisting(paths []string, opts ...ExistsOption) (string, error) {
+	return std.FirstExisting(paths, opts...)
+}
+
+// FirstExisting returns the first path of paths which exists on the filesystem of x.
+//
+// See the package level FirstExisting function for details.
+func (x *XDG) FirstExisting(paths []string, opts ...ExistsOption) (string, error) {
+	var c existsConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	for _, path := range paths {
+		err := x.probe(path, &c)
+		if c.probe != nil {
+			c.probe(path, err)
+		}
+		if err == nil {
+			return path, nil
+		}
+	}
+	return "", &NotFoundError{Paths: paths}
+}
+
+// probe reports why path is not accepted by c, or nil if it is.
+func (x *XDG) probe(path string, c *existsConfig) error {
+	var (
+		fi  fs.FileInfo
+		err error
+	)
+	if c.nofollow && x.fsys == nil {
+		fi, err = os.Lstat(path)
+	} else {
+		fi, err = x.stat(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	switch c.require {
+	case requireFile:
+		if !fi.Mode().IsRegular() {
+			return &fs.PathError{Op: "stat", Path: path, Err: errNotRegular}
+		}
+	case requireDir:
+		if !fi.IsDir() {
+			return &fs.PathError{Op: "stat", Path: path, Err: errNotDir}
+		}
+	case requireExecutable:
+		if !fi.Mode().IsRegular() {
+			return &fs.PathError{Op: "stat", Path: path, Err: errNotRegular}
+		}
+		if runtime.GOOS != "windows" && fi.Mode().Perm()&0111 == 0 {
+			return &fs.PathError{Op: "stat", Path: path, Err: errNotExecutable}
+		}
+	}
+	return nil
+}
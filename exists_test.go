@@ -0,0 +1,166 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestFirstExisting(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, "file")
+	dir := filepath.Join(root, "dir")
+	link := filepath.Join(root, "link")
+	broken := filepath.Join(root, "broken")
+	missing := filepath.Join(root, "missing")
+
+	writeFile(t, file)
+	if err := os.Mkdir(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(file, link); err != nil {
+		t.Skipf("symlink is not supported: %v", err)
+	}
+	if err := os.Symlink(missing, broken); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		paths   []string
+		opts    []ExistsOption
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "first",
+			paths: []string{missing, dir, file},
+			want:  dir,
+		},
+		{
+			name:  "require file",
+			paths: []string{missing, dir, file},
+			opts:  []ExistsOption{RequireFile()},
+			want:  file,
+		},
+		{
+			name:  "require dir",
+			paths: []string{file, dir},
+			opts:  []ExistsOption{RequireDir()},
+			want:  dir,
+		},
+		{
+			name:  "follow symlinks",
+			paths: []string{broken, link, file},
+			opts:  []ExistsOption{RequireFile()},
+			want:  link,
+		},
+		{
+			name:  "not follow symlinks",
+			paths: []string{broken, link, file},
+			opts:  []ExistsOption{RequireFile(), FollowSymlinks(false)},
+			want:  file,
+		},
+		{
+			name:  "not follow symlinks without requirement",
+			paths: []string{broken, file},
+			opts:  []ExistsOption{FollowSymlinks(false)},
+			want:  broken,
+		},
+		{
+			name:    "none",
+			paths:   []string{missing, file},
+			opts:    []ExistsOption{RequireDir()},
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FirstExisting(tt.paths, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FirstExisting() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("FirstExisting() = %q, want %q", got, tt.want)
+			}
+			if err == nil {
+				return
+			}
+			var nf *NotFoundError
+			if !errors.As(err, &nf) || !reflect.DeepEqual(nf.Paths, tt.paths) {
+				t.Errorf("FirstExisting() error = %#v, want *NotFoundError with %q", err, tt.paths)
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("FirstExisting() error = %v, want fs.ErrNotExist", err)
+			}
+		})
+	}
+}
+
+func TestFirstExistingExecutable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("windows has no executable bit")
+	}
+	root := t.TempDir()
+	file := filepath.Join(root, "file")
+	exe := filepath.Join(root, "exe")
+	writeFile(t, file)
+	if err := os.WriteFile(exe, nil, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := FirstExisting([]string{root, file, exe}, RequireExecutable())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != exe {
+		t.Errorf("FirstExisting() = %q, want %q", got, exe)
+	}
+}
+
+func TestFirstExistingProbe(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, "file")
+	missing := filepath.Join(root, "missing")
+	writeFile(t, file)
+
+	type result struct {
+		path string
+		err  error
+	}
+	var got []result
+	path, err := FirstExisting([]string{missing, root, file, filepath.Join(root, "never")}, RequireFile(), WithProbe(func(path string, err error) {
+		got = append(got, result{path: path, err: err})
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != file {
+		t.Errorf("FirstExisting() = %q, want %q", path, file)
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("probed %d candidates, want 3: %v", len(got), got)
+	}
+	if got[0].path != missing || !errors.Is(got[0].err, fs.ErrNotExist) {
+		t.Errorf("probe[0] = %v, want %s with fs.ErrNotExist", got[0], missing)
+	}
+	if got[1].path != root || !errors.Is(got[1].err, errNotRegular) {
+		t.Errorf("probe[1] = %v, want %s with %v", got[1], root, errNotRegular)
+	}
+	if got[2].path != file || got[2].err != nil {
+		t.Errorf("probe[2] = %v, want %s accepted", got[2], file)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// Trace is the decision record of how Explain resolved the file, in the order of the decisions.
+type Trace struct {
+	// Kind is the explained kind.
+	Kind Kind
+	// Rel is the explained relative path.
+	Rel string
+	// Vars is the environment variables consulted.
+	Vars []TraceVar
+	// Dirs is every entry of the base directories considered, including the rejected ones.
+	Dirs []TraceDir
+	// Probes is the candidate paths probed and their results, in the search order.
+	Probes []TraceProbe
+	// Cached reports whether the not-found outcome is served from WithNegativeCache without probing.
+	Cached bool
+	// Path is the winning file path. It is empty if no file is found.
+	Path string
+	// Err is the error which the search returns, such as the *NotFoundError.
+	Err error
+}
+
+// TraceVar is the environment variable consulted by Explain.
+type TraceVar struct {
+	// Name is the variable name such as "XDG_CONFIG_HOME".
+	Name string
+	// Raw is the raw value of the environment.
+	Raw string
+	// Set reports whether the variable is present in the environment, even if it is empty.
+	Set bool
+	// Value is the resolved value. It is the default if Raw is empty, and the tilde expanded Raw otherwise.
+	Value string
+}
+
+// TraceDir is the entry of the base directories considered by Explain.
+type TraceDir struct {
+	// Dir is the entry, cleaned if it is absolute.
+	Dir string
+	// Rejected is the reason why the entry is not searched, such as "empty", "relative" or "duplicate". It is empty
+	// for the searched entry.
+	Rejected string
+}
+
+// TraceProbe is the candidate path probed by Explain.
+type TraceProbe struct {
+	// Path is the candidate path.
+	Path string
+	// Err is the reason of the rejection, such as the error which matches to fs.ErrNotExist. It is nil for the winner.
+	Err error
+}
+
+// Explain resolves the rel file of kind same as SearchDataFile or SearchConfigFile, and returns the record of every
+// step, such as for the --verbose logs answering "why is my app reading that file".
+//
+// KindDataHome and KindDataDirs explain SearchDataFile, and KindConfigHome and KindConfigDirs explain
+// SearchConfigFile. The other kinds explain the rel file in the single base directory. Explain runs the real search
+// code path with the probe hook, so the trace reports what the search does including the WithFS and
+// WithNegativeCache configurations. The invalid rel or kind is reported by Trace.Err.
+func Explain(kind Kind, rel string) Trace {
+	return std.Explain(kind, rel)
+}
+
+// Explain resolves the rel file of kind and returns the record of every step.
+//
+// See the package level Explain function for details.
+func (x *XDG) Explain(kind Kind, rel string) Trace {
+	t := Trace{Kind: kind, Rel: rel}
+
+	var entries []string
+	switch kind {
+	case KindDataHome, KindDataDirs:
+		home, dirs := x.DataHome(), x.DataDirs()
+		t.Vars = []TraceVar{x.traceVar(EnvDataHome, home), x.traceVar(EnvDataDirs, dirs)}
+		entries = dirEntries(home, dirs)
+	case KindConfigHome, KindConfigDirs:
+		home, dirs := x.ConfigHome(), x.ConfigDirs()
+		t.Vars = []TraceVar{x.traceVar(EnvConfigHome, home), x.traceVar(EnvConfigDirs, dirs)}
+		entries = dirEntries(home, dirs)
+	default:
+		dir, err := x.Dir(kind)
+		if err != nil {
+			t.Err = err
+			return t
+		}
+		t.Vars = []TraceVar{x.traceVar(kind.env(), dir)}
+		entries = []string{dir}
+	}
+	dirs := filterDirs(entries, func(dir, rejected string) {
+		t.Dirs = append(t.Dirs, TraceDir{Dir: dir, Rejected: rejected})
+	})
+
+	clean, err := cleanRel(rel)
+	if err != nil {
+		t.Err = err
+		return t
+	}
+	t.Path, t.Err = x.searchFile(dirs, rel, clean, WithProbe(func(path string, err error) {
+		t.Probes = append(t.Probes, TraceProbe{Path: path, Err: err})
+	}))
+	t.Cached = t.Err != nil && len(dirs) > 0 && len(t.Probes) == 0
+	return t
+}
+
+// traceVar returns the TraceVar of the key variable which is resolved to value.
+func (x *XDG) traceVar(key, value string) TraceVar {
+	raw, set := x.lookupenv(key)
+	return TraceVar{Name: key, Raw: raw, Set: set, Value: value}
+}
+
+// String returns the human-readable multi-line rendering of t such as:
+//
+//	explain config-home "myapp/config.toml"
+//	  $XDG_CONFIG_HOME unset, default /home/foo/.config
+//	  $XDG_CONFIG_DIRS="/etc/xdg:etc"
+//	  dir /home/foo/.config
+//	  dir /etc/xdg
+//	  dir "etc" rejected: relative
+//	  probe /home/foo/.config/myapp/config.toml: file does not exist
+//	  probe /etc/xdg/myapp/config.toml: found
+//	  result /etc/xdg/myapp/config.toml
+func (t Trace) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "explain %v %q\n", t.Kind, t.Rel)
+	for _, v := range t.Vars {
+		switch {
+		case !v.Set:
+			fmt.Fprintf(&b, "  $%s unset, default %s\n", v.Name, v.Value)
+		case v.Raw == "":
+			fmt.Fprintf(&b, "  $%s empty, default %s\n", v.Name, v.Value)
+		case v.Raw != v.Value:
+			fmt.Fprintf(&b, "  $%s=%q, expanded %s\n", v.Name, v.Raw, v.Value)
+		default:
+			fmt.Fprintf(&b, "  $%s=%q\n", v.Name, v.Raw)
+		}
+	}
+	for _, d := range t.Dirs {
+		if d.Rejected != "" {
+			fmt.Fprintf(&b, "  dir %q rejected: %s\n", d.Dir, d.Rejected)
+			continue
+		}
+		fmt.Fprintf(&b, "  dir %s\n", d.Dir)
+	}
+	for _, p := range t.Probes {
+		if p.Err != nil {
+			fmt.Fprintf(&b, "  probe %s: %v\n", p.Path, probeReason(p.Err))
+			continue
+		}
+		fmt.Fprintf(&b, "  probe %s: found\n", p.Path)
+	}
+	switch {
+	case t.Err == nil:
+		fmt.Fprintf(&b, "  result %s\n", t.Path)
+	case t.Cached:
+		fmt.Fprintf(&b, "  not found (negative cache)\n")
+	case errors.Is(t.Err, fs.ErrNotExist):
+		fmt.Fprintf(&b, "  not found\n")
+	default:
+		fmt.Fprintf(&b, "  error: %v\n", t.Err)
+	}
+	return b.String()
+}
+
+// probeReason returns the reason of err without the redundant path of *fs.PathError.
+func probeReason(err error) error {
+	var pe *fs.PathError
+	if errors.As(err, &pe) {
+		return pe.Err
+	}
+	return err
+}
@@ -0,0 +1,132 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"runtime"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestExplain(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the golden trace is for the linux defaults")
+	}
+	mapfs := fstest.MapFS{
+		"home/foo/.config/myapp":  &fstest.MapFile{Mode: fs.ModeDir},
+		"etc/xdg/myapp/app.toml":  &fstest.MapFile{Mode: fs.ModeDir},
+		"opt/xdg/myapp/app.toml":  &fstest.MapFile{Data: []byte("opt")},
+		"var/cache/myapp/db.json": &fstest.MapFile{},
+	}
+	x := New(WithHome("/home/foo"), WithFS(mapfs), WithLookupEnv(mapLookupEnv(map[string]string{
+		"XDG_CONFIG_DIRS": "/etc/xdg:etc:/opt/xdg/:/etc/xdg:",
+		"XDG_CACHE_HOME":  "~/cache",
+	})))
+
+	tests := []struct {
+		name string
+		kind Kind
+		rel  string
+		want string
+	}{
+		{
+			name: "config",
+			kind: KindConfigHome,
+			rel:  "myapp/app.toml",
+			want: `explain config-home "myapp/app.toml"
+  $XDG_CONFIG_HOME unset, default /home/foo/.config
+  $XDG_CONFIG_DIRS="/etc/xdg:etc:/opt/xdg/:/etc/xdg:"
+  dir /home/foo/.config
+  dir /etc/xdg
+  dir "etc" rejected: relative
+  dir /opt/xdg
+  dir "/etc/xdg" rejected: duplicate
+  dir "" rejected: empty
+  probe /home/foo/.config/myapp/app.toml: file does not exist
+  probe /etc/xdg/myapp/app.toml: not a regular file
+  probe /opt/xdg/myapp/app.toml: found
+  result /opt/xdg/myapp/app.toml
+`,
+		},
+		{
+			name: "cache not found",
+			kind: KindCacheHome,
+			rel:  "myapp/index.json",
+			want: `explain cache-home "myapp/index.json"
+  $XDG_CACHE_HOME="~/cache", expanded /home/foo/cache
+  dir /home/foo/cache
+  probe /home/foo/cache/myapp/index.json: file does not exist
+  not found
+`,
+		},
+		{
+			name: "invalid rel",
+			kind: KindDataDirs,
+			rel:  "../passwd",
+			want: `explain data-dirs "../passwd"
+  $XDG_DATA_HOME unset, default /home/foo/.local/share
+  $XDG_DATA_DIRS unset, default /usr/local/share:/usr/share
+  dir /home/foo/.local/share
+  dir /usr/local/share
+  dir /usr/share
+  error: ` + Explain(KindDataDirs, "../passwd").Err.Error() + "\n",
+		},
+		{
+			name: "unknown kind",
+			kind: Kind(100),
+			rel:  "a",
+			want: `explain Kind(100) "a"
+  error: xdgbasedir: unknown kind Kind(100)
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := x.Explain(tt.kind, tt.rel).String(); got != tt.want {
+				t.Errorf("Explain(%v, %q) =\n%s\nwant\n%s", tt.kind, tt.rel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExplainMatchesSearch(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"usr/share2/snippets/go.json": &fstest.MapFile{},
+		"usr/share4/snippets/go.json": &fstest.MapFile{},
+	}
+	x, _ := setupNegCache(t, mapfs, time.Minute, 8)
+
+	for _, rel := range []string{"snippets/go.json", "snippets/rust.json"} {
+		want, wantErr := x.With(WithNegativeCache(0, 0)).SearchDataFile(rel)
+		trace := x.Explain(KindDataHome, rel)
+		if trace.Path != want || (trace.Err == nil) != (wantErr == nil) {
+			t.Errorf("Explain(%q) = %q, %v, want %q, %v", rel, trace.Path, trace.Err, want, wantErr)
+		}
+		if trace.Cached {
+			t.Errorf("Explain(%q) is cached on the first search", rel)
+		}
+
+		var probed []string
+		for _, p := range trace.Probes {
+			probed = append(probed, p.Path)
+		}
+		if wantErr != nil {
+			var nf *NotFoundError
+			if !errors.As(wantErr, &nf) || !reflect.DeepEqual(probed, nf.Paths) {
+				t.Errorf("Explain(%q) probed %v, want %v", rel, probed, nf.Paths)
+			}
+		}
+	}
+
+	// the miss remembered by the negative cache is reported as is
+	trace := x.Explain(KindDataDirs, "snippets/rust.json")
+	if !trace.Cached || len(trace.Probes) != 0 || !errors.Is(trace.Err, fs.ErrNotExist) {
+		t.Errorf("Explain() of the cached miss = %+v, want Cached", trace)
+	}
+}
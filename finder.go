@@ -0,0 +1,40 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+// Finder searches the files in the XDG base directories.
+//
+// XDG and App implement Finder. Accept Finder instead of *XDG or *App where the code only needs to know where
+// the files come from, so its unit tests can substitute the test double such as xdgtest.MapFinder without touching
+// the filesystem or the environment. WithFinder makes App search through the Finder too.
+type Finder interface {
+	// SearchDataFile returns the first existing rel file in the data directories. See SearchDataFile.
+	SearchDataFile(rel string) (string, error)
+	// SearchConfigFile returns the first existing rel file in the configuration directories. See SearchConfigFile.
+	SearchConfigFile(rel string) (string, error)
+	// FindDataFiles returns all existing rel files in the data directories. See SearchAllDataFiles.
+	FindDataFiles(rel string) ([]string, error)
+	// FindConfigFiles returns all existing rel files in the configuration directories. See SearchAllConfigFiles.
+	FindConfigFiles(rel string) ([]string, error)
+}
+
+var (
+	_ Finder = (*XDG)(nil)
+	_ Finder = (*App)(nil)
+)
+
+// FindDataFiles returns all existing regular files of rel in the data directories.
+//
+// It is SearchAllDataFiles under the name of App.FindDataFiles, so both XDG and App implement Finder.
+func (x *XDG) FindDataFiles(rel string) ([]string, error) {
+	return x.SearchAllDataFiles(rel)
+}
+
+// FindConfigFiles returns all existing regular files of rel in the configuration directories.
+//
+// It is SearchAllConfigFiles under the name of App.FindConfigFiles, so both XDG and App implement Finder.
+func (x *XDG) FindConfigFiles(rel string) ([]string, error) {
+	return x.SearchAllConfigFiles(rel)
+}
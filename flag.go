@@ -0,0 +1,74 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// DirFlag is the flag.Value which overrides the base directory of kind for the XDG, such as --config-home=/path.
+//
+// It is wired to the flag package by flag.Var:
+//
+//	x := xdgbasedir.New()
+//	flag.Var(xdgbasedir.NewDirFlag(x, xdgbasedir.KindConfigHome), "config-home", "configuration directory")
+//	flag.Parse()
+//	app, err := x.App("myapp") // resolves under the --config-home directory if given
+//
+// The default value in the usage message is the current directory path of kind.
+type DirFlag struct {
+	x    *XDG
+	kind Kind
+}
+
+var _ flag.Value = (*DirFlag)(nil)
+
+// NewDirFlag returns the DirFlag which overrides the base directory of kind for x.
+func NewDirFlag(x *XDG, kind Kind) *DirFlag {
+	return &DirFlag{x: x, kind: kind}
+}
+
+// String implements flag.Value. It returns the current directory path of kind.
+func (f *DirFlag) String() string {
+	if f == nil || f.x == nil {
+		return ""
+	}
+	dir, _ := f.x.Dir(f.kind)
+	return dir
+}
+
+// Set implements flag.Value. It overrides the base directory of kind with s, same as setting the environment
+// variable of kind for x.
+//
+// The leading tilde of s is expanded to the user home directory, and s must be the absolute path. For KindDataDirs
+// and KindConfigDirs, s is the list separated by filepath.ListSeparator, and each entry must be absolute.
+// Set is not safe to call concurrently with the other methods of x, so it should be called by flag.Parse before use.
+func (f *DirFlag) Set(s string) error {
+	env := f.kind.env()
+	if env == "" {
+		return fmt.Errorf("xdgbasedir: unknown kind %v", f.kind)
+	}
+
+	dirs := []string{s}
+	if f.kind == KindDataDirs || f.kind == KindConfigDirs {
+		dirs = filepath.SplitList(s)
+		if len(dirs) == 0 {
+			return fmt.Errorf("xdgbasedir: empty %s", f.kind)
+		}
+	}
+	for i, dir := range dirs {
+		dir = f.x.expandUser(dir)
+		if !filepath.IsAbs(dir) {
+			return fmt.Errorf("xdgbasedir: %s %q is not an absolute path", f.kind, dir)
+		}
+		dirs[i] = dir
+	}
+
+	WithEnv(map[string]string{env: strings.Join(dirs, string(filepath.ListSeparator))})(f.x)
+	return nil
+}
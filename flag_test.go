@@ -0,0 +1,132 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"flag"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDirFlag(t *testing.T) {
+	root := string(filepath.Separator)
+	if runtime.GOOS == "windows" {
+		root = `C:\`
+	}
+	home := filepath.Join(root, "home", "gopher")
+	configHome := filepath.Join(root, "alt", "config")
+	dataDirs := []string{filepath.Join(root, "alt", "share"), filepath.Join(root, "usr", "share")}
+
+	tests := []struct {
+		name    string
+		args    []string
+		kind    Kind
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "config home",
+			args: []string{"--dir=" + configHome},
+			kind: KindConfigHome,
+			want: configHome,
+		},
+		{
+			name: "tilde",
+			args: []string{"--dir=~/alt"},
+			kind: KindCacheHome,
+			want: filepath.Join(home, "alt"),
+		},
+		{
+			name: "list",
+			args: []string{"--dir=" + strings.Join(dataDirs, string(filepath.ListSeparator))},
+			kind: KindDataDirs,
+			want: strings.Join(dataDirs, string(filepath.ListSeparator)),
+		},
+		{
+			name: "last wins",
+			args: []string{"--dir=" + filepath.Join(root, "first"), "--dir=" + configHome},
+			kind: KindConfigHome,
+			want: configHome,
+		},
+		{
+			name:    "relative",
+			args:    []string{"--dir=relative"},
+			kind:    KindConfigHome,
+			wantErr: true,
+		},
+		{
+			name:    "relative list entry",
+			args:    []string{"--dir=" + strings.Join([]string{dataDirs[0], "relative"}, string(filepath.ListSeparator))},
+			kind:    KindConfigDirs,
+			wantErr: true,
+		},
+		{
+			name:    "unknown kind",
+			args:    []string{"--dir=" + configHome},
+			kind:    Kind(-1),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := New(WithHome(home), WithLookupEnv(mapLookupEnv(map[string]string{"XDG_CONFIG_HOME": filepath.Join(home, ".config")})))
+			before := x.ConfigHome()
+
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			flags.SetOutput(io.Discard)
+			flags.Var(NewDirFlag(x, tt.kind), "dir", "override directory")
+			err := flags.Parse(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if got := x.ConfigHome(); got != before {
+					t.Errorf("ConfigHome() = %q after the failed Set, want %q", got, before)
+				}
+				return
+			}
+
+			got, err := x.Dir(tt.kind)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Dir(%v) = %q, want %q", tt.kind, got, tt.want)
+			}
+			if s := flags.Lookup("dir").Value.String(); s != tt.want {
+				t.Errorf("String() = %q, want %q", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirFlagApp(t *testing.T) {
+	x := New(WithLookupEnv(mapLookupEnv(nil)))
+	app, err := x.App("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.Var(NewDirFlag(x, KindStateHome), "state-home", "state directory")
+	if got := flags.Lookup("state-home").DefValue; got != x.StateHome() {
+		t.Errorf("DefValue = %q, want the default %q", got, x.StateHome())
+	}
+	if err := flags.Parse([]string{"--state-home", dir}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := app.StateHome(), filepath.Join(dir, "myapp"); got != want {
+		t.Errorf("StateHome() = %q, want %q", got, want)
+	}
+
+	var zero DirFlag
+	if s := zero.String(); s != "" {
+		t.Errorf("zero String() = %q, want empty", s)
+	}
+}
@@ -0,0 +1,388 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// unionFS is the read-only union filesystem of the layers.
+//
+// The earlier layer shadows the later ones, same as the preference order of XDG base directories.
+// The directory listings are merged across the layers, and the same name entries are de-duplicated
+// keeping the highest-precedence one. A non-directory file in the layer hides the same path and
+// everything below it in the later layers.
+type unionFS struct {
+	layers []fs.FS
+	// bases is the base directory of each layers.
+	bases []string
+}
+
+// LayerInfo is the fs.FileInfo returned by the merged filesystems such as ConfigFS, which carries the base directory
+// of the layer the file came from.
+//
+// The Stat of the merged filesystem, the Stat of its opened file and the Info of its directory entry return
+// the *LayerInfo, so the caller can treat the user layer files differently from the system layer ones:
+//
+//	fi, err := fs.Stat(xdgbasedir.ConfigFS(), "myapp/config.toml")
+//	if err == nil && fi.(*xdgbasedir.LayerInfo).Base == xdgbasedir.ConfigHome() {
+//		// the user editable file
+//	}
+type LayerInfo struct {
+	fs.FileInfo
+	// Base is the base directory of the layer, such as "/etc/xdg", or "/etc/xdg/myapp" for App.ConfigFS.
+	// It is empty for the fallback layer of DataFSWithFallback and ConfigFSWithFallback.
+	Base string
+}
+
+var (
+	_ fs.FS         = (*unionFS)(nil)
+	_ fs.ReadDirFS  = (*unionFS)(nil)
+	_ fs.StatFS     = (*unionFS)(nil)
+	_ fs.ReadFileFS = (*unionFS)(nil)
+	_ fs.GlobFS     = (*unionFS)(nil)
+)
+
+// DataFS returns the read-only union filesystem over $XDG_DATA_HOME and each entry of $XDG_DATA_DIRS,
+// which is the merged view of DataDirsAll, such as fs.ReadFile(DataFS(), "myapp/schema.sql").
+//
+// Opening a file tries $XDG_DATA_HOME first and then each data directory in order, so the first match wins same as
+// the specification says to search. The returned fs.FS also implements fs.ReadDirFS, which merges the directory
+// listings across the layers de-duplicated by name with the home-most precedence, fs.StatFS which reports the info
+// of the winning layer, fs.ReadFileFS and fs.GlobFS, which read each directory once, so fs.WalkDir and fs.Glob
+// don't fall back to opening every entry. The names are fs.ValidPath style without the leading slash.
+// The returned fs.FS is safe for concurrent use. See App.DataFS for the per-app view.
+func DataFS() fs.FS {
+	return std.DataFS()
+}
+
+// DataFS returns the read-only union filesystem over the data directories.
+//
+// See the package level DataFS function for details.
+func (x *XDG) DataFS() fs.FS {
+	return x.unionFS(x.DataDirsAll())
+}
+
+// ConfigFS returns the read-only union filesystem over $XDG_CONFIG_HOME and each entry of $XDG_CONFIG_DIRS,
+// which is the merged view of ConfigDirsAll.
+//
+// Opening a file tries $XDG_CONFIG_HOME first and then each configuration directory in order, so the user files
+// shadow the system files. The returned fs.FS also implements fs.ReadDirFS, fs.StatFS, fs.ReadFileFS and fs.GlobFS.
+// ReadDir(".") returns the union of entries of every configuration directory de-duplicated by name, and the
+// highest-precedence directory wins on the conflicts, which is how the theme and plugin directories are enumerated.
+// See App.DataFS for the semantics.
+func ConfigFS() fs.FS {
+	return std.ConfigFS()
+}
+
+// ConfigFS returns the read-only union filesystem over the configuration directories.
+//
+// See the package level ConfigFS function for details.
+func (x *XDG) ConfigFS() fs.FS {
+	return x.unionFS(x.ConfigDirsAll())
+}
+
+// DataFSWithFallback returns the union filesystem same as DataFS with fallback as the last layer, such as the
+// embed.FS of the default themes and templates. The user overrides the individual files by placing the
+// replacements into the data directories.
+//
+// The fallback layer is treated exactly like another data directory, so the shadowing, the merged ReadDir and Stat,
+// and hence fs.WalkDir, see the union. The Base of the *LayerInfo of the fallback layer is empty. The names of
+// fallback are relative to the merged root, such as "myapp/themes/dark.yaml", so use fs.Sub for the embed.FS
+// rooted at another directory.
+func DataFSWithFallback(fallback fs.FS) fs.FS {
+	return std.DataFSWithFallback(fallback)
+}
+
+// DataFSWithFallback returns the union filesystem over the data directories and fallback.
+//
+// See the package level DataFSWithFallback function for details.
+func (x *XDG) DataFSWithFallback(fallback fs.FS) fs.FS {
+	return x.unionFS(x.DataDirsAll()).withFallback(fallback)
+}
+
+// ConfigFSWithFallback returns the union filesystem same as ConfigFS with fallback as the last layer, such as the
+// embed.FS of the default configuration files.
+//
+// See DataFSWithFallback for the semantics.
+func ConfigFSWithFallback(fallback fs.FS) fs.FS {
+	return std.ConfigFSWithFallback(fallback)
+}
+
+// ConfigFSWithFallback returns the union filesystem over the configuration directories and fallback.
+//
+// See the package level ConfigFSWithFallback function for details.
+func (x *XDG) ConfigFSWithFallback(fallback fs.FS) fs.FS {
+	return x.unionFS(x.ConfigDirsAll()).withFallback(fallback)
+}
+
+// unionFS returns the union filesystem of each dirs on the filesystem of x.
+func (x *XDG) unionFS(dirs []string) *unionFS {
+	layers := make([]fs.FS, len(dirs))
+	for i, dir := range dirs {
+		layers[i] = x.dirFS(dir)
+	}
+	return &unionFS{layers: layers, bases: dirs}
+}
+
+// withFallback appends fallback as the lowest-precedence layer without the base directory.
+func (u *unionFS) withFallback(fallback fs.FS) *unionFS {
+	u.layers = append(u.layers, fallback)
+	u.bases = append(u.bases[:len(u.bases):len(u.bases)], "")
+	return u
+}
+
+// Open implements fs.FS.
+func (u *unionFS) Open(name string) (fs.File, error) {
+	if !validPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	i, fi, err := u.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := u.layers[i].Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return &unionFile{File: f, base: u.bases[i]}, nil
+	}
+	return &unionDir{unionFile: unionFile{File: f, base: u.bases[i]}, u: u, name: name, layer: i}, nil
+}
+
+// Stat implements fs.StatFS. It returns the *LayerInfo of the highest-precedence layer.
+func (u *unionFS) Stat(name string) (fs.FileInfo, error) {
+	if !validPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+
+	i, fi, err := u.stat("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return &LayerInfo{FileInfo: fi, Base: u.bases[i]}, nil
+}
+
+// ReadFile implements fs.ReadFileFS. It reads the file of the highest-precedence layer.
+func (u *unionFS) ReadFile(name string) ([]byte, error) {
+	if !validPath(name) {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrInvalid}
+	}
+
+	i, _, err := u.stat("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadFile(u.layers[i], name)
+}
+
+// ReadDir implements fs.ReadDirFS. It returns the merged entries of the name directory sorted by filename.
+func (u *unionFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !validPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	i, fi, err := u.stat("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	return u.readDir(name, i)
+}
+
+// Glob implements fs.GlobFS. It returns the merged paths matching pattern sorted by path, and each path is
+// returned once even if several layers have it.
+//
+// The directories are read by the merged ReadDir, so each directory of each layer is read once per Glob.
+func (u *unionFS) Glob(pattern string) ([]string, error) {
+	// hide Glob from fs.Glob, which would call it recursively
+	return fs.Glob(readDirFS{u}, pattern)
+}
+
+// readDirFS is the unionFS without Glob.
+type readDirFS struct {
+	u *unionFS
+}
+
+func (r readDirFS) Open(name string) (fs.File, error)          { return r.u.Open(name) }
+func (r readDirFS) Stat(name string) (fs.FileInfo, error)      { return r.u.Stat(name) }
+func (r readDirFS) ReadDir(name string) ([]fs.DirEntry, error) { return r.u.ReadDir(name) }
+
+// stat returns the index and fs.FileInfo of the highest-precedence layer which has name.
+//
+// The layer which returns the error other than fs.ErrNotExist, such as permission denied, is skipped.
+// That error is returned if no layer has name.
+func (u *unionFS) stat(op, name string) (int, fs.FileInfo, error) {
+	var firstErr error
+	for i, layer := range u.layers {
+		fi, err := fs.Stat(layer, name)
+		if err == nil {
+			return i, fi, nil
+		}
+		if firstErr == nil && !errors.Is(err, fs.ErrNotExist) {
+			firstErr = err
+		}
+		if hidden(layer, name) {
+			break
+		}
+	}
+	if firstErr == nil {
+		firstErr = &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return -1, nil, firstErr
+}
+
+// readDir merges the entries of the name directory in the layers from start.
+//
+// The merging stops at the layer which has name or its ancestor as not a directory.
+func (u *unionFS) readDir(name string, start int) ([]fs.DirEntry, error) {
+	var entries []fs.DirEntry
+	seen := make(map[string]bool)
+	for i := start; i < len(u.layers); i++ {
+		// read the directory first, and stat only on the failure, so each layer directory is hit once
+		list, err := fs.ReadDir(u.layers[i], name)
+		if err != nil {
+			if i == start {
+				return nil, err
+			}
+			if fi, err := fs.Stat(u.layers[i], name); err == nil {
+				if !fi.IsDir() {
+					break
+				}
+				continue
+			}
+			if hidden(u.layers[i], name) {
+				break
+			}
+			continue
+		}
+		for _, entry := range list {
+			if seen[entry.Name()] {
+				continue
+			}
+			seen[entry.Name()] = true
+			entries = append(entries, &layerEntry{DirEntry: entry, base: u.bases[i]})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// validPath reports whether name is the valid path for unionFS.
+//
+// In addition to fs.ValidPath, the backslash and colon are rejected on windows, same as os.DirFS, because they
+// would be interpreted as the path separator and the drive letter prefix, and escape the layer.
+func validPath(name string) bool {
+	return fs.ValidPath(name) && !(runtime.GOOS == "windows" && strings.ContainsAny(name, `\:`))
+}
+
+// hidden reports whether the ancestor of name in layer is not a directory, which hides name in the later layers.
+func hidden(layer fs.FS, name string) bool {
+	for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+		if fi, err := fs.Stat(layer, dir); err == nil {
+			return !fi.IsDir()
+		}
+	}
+	return false
+}
+
+// unionFile is the file of unionFS which returns the *LayerInfo.
+type unionFile struct {
+	fs.File
+	base string
+}
+
+// Stat implements fs.File.
+func (f *unionFile) Stat() (fs.FileInfo, error) {
+	fi, err := f.File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return &LayerInfo{FileInfo: fi, Base: f.base}, nil
+}
+
+// Seek implements io.Seeker if the file of the layer implements it, which http.FileServer requires.
+func (f *unionFile) Seek(offset int64, whence int) (int64, error) {
+	s, ok := f.File.(io.Seeker)
+	if !ok {
+		return 0, errors.New("xdgbasedir: file does not implement io.Seeker")
+	}
+	return s.Seek(offset, whence)
+}
+
+// ReadAt implements io.ReaderAt if the file of the layer implements it.
+func (f *unionFile) ReadAt(p []byte, off int64) (int, error) {
+	r, ok := f.File.(io.ReaderAt)
+	if !ok {
+		return 0, errors.New("xdgbasedir: file does not implement io.ReaderAt")
+	}
+	return r.ReadAt(p, off)
+}
+
+// layerEntry is the directory entry of unionFS which returns the *LayerInfo.
+type layerEntry struct {
+	fs.DirEntry
+	base string
+}
+
+// Info implements fs.DirEntry.
+func (e *layerEntry) Info() (fs.FileInfo, error) {
+	fi, err := e.DirEntry.Info()
+	if err != nil {
+		return nil, err
+	}
+	return &LayerInfo{FileInfo: fi, Base: e.base}, nil
+}
+
+// unionDir is the directory file of unionFS which merges the entries across the layers.
+type unionDir struct {
+	unionFile
+
+	u       *unionFS
+	name    string
+	layer   int
+	entries []fs.DirEntry
+	read    bool
+	offset  int
+}
+
+var _ fs.ReadDirFile = (*unionDir)(nil)
+
+// ReadDir implements fs.ReadDirFile.
+func (d *unionDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		entries, err := d.u.readDir(d.name, d.layer)
+		if err != nil {
+			return nil, err
+		}
+		d.entries = entries
+		d.read = true
+	}
+
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+	return rest[:n], nil
+}
@@ -0,0 +1,658 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// writeFileContent writes the data to path with creating its parent directories.
+func writeFileContent(t testing.TB, path, data string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setupLayers creates the synthetic two-layer data directories tree and returns the home and system directories.
+func setupLayers(t *testing.T) (home, system string) {
+	t.Helper()
+
+	root := t.TempDir()
+	home = filepath.Join(root, "home")
+	system = filepath.Join(root, "system")
+
+	writeFileContent(t, filepath.Join(home, "myapp", "themes", "dark.yaml"), "home dark")
+	writeFileContent(t, filepath.Join(home, "myapp", "themes", "custom.yaml"), "home custom")
+	writeFileContent(t, filepath.Join(home, "myapp", "shadow"), "home file")
+	writeFileContent(t, filepath.Join(system, "myapp", "themes", "dark.yaml"), "system dark")
+	writeFileContent(t, filepath.Join(system, "myapp", "themes", "light.yaml"), "system light")
+	writeFileContent(t, filepath.Join(system, "myapp", "grammars", "go.json"), "system go")
+	writeFileContent(t, filepath.Join(system, "myapp", "shadow", "hidden"), "system hidden")
+
+	return home, system
+}
+
+func TestApp_DataFS(t *testing.T) {
+	home, system := setupLayers(t)
+	t.Setenv("XDG_DATA_HOME", home)
+	t.Setenv("XDG_DATA_DIRS", system)
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := app.DataFS()
+
+	if err := fstest.TestFS(fsys, "themes/dark.yaml", "themes/custom.yaml", "themes/light.yaml", "grammars/go.json", "shadow"); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"themes/dark.yaml":  "home dark",
+		"themes/light.yaml": "system light",
+		"shadow":            "home file",
+	}
+	for name, want := range files {
+		got, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("ReadFile(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	if _, err := fs.Stat(fsys, "shadow/hidden"); err == nil {
+		t.Error("shadow/hidden must be shadowed by the home file")
+	}
+
+	entries, err := fs.ReadDir(fsys, "themes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if want := []string{"custom.yaml", "dark.yaml", "light.yaml"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ReadDir(themes) = %v, want %v", names, want)
+	}
+
+	var walked []string
+	if err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			walked = append(walked, path)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"grammars/go.json", "shadow", "themes/custom.yaml", "themes/dark.yaml", "themes/light.yaml"}; !reflect.DeepEqual(walked, want) {
+		t.Errorf("WalkDir = %v, want %v", walked, want)
+	}
+}
+
+func TestApp_ConfigFS(t *testing.T) {
+	home, system := setupLayers(t)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_CONFIG_DIRS", system)
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := app.ConfigFS()
+
+	if err := fstest.TestFS(fsys, "themes/dark.yaml", "themes/custom.yaml", "themes/light.yaml", "grammars/go.json"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := fs.ReadFile(fsys, "themes/dark.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "home dark"; string(got) != want {
+		t.Errorf("ReadFile(themes/dark.yaml) = %q, want %q", got, want)
+	}
+}
+
+func TestDataFS(t *testing.T) {
+	home, system := setupLayers(t)
+	t.Setenv("XDG_DATA_HOME", home)
+	t.Setenv("XDG_DATA_DIRS", system)
+
+	fsys := DataFS()
+	if err := fstest.TestFS(fsys, "myapp/themes/dark.yaml", "myapp/themes/custom.yaml", "myapp/themes/light.yaml", "myapp/grammars/go.json"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := fs.ReadFile(fsys, "myapp/themes/dark.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "home dark"; string(got) != want {
+		t.Errorf("ReadFile(myapp/themes/dark.yaml) = %q, want %q", got, want)
+	}
+
+	entries, err := fs.ReadDir(fsys, "myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if want := []string{"grammars", "shadow", "themes"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ReadDir(myapp) = %v, want %v", names, want)
+	}
+	if entries[1].IsDir() {
+		t.Error("ReadDir(myapp) shadow must be the home file")
+	}
+}
+
+func TestDataFSWithFallback(t *testing.T) {
+	home, system := setupLayers(t)
+	t.Setenv("XDG_DATA_HOME", home)
+	t.Setenv("XDG_DATA_DIRS", system)
+
+	fallback := fstest.MapFS{
+		"myapp/themes/dark.yaml":    {Data: []byte("embedded dark")},
+		"myapp/themes/default.yaml": {Data: []byte("embedded default")},
+		"myapp/templates/page.tmpl": {Data: []byte("embedded page")},
+		"myapp/shadow/embedded":     {Data: []byte("embedded hidden")},
+	}
+	fsys := DataFSWithFallback(fallback)
+	if err := fstest.TestFS(fsys, "myapp/themes/dark.yaml", "myapp/themes/default.yaml", "myapp/templates/page.tmpl", "myapp/grammars/go.json"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		want     string
+		wantBase string
+		wantErr  bool
+	}{
+		{name: "myapp/themes/dark.yaml", want: "home dark", wantBase: home},
+		{name: "myapp/themes/light.yaml", want: "system light", wantBase: system},
+		{name: "myapp/themes/default.yaml", want: "embedded default", wantBase: ""},
+		{name: "myapp/templates/page.tmpl", want: "embedded page", wantBase: ""},
+		{name: "myapp/shadow/embedded", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fs.ReadFile(fsys, tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadFile(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("ReadFile(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+			fi, err := fs.Stat(fsys, tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if base := fi.(*LayerInfo).Base; base != tt.wantBase {
+				t.Errorf("Stat(%q).Base = %q, want %q", tt.name, base, tt.wantBase)
+			}
+		})
+	}
+
+	var walked []string
+	if err := fs.WalkDir(fsys, "myapp/themes", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		walked = append(walked, path)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"myapp/themes",
+		"myapp/themes/custom.yaml",
+		"myapp/themes/dark.yaml",
+		"myapp/themes/default.yaml",
+		"myapp/themes/light.yaml",
+	}
+	if !reflect.DeepEqual(walked, want) {
+		t.Errorf("WalkDir(myapp/themes) = %v, want %v", walked, want)
+	}
+}
+
+func TestDataFSConcurrent(t *testing.T) {
+	home, system := setupLayers(t)
+	t.Setenv("XDG_DATA_HOME", home)
+	t.Setenv("XDG_DATA_DIRS", system)
+
+	fsys := DataFS()
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- fstest.TestFS(fsys, "myapp/themes/dark.yaml", "myapp/themes/light.yaml")
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	fi, err := fs.Stat(fsys, "myapp/themes/dark.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(len("home dark")); fi.Size() != want {
+		t.Errorf("Stat().Size() = %d, want %d of the winning layer", fi.Size(), want)
+	}
+}
+
+func TestConfigFS(t *testing.T) {
+	home, system := setupLayers(t)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_CONFIG_DIRS", system)
+
+	fsys := ConfigFS()
+	if err := fstest.TestFS(fsys, "myapp/themes/dark.yaml", "myapp/themes/light.yaml", "myapp/grammars/go.json"); err != nil {
+		t.Fatal(err)
+	}
+
+	readFile, ok := fsys.(fs.ReadFileFS)
+	if !ok {
+		t.Fatal("ConfigFS() does not implement fs.ReadFileFS")
+	}
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "myapp/themes/dark.yaml", want: "home dark"},
+		{name: "myapp/themes/light.yaml", want: "system light"},
+		{name: "myapp/shadow", want: "home file"},
+		{name: "myapp/shadow/hidden", wantErr: true},
+		{name: "myapp/themes", wantErr: true},
+		{name: "../escape", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readFile.ReadFile(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadFile(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ReadFile(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigFSReadDir(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	etc := filepath.Join(root, "etc", "xdg")
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_CONFIG_DIRS", strings.Join([]string{etc, filepath.Join(root, "missing")}, string(filepath.ListSeparator)))
+
+	writeFileContent(t, filepath.Join(home, "mimeapps.list"), "home")
+	writeFileContent(t, filepath.Join(etc, "mimeapps.list"), "system mimeapps")
+	writeFileContent(t, filepath.Join(home, "themes", "dark"), "home dark")
+	writeFileContent(t, filepath.Join(etc, "themes", "dark"), "system dark")
+	writeFileContent(t, filepath.Join(etc, "themes", "light"), "system light")
+	writeFileContent(t, filepath.Join(etc, "user-dirs.defaults"), "system")
+	writeFileContent(t, filepath.Join(home, "plugins"), "home file hides the system directory")
+	writeFileContent(t, filepath.Join(etc, "plugins", "a.so"), "system plugin")
+
+	fsys := ConfigFS()
+
+	type entry struct {
+		name string
+		dir  bool
+		size int64
+	}
+	tests := []struct {
+		name string
+		dir  string
+		want []entry
+	}{
+		{
+			name: "root",
+			dir:  ".",
+			want: []entry{
+				{name: "mimeapps.list", size: int64(len("home"))},
+				{name: "plugins", size: int64(len("home file hides the system directory"))},
+				{name: "themes", dir: true},
+				{name: "user-dirs.defaults", size: int64(len("system"))},
+			},
+		},
+		{
+			name: "sub directory",
+			dir:  "themes",
+			want: []entry{
+				{name: "dark", size: int64(len("home dark"))},
+				{name: "light", size: int64(len("system light"))},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := fsys.Open(tt.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			dir, ok := f.(fs.ReadDirFile)
+			if !ok {
+				t.Fatalf("Open(%q) is not fs.ReadDirFile", tt.dir)
+			}
+			fileEntries, err := dir.ReadDir(-1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fsEntries, err := fs.ReadDir(fsys, tt.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, entries := range [][]fs.DirEntry{fileEntries, fsEntries} {
+				var got []entry
+				for _, e := range entries {
+					fi, err := e.Info()
+					if err != nil {
+						t.Fatal(err)
+					}
+					size := fi.Size()
+					if e.IsDir() {
+						size = 0
+					}
+					got = append(got, entry{name: e.Name(), dir: e.IsDir(), size: size})
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("ReadDir(%q) = %+v, want %+v", tt.dir, got, tt.want)
+				}
+			}
+		})
+	}
+
+	if _, err := fs.ReadDir(fsys, "plugins"); err == nil {
+		t.Error("ReadDir(plugins) must fail because the home file hides the system directory")
+	}
+}
+
+func TestConfigFSLayerInfo(t *testing.T) {
+	home, system := setupLayers(t)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_CONFIG_DIRS", system)
+
+	fsys := ConfigFS()
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "myapp/themes/dark.yaml", want: home},
+		{name: "myapp/themes/light.yaml", want: system},
+		{name: "myapp/grammars", want: system},
+		{name: "myapp", want: home},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stat := func() (fs.FileInfo, error) { return fs.Stat(fsys, tt.name) }
+			open := func() (fs.FileInfo, error) {
+				f, err := fsys.Open(tt.name)
+				if err != nil {
+					return nil, err
+				}
+				defer f.Close()
+				return f.Stat()
+			}
+			for _, fn := range []func() (fs.FileInfo, error){stat, open} {
+				fi, err := fn()
+				if err != nil {
+					t.Fatal(err)
+				}
+				li, ok := fi.(*LayerInfo)
+				if !ok {
+					t.Fatalf("fs.FileInfo is %T, want *LayerInfo", fi)
+				}
+				if li.Base != tt.want {
+					t.Errorf("Base = %q, want %q", li.Base, tt.want)
+				}
+			}
+		})
+	}
+
+	entries, err := fs.ReadDir(fsys, "myapp/themes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"custom.yaml": home, "dark.yaml": home, "light.yaml": system}
+	for _, entry := range entries {
+		fi, err := entry.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if base := fi.(*LayerInfo).Base; base != want[entry.Name()] {
+			t.Errorf("%s Base = %q, want %q", entry.Name(), base, want[entry.Name()])
+		}
+	}
+}
+
+func TestUnionFSInvalidPath(t *testing.T) {
+	fsys := New().unionFS([]string{t.TempDir()})
+	for _, name := range []string{"/abs", "../escape", "a/../b", ""} {
+		if _, err := fsys.Open(name); err == nil {
+			t.Errorf("Open(%q) expected error", name)
+		}
+	}
+	if _, err := fsys.Open("missing"); !os.IsNotExist(err) {
+		t.Errorf("Open(missing) error = %v, want not exist", err)
+	}
+}
+
+func TestDataFSGlob(t *testing.T) {
+	home, system := setupLayers(t)
+	t.Setenv("XDG_DATA_HOME", home)
+	t.Setenv("XDG_DATA_DIRS", system)
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "merged",
+			pattern: "myapp/*/*",
+			want: []string{
+				"myapp/grammars/go.json",
+				"myapp/themes/custom.yaml",
+				"myapp/themes/dark.yaml",
+				"myapp/themes/light.yaml",
+			},
+		},
+		{
+			name:    "literal",
+			pattern: "myapp/shadow",
+			want:    []string{"myapp/shadow"},
+		},
+		{
+			name:    "hidden by the home file",
+			pattern: "myapp/shadow/*",
+		},
+		{
+			name:    "bad pattern",
+			pattern: "myapp/[",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys, ok := DataFS().(fs.GlobFS)
+			if !ok {
+				t.Fatal("DataFS() does not implement fs.GlobFS")
+			}
+			got, err := fsys.Glob(tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Glob(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Glob(%q) = %q, want %q", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataFSFileServer(t *testing.T) {
+	home, system := setupLayers(t)
+	t.Setenv("XDG_DATA_HOME", home)
+	t.Setenv("XDG_DATA_DIRS", system)
+
+	modTime := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(home, "myapp", "themes", "dark.yaml"), modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(system, "myapp", "themes", "dark.yaml"), modTime.Add(time.Hour), modTime.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	srv := http.FileServer(http.FS(DataFS()))
+
+	tests := []struct {
+		name         string
+		path         string
+		wantCode     int
+		wantBody     string
+		wantContains []string
+		wantHeader   map[string]string
+	}{
+		{
+			name:     "home file",
+			path:     "/myapp/themes/dark.yaml",
+			wantCode: http.StatusOK,
+			wantBody: "home dark",
+			wantHeader: map[string]string{
+				"Content-Length": "9",
+				"Last-Modified":  modTime.Format(http.TimeFormat),
+			},
+		},
+		{
+			name:       "system file",
+			path:       "/myapp/grammars/go.json",
+			wantCode:   http.StatusOK,
+			wantBody:   "system go",
+			wantHeader: map[string]string{"Content-Length": "9"},
+		},
+		{
+			name:         "merged listing",
+			path:         "/myapp/themes/",
+			wantCode:     http.StatusOK,
+			wantContains: []string{"custom.yaml", "dark.yaml", "light.yaml"},
+		},
+		{
+			name:     "hidden",
+			path:     "/myapp/shadow/hidden",
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("GET %s status = %d, want %d", tt.path, rec.Code, tt.wantCode)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("GET %s body = %q, want %q", tt.path, rec.Body.String(), tt.wantBody)
+			}
+			for _, s := range tt.wantContains {
+				if !strings.Contains(rec.Body.String(), s) {
+					t.Errorf("GET %s body = %q, want to contain %q", tt.path, rec.Body.String(), s)
+				}
+			}
+			for k, v := range tt.wantHeader {
+				if got := rec.Header().Get(k); got != v {
+					t.Errorf("GET %s header %s = %q, want %q", tt.path, k, got, v)
+				}
+			}
+		})
+	}
+}
+
+// openOnlyFS hides every optional interface of the fs.FS, so fs.WalkDir falls back to opening each directory.
+type openOnlyFS struct {
+	fs.FS
+}
+
+func BenchmarkDataFSWalkDir(b *testing.B) {
+	root := b.TempDir()
+	home := filepath.Join(root, "home")
+	system := filepath.Join(root, "system")
+	// 10k files across the two layers, and half of the directories are merged
+	for _, base := range []string{home, system} {
+		for i := 0; i < 50; i++ {
+			for j := 0; j < 100; j++ {
+				dir := fmt.Sprintf("icons/%s-%02d", filepath.Base(base), i)
+				if i%2 == 0 {
+					dir = fmt.Sprintf("icons/shared-%02d", i)
+				}
+				writeFileContent(b, filepath.Join(base, filepath.FromSlash(dir), fmt.Sprintf("%s-%03d.png", filepath.Base(base), j)), "")
+			}
+		}
+	}
+	x := New(WithEnv(map[string]string{"XDG_DATA_HOME": home, "XDG_DATA_DIRS": system}))
+
+	benchmarks := []struct {
+		name string
+		fsys fs.FS
+	}{
+		{name: "OpenOnly", fsys: openOnlyFS{x.DataFS()}},
+		{name: "ReadDirFS", fsys: x.DataFS()},
+	}
+	for _, bb := range benchmarks {
+		b.Run(bb.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				n := 0
+				if err := fs.WalkDir(bb.fsys, "icons", func(_ string, d fs.DirEntry, err error) error {
+					if err != nil {
+						return err
+					}
+					if !d.IsDir() {
+						n++
+					}
+					return nil
+				}); err != nil {
+					b.Fatal(err)
+				}
+				if n != 10000 {
+					b.Fatalf("walked %d files, want 10000", n)
+				}
+			}
+		})
+	}
+}
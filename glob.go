@@ -0,0 +1,115 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// Match is the file matched by GlobData.
+type Match struct {
+	// Path is the absolute path of the matched file.
+	Path string
+	// Base is the base directory which the file came from, such as "/usr/share".
+	Base string
+	// Rel is the slash separated path relative to Base, such as "applications/firefox.desktop".
+	Rel string
+}
+
+type globConfig struct {
+	shadowing bool
+}
+
+// GlobOption configures GlobData and GlobConfig.
+type GlobOption func(*globConfig)
+
+// WithShadowing returns the GlobOption which controls whether the matches of the same relative path collapse to
+// the highest-precedence one. By default, all matches are returned.
+func WithShadowing(shadowing bool) GlobOption {
+	return func(c *globConfig) {
+		c.shadowing = shadowing
+	}
+}
+
+// GlobData returns the files matching pattern in $XDG_DATA_HOME and each entry of $XDG_DATA_DIRS, such as
+// "applications/*.desktop" or "icons/*/index.theme".
+//
+// The pattern is the slash separated relative path, and follows the path.Match semantics per path segment same as
+// fs.Glob. The matches are ordered by the precedence of base directories, and then by the filename within each base,
+// so the ordering of drop-in files is deterministic. The only possible error is path.ErrBadPattern or the invalid
+// pattern such as "../*".
+func GlobData(pattern string, opts ...GlobOption) ([]Match, error) {
+	return std.GlobData(pattern, opts...)
+}
+
+// GlobData returns the files matching pattern in the data directories.
+//
+// See the package level GlobData function for details.
+func (x *XDG) GlobData(pattern string, opts ...GlobOption) ([]Match, error) {
+	return x.glob(x.DataDirsAll(), pattern, opts)
+}
+
+// GlobConfig returns the absolute paths of the files matching pattern in $XDG_CONFIG_HOME and each entry of
+// $XDG_CONFIG_DIRS, such as the drop-in files "myapp/conf.d/*.conf".
+//
+// Each path appears once, in the same order as GlobData. With WithShadowing(true), the user file overrides the
+// system file of the same relative path. Otherwise, all copies are returned, which is how the plugin loaders find
+// "plugins/*.so" across all configuration directories. The directory which can't be read, such as permission
+// denied, is skipped and the others are still searched. See GlobData for the semantics.
+func GlobConfig(pattern string, opts ...GlobOption) ([]string, error) {
+	return std.GlobConfig(pattern, opts...)
+}
+
+// GlobConfig returns the files matching pattern in the configuration directories.
+//
+// See the package level GlobConfig function for details.
+func (x *XDG) GlobConfig(pattern string, opts ...GlobOption) ([]string, error) {
+	matches, err := x.glob(x.ConfigDirsAll(), pattern, opts)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, len(matches))
+	for i, m := range matches {
+		paths[i] = m.Path
+	}
+	return paths, nil
+}
+
+// glob returns the files matching pattern in each dirs.
+func (x *XDG) glob(dirs []string, pattern string, opts []GlobOption) ([]Match, error) {
+	var c globConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	if !validPath(pattern) {
+		return nil, fmt.Errorf("xdgbasedir: invalid glob pattern %q", pattern)
+	}
+
+	var matches []Match
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		rels, err := fs.Glob(x.dirFS(dir), pattern)
+		if err != nil {
+			return nil, err
+		}
+		// sort by the filename, so the drop-in ordering does not depend on the directory names
+		sort.SliceStable(rels, func(i, j int) bool { return path.Base(rels[i]) < path.Base(rels[j]) })
+		for _, rel := range rels {
+			if c.shadowing {
+				if seen[rel] {
+					continue
+				}
+				seen[rel] = true
+			}
+			matches = append(matches, Match{Path: filepath.Join(dir, filepath.FromSlash(rel)), Base: dir, Rel: rel})
+		}
+	}
+	return matches, nil
+}
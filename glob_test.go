@@ -0,0 +1,242 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGlobData(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	local := filepath.Join(root, "usr", "local", "share")
+	system := filepath.Join(root, "usr", "share")
+	t.Setenv("XDG_DATA_HOME", home)
+	t.Setenv("XDG_DATA_DIRS", strings.Join([]string{local, system}, string(filepath.ListSeparator)))
+
+	writeFile(t, filepath.Join(home, "applications", "editor.desktop"))
+	writeFile(t, filepath.Join(local, "applications", "browser.desktop"))
+	writeFile(t, filepath.Join(system, "applications", "browser.desktop"))
+	writeFile(t, filepath.Join(system, "applications", "editor.desktop"))
+	writeFile(t, filepath.Join(system, "applications", "README"))
+	writeFile(t, filepath.Join(home, "icons", "custom", "index.theme"))
+	writeFile(t, filepath.Join(system, "icons", "hicolor", "index.theme"))
+	writeFile(t, filepath.Join(system, "icons", "hicolor", "16x16", "index.theme"))
+
+	match := func(base, rel string) Match {
+		return Match{Path: filepath.Join(base, filepath.FromSlash(rel)), Base: base, Rel: rel}
+	}
+	tests := []struct {
+		name    string
+		pattern string
+		opts    []GlobOption
+		want    []Match
+		wantErr bool
+	}{
+		{
+			name:    "all copies",
+			pattern: "applications/*.desktop",
+			want: []Match{
+				match(home, "applications/editor.desktop"),
+				match(local, "applications/browser.desktop"),
+				match(system, "applications/browser.desktop"),
+				match(system, "applications/editor.desktop"),
+			},
+		},
+		{
+			name:    "shadowing",
+			pattern: "applications/*.desktop",
+			opts:    []GlobOption{WithShadowing(true)},
+			want: []Match{
+				match(home, "applications/editor.desktop"),
+				match(local, "applications/browser.desktop"),
+			},
+		},
+		{
+			name:    "per segment",
+			pattern: "icons/*/index.theme",
+			want: []Match{
+				match(home, "icons/custom/index.theme"),
+				match(system, "icons/hicolor/index.theme"),
+			},
+		},
+		{
+			name:    "no match",
+			pattern: "mime/*",
+		},
+		{
+			name:    "bad pattern",
+			pattern: "applications/[",
+			wantErr: true,
+		},
+		{
+			name:    "escape",
+			pattern: "../*",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GlobData(tt.pattern, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GlobData(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GlobData(%q) = %+v, want %+v", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlobConfig(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	etc := filepath.Join(root, "etc", "xdg")
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_CONFIG_DIRS", etc)
+
+	writeFile(t, filepath.Join(home, "autostart", "b.desktop"))
+	writeFile(t, filepath.Join(etc, "autostart", "a.desktop"))
+	writeFile(t, filepath.Join(etc, "autostart", "b.desktop"))
+
+	got, err := GlobConfig("autostart/*.desktop", WithShadowing(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(home, "autostart", "b.desktop"),
+		filepath.Join(etc, "autostart", "a.desktop"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GlobConfig() = %q, want %q", got, want)
+	}
+}
+
+func TestGlobConfigDropIn(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	etc := filepath.Join(root, "etc", "xdg")
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_CONFIG_DIRS", etc)
+
+	writeFile(t, filepath.Join(home, "myapp", "conf.d", "50-user.conf"))
+	writeFile(t, filepath.Join(home, "myapp", "conf.d", "10-base.conf"))
+	writeFile(t, filepath.Join(etc, "myapp", "conf.d", "10-base.conf"))
+	writeFile(t, filepath.Join(etc, "myapp", "conf.d", "20-vendor.conf"))
+	writeFile(t, filepath.Join(etc, "plugins", "zz", "conf.d", "00-first.conf"))
+	writeFile(t, filepath.Join(etc, "plugins", "aa", "conf.d", "99-last.conf"))
+
+	rels := func(paths []string) []string {
+		var rels []string
+		for _, p := range paths {
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rels = append(rels, filepath.ToSlash(rel))
+		}
+		return rels
+	}
+	tests := []struct {
+		name    string
+		pattern string
+		opts    []GlobOption
+		want    []string
+	}{
+		{
+			name:    "all copies",
+			pattern: "myapp/conf.d/*.conf",
+			want: []string{
+				"home/myapp/conf.d/10-base.conf",
+				"home/myapp/conf.d/50-user.conf",
+				"etc/xdg/myapp/conf.d/10-base.conf",
+				"etc/xdg/myapp/conf.d/20-vendor.conf",
+			},
+		},
+		{
+			name:    "user overrides system",
+			pattern: "myapp/conf.d/*.conf",
+			opts:    []GlobOption{WithShadowing(true)},
+			want: []string{
+				"home/myapp/conf.d/10-base.conf",
+				"home/myapp/conf.d/50-user.conf",
+				"etc/xdg/myapp/conf.d/20-vendor.conf",
+			},
+		},
+		{
+			name:    "without shadowing",
+			pattern: "myapp/conf.d/*.conf",
+			opts:    []GlobOption{WithShadowing(true), WithShadowing(false)},
+			want: []string{
+				"home/myapp/conf.d/10-base.conf",
+				"home/myapp/conf.d/50-user.conf",
+				"etc/xdg/myapp/conf.d/10-base.conf",
+				"etc/xdg/myapp/conf.d/20-vendor.conf",
+			},
+		},
+		{
+			name:    "sorted by filename",
+			pattern: "plugins/*/conf.d/*.conf",
+			want: []string{
+				"etc/xdg/plugins/zz/conf.d/00-first.conf",
+				"etc/xdg/plugins/aa/conf.d/99-last.conf",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GlobConfig(tt.pattern, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rels(got), tt.want) {
+				t.Errorf("GlobConfig(%q) = %v, want %v", tt.pattern, rels(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestGlobConfigPlugins(t *testing.T) {
+	root := string(filepath.Separator)
+	if runtime.GOOS == "windows" {
+		root = `C:\`
+	}
+	home := filepath.Join(root, "home", ".config")
+	vendor := filepath.Join(root, "etc", "vendor")
+	etc := filepath.Join(root, "etc", "xdg")
+
+	fsys := deniedFS{
+		fsys: fstest.MapFS{
+			"home/.config/plugins/b.so": {},
+			"home/.config/plugins/c.so": {},
+			"etc/vendor/plugins/z.so":   {},
+			"etc/xdg/plugins/a.so":      {},
+			"etc/xdg/plugins/b.so":      {},
+			"etc/xdg/plugins/README":    {},
+		},
+		denied: "etc/vendor/plugins",
+	}
+	x := New(WithFS(fsys), WithLookupEnv(mapLookupEnv(map[string]string{
+		"XDG_CONFIG_HOME": home,
+		"XDG_CONFIG_DIRS": strings.Join([]string{vendor, etc}, string(filepath.ListSeparator)),
+	})))
+	got, err := x.GlobConfig("plugins/*.so", WithShadowing(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(home, "plugins", "b.so"),
+		filepath.Join(home, "plugins", "c.so"),
+		filepath.Join(etc, "plugins", "a.so"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GlobConfig() = %q, want %q", got, want)
+	}
+}
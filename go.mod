@@ -0,0 +1,3 @@
+module github.com/zchee/go-xdgbasedir
+
+go 1.20
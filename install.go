@@ -0,0 +1,126 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// installSumDir is the sub directory name of the checksum manifest of installed data files under $XDG_STATE_HOME/<app>.
+const installSumDir = "installed"
+
+// ErrModified is returned by App.InstallDataFile if the installed data file is modified by the user.
+var ErrModified = errors.New("xdgbasedir: file is modified by the user")
+
+type installConfig struct {
+	force     bool
+	ifMissing bool
+}
+
+// InstallOption configures App.InstallDataFile.
+type InstallOption func(*installConfig)
+
+// InstallForce returns the InstallOption which overwrites the target even if it is modified by the user.
+func InstallForce() InstallOption {
+	return func(c *installConfig) {
+		c.force = true
+	}
+}
+
+// InstallIfMissing returns the InstallOption which installs the file only if the target does not exist.
+// It takes precedence over InstallForce.
+func InstallIfMissing() InstallOption {
+	return func(c *installConfig) {
+		c.ifMissing = true
+	}
+}
+
+// InstallDataFile materializes the name file of src, such as the embed.FS of default assets, to the rel file under
+// $XDG_DATA_HOME/<app>, and reports whether the file is written.
+//
+// The file is written atomically same as WriteConfigFile, and its SHA-256 checksum is recorded in the manifest
+// under $XDG_STATE_HOME/<app>/installed. The copy is skipped if the target already has the same content as src.
+// If the target is what was previously installed, it is replaced with the new src. Otherwise, the target is
+// modified by the user or not installed by InstallDataFile, and InstallDataFile refuses to overwrite it and returns
+// the error which satisfies errors.Is(err, ErrModified), unless InstallForce is given.
+func (a *App) InstallDataFile(src fs.FS, name, rel string, opts ...InstallOption) (bool, error) {
+	var c installConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	rel, err := cleanRel(rel)
+	if err != nil {
+		return false, err
+	}
+	path := filepath.Join(a.DataHome(), rel)
+	sumPath := filepath.Join(a.StateHome(), installSumDir, rel+".sha256")
+
+	data, err := fs.ReadFile(src, name)
+	if err != nil {
+		return false, err
+	}
+	sum := checksum(data)
+
+	cur, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		// install
+	case err != nil:
+		return false, err
+	case c.ifMissing:
+		return false, nil
+	default:
+		curSum := checksum(cur)
+		if curSum == sum {
+			// up to date, and record the checksum in case the manifest is lost
+			return false, recordChecksum(sumPath, sum)
+		}
+		if !c.force {
+			recorded, err := os.ReadFile(sumPath)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return false, err
+			}
+			if string(bytes.TrimSpace(recorded)) != curSum {
+				return false, fmt.Errorf("%s: %w", path, ErrModified)
+			}
+		}
+	}
+
+	w, err := createAtomic(path, 0)
+	if err != nil {
+		return false, err
+	}
+	if err := writeAtomic(w, data); err != nil {
+		return false, err
+	}
+	return true, recordChecksum(sumPath, sum)
+}
+
+// recordChecksum records the sum checksum to the manifest path, unless it is already recorded.
+func recordChecksum(path, sum string) error {
+	recorded, err := os.ReadFile(path)
+	if err == nil && string(bytes.TrimSpace(recorded)) == sum {
+		return nil
+	}
+	w, err := createAtomic(path, 0600)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(w, []byte(sum+"\n"))
+}
+
+// checksum returns the hex encoded SHA-256 checksum of data.
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
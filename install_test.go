@@ -0,0 +1,125 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestApp_InstallDataFile(t *testing.T) {
+	tests := []struct {
+		name string
+		// previous is the content installed by the previous release, or empty
+		previous string
+		// edit is the content which the user overwrites the installed file with, or empty
+		edit          string
+		opts          []InstallOption
+		wantInstalled bool
+		want          string
+		wantErr       error
+	}{
+		{
+			name:          "first run",
+			wantInstalled: true,
+			want:          "v2",
+		},
+		{
+			name:     "up to date",
+			previous: "v2",
+			want:     "v2",
+		},
+		{
+			name:          "upgrade",
+			previous:      "v1",
+			wantInstalled: true,
+			want:          "v2",
+		},
+		{
+			name:     "user modified",
+			previous: "v1",
+			edit:     "mine",
+			want:     "mine",
+			wantErr:  ErrModified,
+		},
+		{
+			name:          "force",
+			previous:      "v1",
+			edit:          "mine",
+			opts:          []InstallOption{InstallForce()},
+			wantInstalled: true,
+			want:          "v2",
+		},
+		{
+			name:     "if missing",
+			previous: "v1",
+			opts:     []InstallOption{InstallIfMissing(), InstallForce()},
+			want:     "v1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			t.Setenv("XDG_DATA_HOME", filepath.Join(root, "data"))
+			t.Setenv("XDG_STATE_HOME", filepath.Join(root, "state"))
+
+			app, err := NewApp("myapp")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.previous != "" {
+				prev := fstest.MapFS{"theme.toml": {Data: []byte(tt.previous)}}
+				if _, err := app.InstallDataFile(prev, "theme.toml", "themes/default.toml"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			path := filepath.Join(root, "data", "myapp", "themes", "default.toml")
+			if tt.edit != "" {
+				writeFileContent(t, path, tt.edit)
+			}
+
+			src := fstest.MapFS{"theme.toml": {Data: []byte("v2")}}
+			installed, err := app.InstallDataFile(src, "theme.toml", "themes/default.toml", tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("InstallDataFile() error = %v, want %v", err, tt.wantErr)
+			}
+			if installed != tt.wantInstalled {
+				t.Errorf("InstallDataFile() = %v, want %v", installed, tt.wantInstalled)
+			}
+			if s := readString(t, path); s != tt.want {
+				t.Errorf("content = %q, want %q", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestApp_InstallDataFileLostManifest(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(root, "data"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(root, "state"))
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the file which has the same content is adopted, and the unknown file is never overwritten
+	writeFileContent(t, filepath.Join(root, "data", "myapp", "same"), "v1")
+	writeFileContent(t, filepath.Join(root, "data", "myapp", "unknown"), "mine")
+	src := fstest.MapFS{"asset": {Data: []byte("v1")}}
+
+	if _, err := app.InstallDataFile(src, "asset", "same"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "state", "myapp", installSumDir, "same.sha256")); err != nil {
+		t.Errorf("checksum is not recorded: %v", err)
+	}
+	if _, err := app.InstallDataFile(src, "asset", "unknown"); !errors.Is(err, ErrModified) {
+		t.Errorf("InstallDataFile() error = %v, want ErrModified", err)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import "fmt"
+
+// Kind represents a kind of the XDG base directory.
+type Kind int
+
+const (
+	// KindDataHome is the kind of $XDG_DATA_HOME.
+	KindDataHome Kind = iota
+	// KindConfigHome is the kind of $XDG_CONFIG_HOME.
+	KindConfigHome
+	// KindDataDirs is the kind of $XDG_DATA_DIRS.
+	KindDataDirs
+	// KindConfigDirs is the kind of $XDG_CONFIG_DIRS.
+	KindConfigDirs
+	// KindCacheHome is the kind of $XDG_CACHE_HOME.
+	KindCacheHome
+	// KindStateHome is the kind of $XDG_STATE_HOME.
+	KindStateHome
+	// KindRuntimeDir is the kind of $XDG_RUNTIME_DIR.
+	KindRuntimeDir
+	// KindBinHome is the kind of $XDG_BIN_HOME.
+	KindBinHome
+)
+
+// kinds is the all kinds in order.
+var kinds = [...]Kind{
+	KindDataHome, KindConfigHome, KindDataDirs, KindConfigDirs, KindCacheHome, KindStateHome, KindBinHome, KindRuntimeDir,
+}
+
+// String returns the name of k such as "config-home", which is suitable for logging and CLI flags.
+func (k Kind) String() string {
+	switch k {
+	case KindDataHome:
+		return "data-home"
+	case KindConfigHome:
+		return "config-home"
+	case KindDataDirs:
+		return "data-dirs"
+	case KindConfigDirs:
+		return "config-dirs"
+	case KindCacheHome:
+		return "cache-home"
+	case KindStateHome:
+		return "state-home"
+	case KindRuntimeDir:
+		return "runtime-dir"
+	case KindBinHome:
+		return "bin-home"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// env returns the environment variable name of k.
+func (k Kind) env() string {
+	switch k {
+	case KindDataHome:
+		return EnvDataHome
+	case KindConfigHome:
+		return EnvConfigHome
+	case KindDataDirs:
+		return EnvDataDirs
+	case KindConfigDirs:
+		return EnvConfigDirs
+	case KindCacheHome:
+		return EnvCacheHome
+	case KindStateHome:
+		return EnvStateHome
+	case KindRuntimeDir:
+		return EnvRuntimeDir
+	case KindBinHome:
+		return EnvBinHome
+	default:
+		return ""
+	}
+}
+
+// IsSet reports whether the environment variable corresponding to kind is present and non-empty.
+//
+// It distinguishes the directory explicitly set by the user from the default.
+func IsSet(kind Kind) bool {
+	return std.IsSet(kind)
+}
+
+// IsSet reports whether the environment variable of kind is present and non-empty in the environment of x.
+//
+// See the package level IsSet function for details.
+func (x *XDG) IsSet(kind Kind) bool {
+	env := kind.env()
+	if env == "" {
+		return false
+	}
+	v, ok := x.lookupenv(env)
+	return ok && v != ""
+}
+
+// Dir returns the directory path of kind. See XDG.Dir.
+func Dir(kind Kind) (string, error) {
+	return std.Dir(kind)
+}
+
+// Dir returns the directory path of kind, dispatching to the accessor such as ConfigHome for KindConfigHome.
+//
+// For KindDataDirs and KindConfigDirs, Dir returns the list separated by filepath.ListSeparator, same as DataDirs
+// and ConfigDirs. It returns an error for the unknown kind.
+func (x *XDG) Dir(kind Kind) (string, error) {
+	switch kind {
+	case KindDataHome:
+		return x.DataHome(), nil
+	case KindConfigHome:
+		return x.ConfigHome(), nil
+	case KindDataDirs:
+		return x.DataDirs(), nil
+	case KindConfigDirs:
+		return x.ConfigDirs(), nil
+	case KindCacheHome:
+		return x.CacheHome(), nil
+	case KindStateHome:
+		return x.StateHome(), nil
+	case KindRuntimeDir:
+		return x.RuntimeDir(), nil
+	case KindBinHome:
+		return x.BinHome(), nil
+	default:
+		return "", fmt.Errorf("xdgbasedir: unknown kind %v", kind)
+	}
+}
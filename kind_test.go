@@ -0,0 +1,134 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		kind  Kind
+		env   string
+		unset bool
+		want  bool
+	}{
+		{
+			name: "set",
+			kind: KindConfigHome,
+			env:  filepath.Join("/tmp", "config"),
+			want: true,
+		},
+		{
+			name: "empty",
+			kind: KindConfigHome,
+			env:  "",
+			want: false,
+		},
+		{
+			name:  "unset",
+			kind:  KindStateHome,
+			unset: true,
+			want:  false,
+		},
+		{
+			name: "runtime dir",
+			kind: KindRuntimeDir,
+			env:  filepath.Join("/run", "user", "1000"),
+			want: true,
+		},
+		{
+			name: "unknown kind",
+			kind: Kind(-1),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if env := tt.kind.env(); env != "" {
+				t.Setenv(env, tt.env)
+				if tt.unset {
+					os.Unsetenv(env)
+				}
+			}
+			if got := IsSet(tt.kind); got != tt.want {
+				t.Errorf("IsSet(%v) = %v, want %v", tt.kind, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXDG_IsSet(t *testing.T) {
+	// the injected environment wins over the process one
+	t.Setenv("XDG_CACHE_HOME", filepath.Join("/tmp", "cache"))
+	t.Setenv("XDG_STATE_HOME", "")
+
+	x := New(WithEnv(map[string]string{
+		"XDG_CONFIG_HOME": filepath.Join("/tmp", "config"),
+		"XDG_STATE_HOME":  filepath.Join("/tmp", "state"),
+		"XDG_DATA_HOME":   "",
+		"XDG_CACHE_HOME":  "",
+	}))
+	for kind, want := range map[Kind]bool{
+		KindConfigHome: true,
+		KindStateHome:  true,
+		KindDataHome:   false,
+		KindCacheHome:  false,
+		Kind(-1):       false,
+	} {
+		if got := x.IsSet(kind); got != want {
+			t.Errorf("IsSet(%v) = %v, want %v", kind, got, want)
+		}
+	}
+}
+
+func TestDir(t *testing.T) {
+	x := New(WithLookupEnv(mapLookupEnv(map[string]string{
+		"XDG_DATA_HOME":   filepath.Join("/tmp", "data"),
+		"XDG_CONFIG_HOME": filepath.Join("/tmp", "config"),
+		"XDG_CACHE_HOME":  filepath.Join("/tmp", "cache"),
+		"XDG_STATE_HOME":  filepath.Join("/tmp", "state"),
+		"XDG_RUNTIME_DIR": filepath.Join("/tmp", "runtime"),
+		"XDG_BIN_HOME":    filepath.Join("/tmp", "bin"),
+	})))
+
+	tests := []struct {
+		kind     Kind
+		wantName string
+		want     string
+		wantErr  bool
+	}{
+		{kind: KindDataHome, wantName: "data-home", want: x.DataHome()},
+		{kind: KindConfigHome, wantName: "config-home", want: x.ConfigHome()},
+		{kind: KindDataDirs, wantName: "data-dirs", want: x.DataDirs()},
+		{kind: KindConfigDirs, wantName: "config-dirs", want: x.ConfigDirs()},
+		{kind: KindCacheHome, wantName: "cache-home", want: x.CacheHome()},
+		{kind: KindStateHome, wantName: "state-home", want: x.StateHome()},
+		{kind: KindRuntimeDir, wantName: "runtime-dir", want: x.RuntimeDir()},
+		{kind: KindBinHome, wantName: "bin-home", want: filepath.Join("/tmp", "bin")},
+		{kind: Kind(100), wantName: "Kind(100)", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantName, func(t *testing.T) {
+			if got := tt.kind.String(); got != tt.wantName {
+				t.Errorf("String() = %v, want %v", got, tt.wantName)
+			}
+			got, err := x.Dir(tt.kind)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Dir(%v) error = %v, wantErr %v", tt.kind, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Dir(%v) = %v, want %v", tt.kind, got, tt.want)
+			}
+		})
+	}
+
+	if got, err := Dir(KindConfigHome); err != nil || got != ConfigHome() {
+		t.Errorf("Dir(KindConfigHome) = %v, %v, want %v", got, err, ConfigHome())
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"path/filepath"
+)
+
+// Layer is the configuration file of a base directory returned by ReadConfigLayers.
+type Layer struct {
+	// Path is the path of the file.
+	Path string
+	// Base is the base directory of the file, such as "/etc/xdg".
+	Base string
+	// User reports whether Base is $XDG_CONFIG_HOME, rather than the system directory of $XDG_CONFIG_DIRS.
+	User bool
+	// Data is the content of the file.
+	Data []byte
+}
+
+// ReadConfigLayers reads every existing regular file of rel in $XDG_CONFIG_HOME and each entry of $XDG_CONFIG_DIRS,
+// and returns them ordered from the lowest precedence to the highest, such as for the drop-in style configuration
+// which applies the later layers over the earlier ones.
+//
+// Note that the order is the reverse of SearchAllConfigFiles, so the user file is the last. The same directory
+// appeared twice in the environment is read once, and the missing files are skipped, including the file removed
+// while reading. ReadConfigLayers returns the empty result without error if no file is found, so the error means
+// rel is not resolvable or the existing file can't be read. See ConcatLayers for the line-oriented formats.
+func ReadConfigLayers(rel string) ([]Layer, error) {
+	return std.ReadConfigLayers(rel)
+}
+
+// ReadConfigLayers reads every existing file of rel in the configuration directories.
+//
+// See the package level ReadConfigLayers function for details.
+func (x *XDG) ReadConfigLayers(rel string) ([]Layer, error) {
+	clean, err := cleanRel(rel)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := x.ConfigDirsAll()
+	home := x.ConfigHome()
+	var layers []Layer
+	for i := len(dirs) - 1; i >= 0; i-- {
+		path := filepath.Join(dirs[i], clean)
+		if x.probe(path, &existsConfig{require: requireFile}) != nil {
+			continue
+		}
+		data, err := x.readFile(path)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		layers = append(layers, Layer{Path: path, Base: dirs[i], User: i == 0 && dirs[i] == filepath.Clean(home), Data: data})
+	}
+	return layers, nil
+}
+
+// ConcatLayers concatenates the data of layers in order with sep between them, such as "\n" or "# ---\n", for
+// the line-oriented formats parsed at once.
+//
+// The newline is appended to each data which does not end with it, so the last line of a layer is never joined
+// with the first line of the next one.
+func ConcatLayers(layers []Layer, sep string) []byte {
+	var buf bytes.Buffer
+	for i, l := range layers {
+		if i > 0 {
+			buf.WriteString(sep)
+		}
+		buf.Write(l.Data)
+		if len(l.Data) > 0 && l.Data[len(l.Data)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,95 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadConfigLayers(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	local := filepath.Join(root, "etc", "local")
+	etc := filepath.Join(root, "etc", "xdg")
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_CONFIG_DIRS", strings.Join([]string{local, filepath.Join(root, "missing"), etc, local}, string(filepath.ListSeparator)))
+
+	writeFileContent(t, filepath.Join(home, "myapp", "app.conf"), "user=1\n")
+	writeFileContent(t, filepath.Join(etc, "myapp", "app.conf"), "system=1")
+	writeFileContent(t, filepath.Join(local, "myapp", "app.conf", "not a file"), "")
+	writeFileContent(t, filepath.Join(local, "myapp", "only.conf"), "local=1\n")
+
+	tests := []struct {
+		name    string
+		rel     string
+		want    []Layer
+		wantErr bool
+	}{
+		{
+			name: "lowest first",
+			rel:  "myapp/app.conf",
+			want: []Layer{
+				{Path: filepath.Join(etc, "myapp", "app.conf"), Base: etc, Data: []byte("system=1")},
+				{Path: filepath.Join(home, "myapp", "app.conf"), Base: home, User: true, Data: []byte("user=1\n")},
+			},
+		},
+		{
+			name: "system only",
+			rel:  "myapp/only.conf",
+			want: []Layer{
+				{Path: filepath.Join(local, "myapp", "only.conf"), Base: local, Data: []byte("local=1\n")},
+			},
+		},
+		{
+			name: "missing",
+			rel:  "myapp/missing.conf",
+		},
+		{
+			name:    "invalid",
+			rel:     "../app.conf",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadConfigLayers(tt.rel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadConfigLayers(%q) error = %v, wantErr %v", tt.rel, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadConfigLayers(%q) = %+v, want %+v", tt.rel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConcatLayers(t *testing.T) {
+	layers := []Layer{
+		{Data: []byte("system=1")},
+		{Data: nil},
+		{Data: []byte("user=1\n")},
+	}
+
+	tests := []struct {
+		name   string
+		layers []Layer
+		sep    string
+		want   string
+	}{
+		{name: "empty"},
+		{name: "newline", layers: layers, sep: "", want: "system=1\nuser=1\n"},
+		{name: "separator", layers: layers, sep: "# ---\n", want: "system=1\n# ---\n# ---\nuser=1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(ConcatLayers(tt.layers, tt.sep)); got != tt.want {
+				t.Errorf("ConcatLayers() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// ErrLocked is returned by LockFile when the lock is held by another process.
+var ErrLocked = errors.New("xdgbasedir: locked by another process")
+
+// FileLock is the advisory inter-process lock backed by the lock file.
+//
+// It is built on flock(2) on unix and LockFileEx on windows. The lock is held per FileLock, so the two FileLocks
+// of the same path exclude each other even in one process.
+//
+// The lock file is opened with close-on-exec, so the lock is not inherited by the child processes started by
+// os/exec. If the lock file is passed to the child explicitly, such as via exec.Cmd.ExtraFiles, the child shares
+// the same lock, and it is released when all copies of the file descriptor are closed.
+// The lock is released automatically when the process exits.
+type FileLock struct {
+	path string
+
+	mu sync.Mutex
+	f  *os.File
+}
+
+// LockFile returns the FileLock of name in $XDG_RUNTIME_DIR/<app>.
+//
+// If no secure runtime directory is available, the lock file is placed in $XDG_STATE_HOME/<app> instead.
+// The runtime directory is preferred, because the lock files on tmpfs vanish at logout.
+func (a *App) LockFile(name string) (*FileLock, error) {
+	path, err := a.RuntimeFile(name)
+	if err != nil {
+		var rerr *RuntimeDirError
+		if !errors.As(err, &rerr) {
+			return nil, err
+		}
+		if path, err = a.StateFile(name); err != nil {
+			return nil, err
+		}
+	}
+	return &FileLock{path: path}, nil
+}
+
+// LockFile creates the lock file $XDG_RUNTIME_DIR/<name>.lock and acquires the advisory lock on it without blocking,
+// such as for the single-instance enforcement of the daemon. The ".lock" extension is not appended if name already
+// has it.
+//
+// If the lock is held by another process, LockFile returns the error which matches to ErrLocked via errors.Is.
+// The release func unlocks the lock and removes the lock file, and calling it twice is no-op. The lock is also
+// released when the process exits, leaving the lock file which the next LockFile reuses.
+//
+// The runtime directory is ensured same as EnsureRuntimeDir, and name must not contain the path separators.
+// Use App.LockFile for the per-app lock which needs the blocking Lock.
+func LockFile(name string) (release func() error, err error) {
+	return std.LockFile(name)
+}
+
+// LockFile creates the lock file under the runtime directory and acquires the lock on it.
+//
+// See the package level LockFile function for details.
+func (x *XDG) LockFile(name string) (release func() error, err error) {
+	if err := validBaseName("lock", name); err != nil {
+		return nil, err
+	}
+	if filepath.Ext(name) != ".lock" {
+		name += ".lock"
+	}
+	dir := x.RuntimeDir()
+	if err := ensureRuntimeDir(dir); err != nil {
+		return nil, err
+	}
+
+	l := &FileLock{path: filepath.Join(dir, name)}
+	for {
+		ok, err := l.TryLock()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("xdgbasedir: %s: %w", l.path, ErrLocked)
+		}
+		// the previous holder may have removed the file between our open and lock, and then the lock is on
+		// the unlinked file which the next process never sees
+		if l.isCurrent() {
+			break
+		}
+		if err := l.Unlock(); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		once sync.Once
+		rerr error
+	)
+	release = func() error {
+		once.Do(func() {
+			rerr = l.release()
+		})
+		return rerr
+	}
+	return release, nil
+}
+
+// isCurrent reports whether the locked file is still the file at the path.
+func (l *FileLock) isCurrent() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	fi, err := l.f.Stat()
+	if err != nil {
+		return false
+	}
+	cur, err := os.Stat(l.path)
+	return err == nil && os.SameFile(fi, cur)
+}
+
+// release removes the lock file and releases the lock.
+//
+// The file is removed while the lock is held on unix, so no other process can lock the unlinked file after the
+// release. windows can't remove the open file, so it is removed after the release, and left as is if another
+// process has opened it in the meantime.
+func (l *FileLock) release() error {
+	if runtime.GOOS == "windows" {
+		err := l.Unlock()
+		os.Remove(l.path)
+		return err
+	}
+
+	rerr := os.Remove(l.path)
+	if err := l.Unlock(); err != nil {
+		return err
+	}
+	return rerr
+}
+
+// Path returns the lock file path.
+func (l *FileLock) Path() string {
+	return l.path
+}
+
+// Lock acquires the lock, blocking until it is available.
+func (l *FileLock) Lock() error {
+	_, err := l.lock(true)
+	return err
+}
+
+// TryLock tries to acquire the lock without blocking, and reports whether it succeeded.
+func (l *FileLock) TryLock() (bool, error) {
+	return l.lock(false)
+}
+
+// Unlock releases the lock.
+func (l *FileLock) Unlock() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.f == nil {
+		return errors.New("xdgbasedir: unlock of unlocked " + l.path)
+	}
+	err := unlockFile(l.f)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	l.f = nil
+	return err
+}
+
+// lock acquires the lock, and reports whether it succeeded.
+func (l *FileLock) lock(block bool) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.f != nil {
+		return false, errors.New("xdgbasedir: " + l.path + " is already locked")
+	}
+
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return false, err
+	}
+	ok, err := lockFile(f, block)
+	if !ok {
+		f.Close()
+		return false, err
+	}
+	l.f = f
+	return true, nil
+}
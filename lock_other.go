@@ -0,0 +1,26 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !darwin && !dragonfly && !freebsd && !illumos && !linux && !netbsd && !openbsd && !windows
+// +build !darwin,!dragonfly,!freebsd,!illumos,!linux,!netbsd,!openbsd,!windows
+
+package xdgbasedir
+
+import (
+	"errors"
+	"os"
+)
+
+// errLockUnsupported is returned when the file lock is not supported on the platform.
+var errLockUnsupported = errors.New("xdgbasedir: file lock is not supported")
+
+// lockFile locks the f exclusively, and reports whether it succeeded.
+func lockFile(f *os.File, block bool) (bool, error) {
+	return false, &os.PathError{Op: "lock", Path: f.Name(), Err: errLockUnsupported}
+}
+
+// unlockFile unlocks the f.
+func unlockFile(f *os.File) error {
+	return &os.PathError{Op: "unlock", Path: f.Name(), Err: errLockUnsupported}
+}
@@ -0,0 +1,264 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupLockFile sets the secure runtime directory and returns the FileLock of "myapp.lock".
+func setupLockFile(t *testing.T) *FileLock {
+	t.Helper()
+
+	runtimeDir := t.TempDir()
+	if err := os.Chmod(runtimeDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := app.LockFile("myapp.lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestApp_LockFile(t *testing.T) {
+	t.Run("runtime dir", func(t *testing.T) {
+		l := setupLockFile(t)
+		if want := filepath.Join(os.Getenv("XDG_RUNTIME_DIR"), "myapp", "myapp.lock"); l.Path() != want {
+			t.Errorf("Path() = %v, want %v", l.Path(), want)
+		}
+	})
+
+	t.Run("fallback to state dir", func(t *testing.T) {
+		stateHome := t.TempDir()
+		t.Setenv("XDG_RUNTIME_DIR", filepath.Join(t.TempDir(), "not-exist"))
+		t.Setenv("XDG_STATE_HOME", stateHome)
+
+		app, err := NewApp("myapp")
+		if err != nil {
+			t.Fatal(err)
+		}
+		l, err := app.LockFile("myapp.lock")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := filepath.Join(stateHome, "myapp", "myapp.lock"); l.Path() != want {
+			t.Errorf("Path() = %v, want %v", l.Path(), want)
+		}
+	})
+}
+
+func TestFileLock(t *testing.T) {
+	l1 := setupLockFile(t)
+	l2 := &FileLock{path: l1.Path()}
+
+	if err := l1.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := l1.Lock(); err == nil {
+		t.Error("Lock() of locked FileLock expected error")
+	}
+	if ok, err := l2.TryLock(); ok || err != nil {
+		t.Errorf("TryLock() of held lock = %v, %v, want false", ok, err)
+	}
+
+	if err := l1.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := l1.Unlock(); err == nil {
+		t.Error("Unlock() of unlocked FileLock expected error")
+	}
+	if ok, err := l2.TryLock(); !ok || err != nil {
+		t.Errorf("TryLock() of released lock = %v, %v, want true", ok, err)
+	}
+
+	// Lock blocks until the other releases it
+	done := make(chan error)
+	go func() { done <- l1.Lock() }()
+	select {
+	case err := <-done:
+		t.Fatalf("Lock() returned while held: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := l2.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if err := l1.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileLockMultiProcess(t *testing.T) {
+	l := setupLockFile(t)
+	counter := filepath.Join(filepath.Dir(l.Path()), "counter")
+	writeFileContent(t, counter, "0")
+
+	const (
+		procs = 4
+		loops = 20
+	)
+	cmds := make([]*exec.Cmd, procs)
+	for i := range cmds {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestFileLockHelperProcess$")
+		cmd.Env = append(os.Environ(), "XDGBASEDIR_WANT_HELPER_PROCESS=1", "XDGBASEDIR_LOOPS="+strconv.Itoa(loops))
+		cmd.Stderr = os.Stderr
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		cmds[i] = cmd
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// the read-modify-write of counter loses the increments without the mutual exclusion
+	if got, want := readString(t, counter), strconv.Itoa(procs*loops); got != want {
+		t.Errorf("counter = %v, want %v", got, want)
+	}
+}
+
+// TestFileLockHelperProcess is the helper process of TestFileLockMultiProcess. It is not a real test.
+func TestFileLockHelperProcess(t *testing.T) {
+	if os.Getenv("XDGBASEDIR_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	fail := func(err error) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	app, err := NewApp("myapp")
+	if err != nil {
+		fail(err)
+	}
+	l, err := app.LockFile("myapp.lock")
+	if err != nil {
+		fail(err)
+	}
+	counter := filepath.Join(filepath.Dir(l.Path()), "counter")
+
+	loops, _ := strconv.Atoi(os.Getenv("XDGBASEDIR_LOOPS"))
+	for i := 0; i < loops; i++ {
+		if err := l.Lock(); err != nil {
+			fail(err)
+		}
+		data, err := os.ReadFile(counter)
+		if err != nil {
+			fail(err)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil {
+			fail(err)
+		}
+		runtime.Gosched()
+		if err := os.WriteFile(counter, []byte(strconv.Itoa(n+1)), 0600); err != nil {
+			fail(err)
+		}
+		if err := l.Unlock(); err != nil {
+			fail(err)
+		}
+	}
+	os.Exit(0)
+}
+
+func TestLockFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("access mode is not supported on windows")
+	}
+	runtimeDir := filepath.Join(t.TempDir(), "runtime")
+	x := New(WithEnv(map[string]string{"XDG_RUNTIME_DIR": runtimeDir}))
+	path := filepath.Join(runtimeDir, "myapp.lock")
+
+	release, err := x.LockFile("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateRuntimeDir(runtimeDir); err != nil {
+		t.Errorf("runtime directory is not secure: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("lock file is not created: %v", err)
+	}
+
+	if _, err := x.LockFile("myapp.lock"); !errors.Is(err, ErrLocked) {
+		t.Errorf("LockFile() of held lock error = %v, want ErrLocked", err)
+	}
+
+	if err := release(); err != nil {
+		t.Fatal(err)
+	}
+	if err := release(); err != nil {
+		t.Errorf("second release() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("lock file is not removed: %v", err)
+	}
+
+	release, err = x.LockFile("myapp")
+	if err != nil {
+		t.Fatalf("LockFile() after release error = %v", err)
+	}
+	if err := release(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"", "..", "a/b"} {
+		if _, err := x.LockFile(name); err == nil {
+			t.Errorf("LockFile(%q) expected error", name)
+		}
+	}
+}
+
+func TestLockFileUnlinked(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the open file can't be removed on windows")
+	}
+	runtimeDir := filepath.Join(t.TempDir(), "runtime")
+	x := New(WithEnv(map[string]string{"XDG_RUNTIME_DIR": runtimeDir}))
+	release, err := x.LockFile("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the other process opens the lock file just before the release, and locks it after the release
+	path := filepath.Join(runtimeDir, "myapp.lock")
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := release(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := lockFile(f, false); !ok || err != nil {
+		t.Fatalf("lockFile() of the unlinked file = %v, %v, want true", ok, err)
+	}
+	l := &FileLock{path: path, f: f}
+	if l.isCurrent() {
+		t.Error("isCurrent() of the unlinked file = true")
+	}
+	if err := l.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// logDir is the sub directory name of the log files under $XDG_STATE_HOME/<app>.
+const logDir = "log"
+
+// LogDir returns the $XDG_STATE_HOME/<app>/log directory path, and creates it with 0700 if not exists.
+//
+// The logs are the state data by the XDG Base Directory Specification, so they are never removed by PurgeCache.
+func (a *App) LogDir() (string, error) {
+	return a.StateDir(logDir)
+}
+
+// LogFile returns the name file path under $XDG_STATE_HOME/<app>/log, and creates its parent directories with 0700.
+//
+// The path is suitable for handing to the rotating logger.
+func (a *App) LogFile(name string) (string, error) {
+	rel, err := cleanRel(name)
+	if err != nil {
+		return "", err
+	}
+	return a.StateFile(filepath.Join(logDir, rel))
+}
+
+// OpenLog opens the name file under $XDG_STATE_HOME/<app>/log for appending, and creates it with 0600 if not exists.
+func (a *App) OpenLog(name string) (*os.File, error) {
+	path, err := a.LogFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+}
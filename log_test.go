@@ -0,0 +1,84 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestApp_LogFile(t *testing.T) {
+	stateHome := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", stateHome)
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := app.LogDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(stateHome, "myapp", "log"); dir != want {
+		t.Errorf("LogDir() = %q, want %q", dir, want)
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		want    string
+		wantErr bool
+	}{
+		{name: "file", file: "myapp.log", want: filepath.Join(dir, "myapp.log")},
+		{name: "nested", file: "worker/1.log", want: filepath.Join(dir, "worker", "1.log")},
+		{name: "escape", file: "../history", wantErr: true},
+		{name: "empty", file: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := app.LogFile(tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LogFile(%q) error = %v, wantErr %v", tt.file, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("LogFile(%q) = %q, want %q", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApp_OpenLog(t *testing.T) {
+	stateHome := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", stateHome)
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{"first\n", "second\n"} {
+		f, err := app.OpenLog("myapp.log")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.WriteString(line); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := filepath.Join(stateHome, "myapp", "log", "myapp.log")
+	if s := readString(t, path); s != "first\nsecond\n" {
+		t.Errorf("content = %q, want appended lines", s)
+	}
+	if fi, err := os.Stat(path); err != nil || runtime.GOOS != "windows" && fi.Mode().Perm() != 0600 {
+		t.Errorf("%s is not created with 0600: %v", path, err)
+	}
+}
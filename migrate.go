@@ -0,0 +1,268 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MigrateBreadcrumb is the breadcrumb file name which MigrateFrom leaves in the legacy directory.
+const MigrateBreadcrumb = "MIGRATED_TO_XDG"
+
+// rename is the os.Rename function. It is a variable for testing.
+var rename = os.Rename
+
+// Migration represents a migrated or skipped entry of the legacy directory.
+type Migration struct {
+	// From is the path of the entry in the legacy directory.
+	From string
+	// To is the destination path under the XDG base directory.
+	To string
+	// Kind is the kind of destination base directory.
+	Kind Kind
+}
+
+// MigrateReport is the report of App.MigrateFrom.
+type MigrateReport struct {
+	// Migrated is the entries migrated to the XDG base directories.
+	Migrated []Migration
+	// Skipped is the entries skipped because the destination already exists.
+	Skipped []Migration
+}
+
+type migrateConfig struct {
+	mapping    map[string]Kind
+	copy       bool
+	symlink    bool
+	breadcrumb bool
+}
+
+// MigrateOption configures App.MigrateFrom.
+type MigrateOption func(*migrateConfig)
+
+// MigrateMapping returns the MigrateOption which maps the top-level entry name of the legacy directory to
+// the kind of destination base directory, such as {"cache": KindCacheHome, "history": KindStateHome}.
+//
+// The unmapped entries are migrated to the $XDG_CONFIG_HOME/<app>. The supported kinds are KindDataHome,
+// KindConfigHome, KindCacheHome and KindStateHome.
+func MigrateMapping(mapping map[string]Kind) MigrateOption {
+	return func(c *migrateConfig) {
+		c.mapping = mapping
+	}
+}
+
+// MigrateCopy returns the MigrateOption which copies the entries instead of moving them.
+func MigrateCopy() MigrateOption {
+	return func(c *migrateConfig) {
+		c.copy = true
+	}
+}
+
+// MigrateSymlink returns the MigrateOption which replaces the legacy directory with the symlink to
+// the $XDG_CONFIG_HOME/<app> after all entries are moved.
+func MigrateSymlink() MigrateOption {
+	return func(c *migrateConfig) {
+		c.symlink = true
+	}
+}
+
+// MigrateLeaveBreadcrumb returns the MigrateOption which leaves the MigrateBreadcrumb file in the legacy directory,
+// which describes where the entries are moved.
+func MigrateLeaveBreadcrumb() MigrateOption {
+	return func(c *migrateConfig) {
+		c.breadcrumb = true
+	}
+}
+
+// MigrateFrom migrates the entries of legacy dot-directory, such as ~/.myapp, to the XDG base directories.
+//
+// Each top-level entry of legacyDir is moved to the application directory of the base directory selected by
+// MigrateMapping, or copied if MigrateCopy is given. The cross-device rename is handled by copy and remove.
+//
+// MigrateFrom is idempotent. If legacyDir does not exist or is a symlink, it does nothing. If the destination
+// already exists, the entry is skipped and reported in MigrateReport.Skipped, so the files which the user
+// already edited are never clobbered.
+func (a *App) MigrateFrom(legacyDir string, opts ...MigrateOption) (*MigrateReport, error) {
+	var c migrateConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	report := new(MigrateReport)
+	fi, err := os.Lstat(legacyDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return report, nil
+		}
+		return nil, err
+	}
+	if fi.Mode()&os.ModeSymlink != 0 {
+		// already migrated and replaced with the symlink
+		return report, nil
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("xdgbasedir: legacy %s is not a directory", legacyDir)
+	}
+
+	entries, err := os.ReadDir(legacyDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == MigrateBreadcrumb {
+			continue
+		}
+
+		kind, ok := c.mapping[name]
+		if !ok {
+			kind = KindConfigHome
+		}
+		dir, err := a.migrateDir(kind)
+		if err != nil {
+			return report, err
+		}
+
+		m := Migration{From: filepath.Join(legacyDir, name), To: filepath.Join(dir, name), Kind: kind}
+		if _, err := os.Lstat(m.To); err == nil {
+			report.Skipped = append(report.Skipped, m)
+			continue
+		}
+		if err := migrate(m.From, m.To, c.copy); err != nil {
+			return report, err
+		}
+		report.Migrated = append(report.Migrated, m)
+	}
+
+	if c.copy {
+		return report, nil
+	}
+	if c.symlink {
+		// os.Remove only removes the empty directory, so the skipped entries are never lost
+		if err := os.Remove(legacyDir); err == nil {
+			return report, os.Symlink(a.ConfigHome(), legacyDir)
+		}
+	}
+	if c.breadcrumb && len(report.Migrated) > 0 {
+		if err := writeBreadcrumb(legacyDir, report); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// migrateDir returns the application directory of the kind base directory, and creates it.
+func (a *App) migrateDir(kind Kind) (string, error) {
+	switch kind {
+	case KindDataHome:
+		return a.DataDir("")
+	case KindConfigHome:
+		return a.ConfigDir("")
+	case KindCacheHome:
+		return a.CacheDir("")
+	case KindStateHome:
+		return a.StateDir("")
+	default:
+		return "", fmt.Errorf("xdgbasedir: unsupported migration kind %d", kind)
+	}
+}
+
+// migrate moves or copies the src to dst. The cross-device rename falls back to copy and remove.
+func migrate(src, dst string, copy bool) error {
+	if !copy {
+		err := rename(src, dst)
+		if err == nil || errCrossDevice == nil || !errors.Is(err, errCrossDevice) {
+			return err
+		}
+	}
+
+	if err := copyTree(src, dst); err != nil {
+		// do not leave the partial copy, the next run retries it
+		os.RemoveAll(dst)
+		return err
+	}
+	if copy {
+		return nil
+	}
+	return os.RemoveAll(src)
+}
+
+// copyTree copies the src file or directory tree to dst, preserving the permission and symlinks.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, fi.Mode().Perm())
+		case fi.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case fi.Mode().IsRegular():
+			return copyFile(path, target, fi.Mode().Perm())
+		default:
+			// skip the special files such as sockets and named pipes
+			return nil
+		}
+	})
+}
+
+// copyFile copies the src regular file to dst with perm.
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// writeBreadcrumb writes the MigrateBreadcrumb file which describes the migrated entries.
+func writeBreadcrumb(legacyDir string, report *MigrateReport) error {
+	var b strings.Builder
+	b.WriteString("This directory has been migrated to the XDG Base Directory.\n\n")
+	for _, m := range report.Migrated {
+		fmt.Fprintf(&b, "%s -> %s\n", filepath.Base(m.From), m.To)
+	}
+
+	f, err := os.OpenFile(filepath.Join(legacyDir, MigrateBreadcrumb), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, b.String()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
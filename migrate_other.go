@@ -0,0 +1,11 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !unix && !windows
+// +build !unix,!windows
+
+package xdgbasedir
+
+// errCrossDevice is nil because the platform does not report the rename across the filesystems.
+var errCrossDevice error
@@ -0,0 +1,209 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// setupMigrate creates the legacy directory and the XDG base directories, and returns the legacy directory and app.
+func setupMigrate(t *testing.T) (string, *App) {
+	t.Helper()
+
+	root := t.TempDir()
+	for env, dir := range map[string]string{
+		"XDG_CONFIG_HOME": "config",
+		"XDG_CACHE_HOME":  "cache",
+		"XDG_STATE_HOME":  "state",
+		"XDG_DATA_HOME":   "data",
+	} {
+		t.Setenv(env, filepath.Join(root, dir))
+	}
+
+	legacy := filepath.Join(root, ".myapp")
+	writeFileContent(t, filepath.Join(legacy, "config.toml"), "legacy config")
+	writeFileContent(t, filepath.Join(legacy, "cache", "objects", "ab"), "object")
+	writeFileContent(t, filepath.Join(legacy, "history"), "ls")
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return legacy, app
+}
+
+var migrateMapping = MigrateMapping(map[string]Kind{
+	"cache":   KindCacheHome,
+	"history": KindStateHome,
+})
+
+func readString(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestApp_MigrateFrom(t *testing.T) {
+	legacy, app := setupMigrate(t)
+
+	report, err := app.MigrateFrom(legacy, migrateMapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Migrated) != 3 || len(report.Skipped) != 0 {
+		t.Fatalf("MigrateFrom() = %+v, want 3 migrated", report)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "config", path: filepath.Join(app.ConfigHome(), "config.toml"), want: "legacy config"},
+		{name: "cache", path: filepath.Join(app.CacheHome(), "cache", "objects", "ab"), want: "object"},
+		{name: "state", path: filepath.Join(app.StateHome(), "history"), want: "ls"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := readString(t, tt.path); got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	entries, err := os.ReadDir(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("legacy directory has %d entries, want moved all", len(entries))
+	}
+}
+
+func TestApp_MigrateFromIdempotent(t *testing.T) {
+	legacy, app := setupMigrate(t)
+
+	if _, err := app.MigrateFrom(legacy, migrateMapping); err != nil {
+		t.Fatal(err)
+	}
+
+	// the user edits the migrated config, then the legacy one re-appears
+	target := filepath.Join(app.ConfigHome(), "config.toml")
+	writeFileContent(t, target, "edited")
+	writeFileContent(t, filepath.Join(legacy, "config.toml"), "stale")
+
+	report, err := app.MigrateFrom(legacy, migrateMapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Migrated) != 0 || len(report.Skipped) != 1 {
+		t.Fatalf("MigrateFrom() = %+v, want 1 skipped", report)
+	}
+	if got := readString(t, target); got != "edited" {
+		t.Errorf("migrated config = %q, want not clobbered", got)
+	}
+	if got := readString(t, filepath.Join(legacy, "config.toml")); got != "stale" {
+		t.Errorf("skipped legacy config = %q, want kept", got)
+	}
+
+	if report, err := app.MigrateFrom(filepath.Join(legacy, "not-exist")); err != nil || len(report.Migrated) != 0 {
+		t.Errorf("MigrateFrom(not exist) = %+v, %v", report, err)
+	}
+}
+
+func TestApp_MigrateFromCopy(t *testing.T) {
+	legacy, app := setupMigrate(t)
+
+	report, err := app.MigrateFrom(legacy, migrateMapping, MigrateCopy())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range report.Migrated {
+		if _, err := os.Stat(m.From); err != nil {
+			t.Errorf("copied source %s: %v", m.From, err)
+		}
+		if _, err := os.Stat(m.To); err != nil {
+			t.Errorf("copied destination %s: %v", m.To, err)
+		}
+	}
+}
+
+func TestApp_MigrateFromCrossDevice(t *testing.T) {
+	if errCrossDevice == nil {
+		t.Skipf("%s does not report the cross-device rename", runtime.GOOS)
+	}
+	legacy, app := setupMigrate(t)
+
+	rename = func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: errCrossDevice}
+	}
+	defer func() { rename = os.Rename }()
+
+	report, err := app.MigrateFrom(legacy, migrateMapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Migrated) != 3 {
+		t.Fatalf("MigrateFrom() = %+v, want 3 migrated", report)
+	}
+	if got := readString(t, filepath.Join(app.CacheHome(), "cache", "objects", "ab")); got != "object" {
+		t.Errorf("copied cache = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(legacy, "cache")); !os.IsNotExist(err) {
+		t.Errorf("legacy cache is not removed: %v", err)
+	}
+}
+
+func TestApp_MigrateFromLeave(t *testing.T) {
+	t.Run("breadcrumb", func(t *testing.T) {
+		legacy, app := setupMigrate(t)
+
+		if _, err := app.MigrateFrom(legacy, migrateMapping, MigrateLeaveBreadcrumb()); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(filepath.Join(legacy, MigrateBreadcrumb)); err != nil {
+			t.Fatal(err)
+		}
+
+		// the breadcrumb itself is never migrated
+		report, err := app.MigrateFrom(legacy, migrateMapping, MigrateLeaveBreadcrumb())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Migrated) != 0 || len(report.Skipped) != 0 {
+			t.Errorf("MigrateFrom() = %+v, want nothing", report)
+		}
+	})
+
+	t.Run("symlink", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("symlink requires the privilege on windows")
+		}
+		legacy, app := setupMigrate(t)
+
+		if _, err := app.MigrateFrom(legacy, migrateMapping, MigrateSymlink()); err != nil {
+			t.Fatal(err)
+		}
+		link, err := os.Readlink(legacy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if link != app.ConfigHome() {
+			t.Errorf("legacy symlink = %v, want %v", link, app.ConfigHome())
+		}
+
+		report, err := app.MigrateFrom(legacy, migrateMapping, MigrateSymlink())
+		if err != nil || len(report.Migrated)+len(report.Skipped) != 0 {
+			t.Errorf("MigrateFrom() = %+v, %v, want nothing", report, err)
+		}
+	})
+}
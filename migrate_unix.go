@@ -0,0 +1,13 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build unix
+// +build unix
+
+package xdgbasedir
+
+import "syscall"
+
+// errCrossDevice is the error of the rename across the filesystems.
+var errCrossDevice error = syscall.EXDEV
@@ -0,0 +1,13 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package xdgbasedir
+
+import "syscall"
+
+// errCrossDevice is the ERROR_NOT_SAME_DEVICE error of the rename across the volumes.
+var errCrossDevice error = syscall.Errno(17)
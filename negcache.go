@@ -0,0 +1,116 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithNegativeCache returns the Option which remembers the failed lookups of SearchDataFile, SearchConfigFile and
+// their App variants for ttl, so the repeated searches of the same nonexistent file skip probing every base directory.
+//
+// At most size failures are remembered, and the least recently used one is evicted. The cache key includes the
+// searched base directories, so the changed environment is never served from the stale entry. The file created
+// within ttl is not found until the entry expires or Invalidate is called. The cache is safe for concurrent use,
+// and is shared with the XDG cloned by With. For the correctness-critical lookup, bypass the cache per call by
+// x.With(WithNegativeCache(0, 0)), which disables it on the clone. The ttl or size less than or equal to zero
+// disables the cache.
+func WithNegativeCache(ttl time.Duration, size int) Option {
+	return func(x *XDG) {
+		x.negCache = nil
+		if ttl > 0 && size > 0 {
+			x.negCache = newNegCache(ttl, size)
+		}
+	}
+}
+
+// Invalidate forgets every failed lookup remembered by WithNegativeCache. It is no-op if the cache is disabled.
+func (x *XDG) Invalidate() {
+	if x.negCache != nil {
+		x.negCache.clear()
+	}
+}
+
+// negCache is the bounded LRU set of the failed lookups with the expiration.
+type negCache struct {
+	ttl  time.Duration
+	size int
+	now  func() time.Time
+
+	mu    sync.Mutex
+	order *list.List // of *negEntry, the front is the most recently used
+	items map[string]*list.Element
+}
+
+// negEntry is the failed lookup.
+type negEntry struct {
+	key     string
+	expires time.Time
+}
+
+// newNegCache returns the negCache which remembers at most size failures for ttl.
+func newNegCache(ttl time.Duration, size int) *negCache {
+	return &negCache{
+		ttl:   ttl,
+		size:  size,
+		now:   time.Now,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// negKey returns the cache key of the rel lookup in dirs.
+func negKey(dirs []string, rel string) string {
+	return strings.Join(dirs, "\x00") + "\x00\x00" + rel
+}
+
+// has reports whether the key is the remembered failure which is not expired yet.
+func (c *negCache) has(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	if !c.now().Before(e.Value.(*negEntry).expires) {
+		c.order.Remove(e)
+		delete(c.items, key)
+		return false
+	}
+	c.order.MoveToFront(e)
+	return true
+}
+
+// add remembers the failure of key, evicting the least recently used one if the cache is full.
+func (c *negCache) add(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := c.now().Add(c.ttl)
+	if e, ok := c.items[key]; ok {
+		e.Value.(*negEntry).expires = expires
+		c.order.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.order.PushFront(&negEntry{key: key, expires: expires})
+	for c.order.Len() > c.size {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.items, e.Value.(*negEntry).key)
+	}
+}
+
+// clear forgets every failure.
+func (c *negCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+}
@@ -0,0 +1,166 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// setupNegCache returns the XDG on mapfs with six data directories and the negative cache, and its probeFS.
+func setupNegCache(tb testing.TB, mapfs fstest.MapFS, ttl time.Duration, size int) (*XDG, *probeFS) {
+	tb.Helper()
+
+	root := string(filepath.Separator)
+	if runtime.GOOS == "windows" {
+		root = `C:\`
+	}
+	var dirs []string
+	for i := 0; i < 5; i++ {
+		dirs = append(dirs, filepath.Join(root, "usr", fmt.Sprintf("share%d", i)))
+	}
+	fsys := &probeFS{FS: mapfs}
+	x := New(WithFS(fsys), WithNegativeCache(ttl, size), WithLookupEnv(mapLookupEnv(map[string]string{
+		"XDG_DATA_HOME": filepath.Join(root, "home", ".local", "share"),
+		"XDG_DATA_DIRS": strings.Join(dirs, string(filepath.ListSeparator)),
+	})))
+	if len(x.DataDirsAll()) != 6 {
+		tb.Skip("the test environment is for absolute paths")
+	}
+	return x, fsys
+}
+
+func TestWithNegativeCache(t *testing.T) {
+	mapfs := fstest.MapFS{}
+	x, fsys := setupNegCache(t, mapfs, time.Minute, 2)
+	now := time.Now()
+	x.negCache.now = func() time.Time { return now }
+
+	probes := func(t *testing.T, rel string) int {
+		t.Helper()
+
+		fsys.probed = nil
+		_, err := x.SearchDataFile(rel)
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("SearchDataFile(%q) error = %v, want fs.ErrNotExist", rel, err)
+		}
+		var nf *NotFoundError
+		if !errors.As(err, &nf) || nf.Name != rel || len(nf.Paths) != 6 {
+			t.Errorf("SearchDataFile(%q) error = %#v, want *NotFoundError with 6 paths", rel, err)
+		}
+		return len(fsys.probed)
+	}
+
+	if n := probes(t, "snippets/go.json"); n != 6 {
+		t.Errorf("first miss probed %d times, want 6", n)
+	}
+	if n := probes(t, "snippets/go.json"); n != 0 {
+		t.Errorf("cached miss probed %d times, want 0", n)
+	}
+
+	// the file created within ttl is hidden until Invalidate
+	mapfs["usr/share3/snippets/go.json"] = &fstest.MapFile{}
+	if n := probes(t, "snippets/go.json"); n != 0 {
+		t.Errorf("cached miss probed %d times, want 0", n)
+	}
+	if _, err := x.With(WithNegativeCache(0, 0)).SearchDataFile("snippets/go.json"); err != nil {
+		t.Errorf("bypassed SearchDataFile() error = %v", err)
+	}
+	x.Invalidate()
+	if _, err := x.SearchDataFile("snippets/go.json"); err != nil {
+		t.Errorf("SearchDataFile() after Invalidate error = %v", err)
+	}
+	delete(mapfs, "usr/share3/snippets/go.json")
+
+	// expiration
+	probes(t, "a")
+	now = now.Add(time.Minute)
+	if n := probes(t, "a"); n != 6 {
+		t.Errorf("expired miss probed %d times, want 6", n)
+	}
+
+	// the least recently used b is evicted by c
+	probes(t, "b")
+	probes(t, "a")
+	probes(t, "c")
+	if n := probes(t, "a"); n != 0 {
+		t.Errorf("recently used miss probed %d times, want 0", n)
+	}
+	if n := probes(t, "b"); n != 6 {
+		t.Errorf("evicted miss probed %d times, want 6", n)
+	}
+}
+
+func TestWithNegativeCacheConcurrent(t *testing.T) {
+	x, _ := setupNegCache(t, fstest.MapFS{}, time.Minute, 8)
+	// probeFS is not safe for concurrent use
+	x = x.With(WithFS(&countFS{FS: fstest.MapFS{}}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if _, err := x.SearchDataFile(fmt.Sprintf("missing/%d", (i+j)%16)); err == nil {
+					t.Error("SearchDataFile() expected error")
+					return
+				}
+				if j%50 == 0 {
+					x.Invalidate()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// countFS is the fs.FS which counts the opens, and is safe for concurrent use.
+type countFS struct {
+	fs.FS
+	mu sync.Mutex
+	n  int
+}
+
+// Open implements fs.FS.
+func (c *countFS) Open(name string) (fs.File, error) {
+	c.mu.Lock()
+	c.n++
+	c.mu.Unlock()
+	return c.FS.Open(name)
+}
+
+func BenchmarkSearchDataFileMiss(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "NoCache"},
+		{name: "NegativeCache", opts: []Option{WithNegativeCache(time.Minute, 128)}},
+	}
+	for _, bb := range benchmarks {
+		b.Run(bb.name, func(b *testing.B) {
+			x, _ := setupNegCache(b, fstest.MapFS{}, 0, 0)
+			fsys := &countFS{FS: fstest.MapFS{}}
+			x = x.With(append([]Option{WithFS(fsys)}, bb.opts...)...)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := x.SearchDataFile("snippets/go.json"); err == nil {
+					b.Fatal("SearchDataFile() expected error")
+				}
+			}
+			b.ReportMetric(float64(fsys.n)/float64(b.N), "stats/op")
+		})
+	}
+}
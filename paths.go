@@ -0,0 +1,119 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Location is the resolved directory path and whether it currently exists.
+type Location struct {
+	Path   string `json:"path"`
+	Exists bool   `json:"exists"`
+}
+
+// AppPaths is the report of every resolved per-app location. See App.Paths.
+type AppPaths struct {
+	Name       string     `json:"name"`
+	DataHome   Location   `json:"dataHome"`
+	ConfigHome Location   `json:"configHome"`
+	CacheHome  Location   `json:"cacheHome"`
+	StateHome  Location   `json:"stateHome"`
+	RuntimeDir Location   `json:"runtimeDir"`
+	DataDirs   []Location `json:"dataDirs"`
+	ConfigDirs []Location `json:"configDirs"`
+}
+
+// Paths returns the report of every resolved per-app location, such as for the --paths subcommand.
+//
+// The DataDirs and ConfigDirs are the search lists in the preference order, which SearchDataFile and
+// SearchConfigFile use. The paths reflect the configuration of XDG and Mode, so they are exactly what the
+// other helpers use. Paths does not create any directory.
+func (a *App) Paths() *AppPaths {
+	return &AppPaths{
+		Name:       a.Name(),
+		DataHome:   a.location(a.DataHome()),
+		ConfigHome: a.location(a.ConfigHome()),
+		CacheHome:  a.location(a.CacheHome()),
+		StateHome:  a.location(a.StateHome()),
+		RuntimeDir: a.location(a.RuntimeDir()),
+		DataDirs:   a.locations(a.dirs(a.x.DataDirsAll())),
+		ConfigDirs: a.locations(a.dirs(a.x.ConfigDirsAll())),
+	}
+}
+
+// EnsureAll creates each per-app directory of data, config, cache and state with 0700, and returns the resolved
+// paths. It is useful on the first run, so the later failures are about the content, not the missing parents.
+//
+// The runtime directory is also created same as RuntimeFile if the secure runtime directory is available,
+// otherwise it is skipped gracefully. The failures are aggregated by errors.Join, so the caller learns about
+// every problem, not just the first.
+func (a *App) EnsureAll() (*AppPaths, error) {
+	var errs []error
+	for _, mkdir := range []func(string) (string, error){a.DataDir, a.ConfigDir, a.CacheDir, a.StateDir} {
+		if _, err := mkdir(""); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if _, err := a.ensureRuntimeDir(); err != nil {
+		var rerr *RuntimeDirError
+		if !errors.As(err, &rerr) {
+			errs = append(errs, err)
+		}
+	}
+	return a.Paths(), errors.Join(errs...)
+}
+
+// location returns the Location of the dir directory.
+func (a *App) location(dir string) Location {
+	fi, err := a.x.stat(dir)
+	return Location{Path: dir, Exists: err == nil && fi.IsDir()}
+}
+
+// locations returns the Location of each dirs.
+func (a *App) locations(dirs []string) []Location {
+	locs := make([]Location, len(dirs))
+	for i, dir := range dirs {
+		locs[i] = a.location(dir)
+	}
+	return locs
+}
+
+// String returns the terminal output of p, one location per line such as:
+//
+//	name         myapp
+//	data-home    /home/foo/.local/share/myapp
+//	config-home  /home/foo/.config/myapp (missing)
+//	...
+//	data-dir     /home/foo/.local/share/myapp
+//	data-dir     /usr/share/myapp (missing)
+//
+// The search lists are printed one entry per line with the same key.
+func (p *AppPaths) String() string {
+	var b strings.Builder
+	line := func(key string, loc Location) {
+		fmt.Fprintf(&b, "%-12s %s", key, loc.Path)
+		if !loc.Exists {
+			b.WriteString(" (missing)")
+		}
+		b.WriteByte('\n')
+	}
+
+	fmt.Fprintf(&b, "%-12s %s\n", "name", p.Name)
+	line("data-home", p.DataHome)
+	line("config-home", p.ConfigHome)
+	line("cache-home", p.CacheHome)
+	line("state-home", p.StateHome)
+	line("runtime-dir", p.RuntimeDir)
+	for _, loc := range p.DataDirs {
+		line("data-dir", loc)
+	}
+	for _, loc := range p.ConfigDirs {
+		line("config-dir", loc)
+	}
+	return b.String()
+}
@@ -0,0 +1,158 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"testing/fstest"
+)
+
+func TestApp_Paths(t *testing.T) {
+	x := New(
+		WithFS(fstest.MapFS{
+			"config/myapp/config.toml": {Data: []byte("home")},
+			"usr/share/myapp/icon.svg": {Data: []byte("share")},
+		}),
+		WithLookupEnv(mapLookupEnv(map[string]string{
+			"XDG_DATA_HOME":   "/data",
+			"XDG_CONFIG_HOME": "/config",
+			"XDG_CACHE_HOME":  "/cache",
+			"XDG_STATE_HOME":  "/state",
+			"XDG_RUNTIME_DIR": "/run",
+			"XDG_DATA_DIRS":   "/usr/local/share:/usr/share",
+			"XDG_CONFIG_DIRS": "/etc/xdg",
+		})),
+	)
+	if x.DataHome() != "/data" || len(x.DataDirsAll()) != 3 {
+		t.Skip("the test environment is for unix path separators")
+	}
+	app, err := x.App("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := app.Paths()
+	want := &AppPaths{
+		Name:       "myapp",
+		DataHome:   Location{Path: "/data/myapp"},
+		ConfigHome: Location{Path: "/config/myapp", Exists: true},
+		CacheHome:  Location{Path: "/cache/myapp"},
+		StateHome:  Location{Path: "/state/myapp"},
+		RuntimeDir: Location{Path: "/run/myapp"},
+		DataDirs: []Location{
+			{Path: "/data/myapp"},
+			{Path: "/usr/local/share/myapp"},
+			{Path: "/usr/share/myapp", Exists: true},
+		},
+		ConfigDirs: []Location{
+			{Path: "/config/myapp", Exists: true},
+			{Path: "/etc/xdg/myapp"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Paths() = %+v, want %+v", got, want)
+	}
+
+	wantString := `name         myapp
+data-home    /data/myapp (missing)
+config-home  /config/myapp
+cache-home   /cache/myapp (missing)
+state-home   /state/myapp (missing)
+runtime-dir  /run/myapp (missing)
+data-dir     /data/myapp (missing)
+data-dir     /usr/local/share/myapp (missing)
+data-dir     /usr/share/myapp
+config-dir   /config/myapp
+config-dir   /etc/xdg/myapp (missing)
+`
+	if s := got.String(); s != wantString {
+		t.Errorf("String() = %s, want %s", s, wantString)
+	}
+
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded AppPaths
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&decoded, want) {
+		t.Errorf("json round trip = %+v, want %+v", decoded, want)
+	}
+}
+
+func TestApp_EnsureAll(t *testing.T) {
+	root := t.TempDir()
+	for _, env := range []string{"XDG_DATA_HOME", "XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_STATE_HOME"} {
+		t.Setenv(env, filepath.Join(root, env))
+	}
+	t.Setenv("XDG_RUNTIME_DIR", filepath.Join(root, "not-exist"))
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("without runtime dir", func(t *testing.T) {
+		paths, err := app.EnsureAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, loc := range []Location{paths.DataHome, paths.ConfigHome, paths.CacheHome, paths.StateHome} {
+			if !loc.Exists {
+				t.Errorf("%s is not created", loc.Path)
+			}
+			if fi, err := os.Stat(loc.Path); err != nil || runtime.GOOS != "windows" && fi.Mode().Perm() != 0700 {
+				t.Errorf("%s is not created with 0700: %v", loc.Path, err)
+			}
+		}
+		if paths.RuntimeDir.Exists {
+			t.Errorf("RuntimeDir %s exists", paths.RuntimeDir.Path)
+		}
+	})
+
+	t.Run("secure runtime dir", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("access mode is not supported on windows")
+		}
+		runtimeDir := filepath.Join(root, "runtime")
+		if err := os.Mkdir(runtimeDir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+		paths, err := app.EnsureAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !paths.RuntimeDir.Exists {
+			t.Errorf("RuntimeDir %s is not created", paths.RuntimeDir.Path)
+		}
+	})
+
+	t.Run("aggregate errors", func(t *testing.T) {
+		// the regular files in place of the application directories
+		broken := t.TempDir()
+		for _, env := range []string{"XDG_DATA_HOME", "XDG_CACHE_HOME"} {
+			t.Setenv(env, filepath.Join(broken, env))
+			writeFileContent(t, filepath.Join(broken, env, "myapp"), "")
+		}
+
+		_, err := app.EnsureAll()
+		if err == nil {
+			t.Fatal("EnsureAll() expected error")
+		}
+		joined, ok := err.(interface{ Unwrap() []error })
+		if !ok || len(joined.Unwrap()) != 2 {
+			t.Errorf("EnsureAll() error = %v, want 2 aggregated errors", err)
+		}
+	})
+}
@@ -0,0 +1,274 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrAlreadyRunning is returned by PIDFile.Acquire when the other live process holds the pid file.
+var ErrAlreadyRunning = errors.New("xdgbasedir: already running")
+
+// commLen is the maximum length of /proc/<pid>/comm, which is TASK_COMM_LEN excluding the trailing NUL byte.
+const commLen = 15
+
+// PIDFile represents the pid file of the single-instance daemon in the runtime directory.
+type PIDFile struct {
+	// Comm is the expected process name of the recorded pid, such as "myappd".
+	// If not empty, the pid file is also considered stale when /proc/<pid>/comm does not match Comm,
+	// which detects the pid reused by the other program. It is only effective where /proc is available.
+	Comm string
+
+	path string
+}
+
+// PIDFile returns the PIDFile of name in $XDG_RUNTIME_DIR/<app>.
+//
+// The runtime directory is validated same as RuntimeFile.
+func (a *App) PIDFile(name string) (*PIDFile, error) {
+	path, err := a.RuntimeFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return &PIDFile{path: path}, nil
+}
+
+// Path returns the pid file path.
+func (p *PIDFile) Path() string {
+	return p.path
+}
+
+// Acquire creates the pid file which contains the current pid.
+//
+// The pid file is created atomically with its content, and Acquire and Release are serialized by the lock on the
+// sibling <path>.lock file, so the concurrent Acquire from the multiple processes results in exactly one winner.
+// If the pid file is held by the other live process, Acquire returns the error wraps ErrAlreadyRunning. The stale
+// pid file, whose recorded process is dead, is taken over. Acquire returns nil if the pid file is already held by
+// the current process. The lock file is left in place, because removing it would break the serialization.
+func (p *PIDFile) Acquire() error {
+	l, err := p.lock()
+	if err != nil {
+		return err
+	}
+	defer l.Unlock()
+
+	self := os.Getpid()
+	err = p.create(self)
+	if err == nil || !errors.Is(err, fs.ErrExist) {
+		return err
+	}
+
+	data, err := os.ReadFile(p.path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		pid, ok := parsePID(data)
+		if ok && pid == self {
+			return nil
+		}
+		if ok && processAlive(pid) && p.commMatches(pid) {
+			return fmt.Errorf("%w: %s is held by pid %d", ErrAlreadyRunning, p.path, pid)
+		}
+		// the stale pid file can't be replaced by the others while the lock is held
+		if err := os.Remove(p.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return p.create(self)
+}
+
+// Release removes the pid file only if it still contains the current pid.
+func (p *PIDFile) Release() error {
+	l, err := p.lock()
+	if err != nil {
+		return err
+	}
+	defer l.Unlock()
+	return p.release()
+}
+
+// release removes the pid file if it contains the current pid, without the lock.
+func (p *PIDFile) release() error {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if pid, ok := parsePID(data); !ok || pid != os.Getpid() {
+		return nil
+	}
+	return os.Remove(p.path)
+}
+
+// lock acquires the lock on the sibling <path>.lock file, blocking until it is available.
+func (p *PIDFile) lock() (*FileLock, error) {
+	l := &FileLock{path: p.path + ".lock"}
+	for {
+		if err := l.Lock(); err != nil {
+			return nil, err
+		}
+		// the lock file may be removed between our open and lock, such as by CleanRuntimeDir
+		if l.isCurrent() {
+			return l, nil
+		}
+		if err := l.Unlock(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// WritePIDFile writes the current pid to $XDG_RUNTIME_DIR/<name>.pid atomically, and returns its path, such as for
+// the service managers which read the pid of the daemon. The ".pid" extension is not appended if name already has it.
+//
+// The pid file is written to the temporary file first and renamed over the path, so the readers never see the
+// partial content. The existing pid file is overwritten regardless of its owner. Use App.PIDFile for the
+// single-instance daemon, which refuses the pid file held by the other live process. The runtime directory is
+// ensured same as EnsureRuntimeDir, and name must not contain the path separators.
+func WritePIDFile(name string) (path string, err error) {
+	return std.WritePIDFile(name)
+}
+
+// WritePIDFile writes the current pid to the pid file under the runtime directory.
+//
+// See the package level WritePIDFile function for details.
+func (x *XDG) WritePIDFile(name string) (path string, err error) {
+	path, err = x.pidFilePath(name)
+	if err != nil {
+		return "", err
+	}
+	if err := ensureRuntimeDir(filepath.Dir(path)); err != nil {
+		return "", err
+	}
+	w, err := createAtomic(path, 0600)
+	if err != nil {
+		return "", err
+	}
+	if err := writeAtomic(w, []byte(strconv.Itoa(os.Getpid())+"\n")); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// ReadPIDFile reads the pid from $XDG_RUNTIME_DIR/<name>.pid written by WritePIDFile.
+//
+// It returns the error which matches to fs.ErrNotExist if the pid file does not exist. The recorded process may
+// be already dead.
+func ReadPIDFile(name string) (int, error) {
+	return std.ReadPIDFile(name)
+}
+
+// ReadPIDFile reads the pid from the pid file under the runtime directory.
+//
+// See the package level ReadPIDFile function for details.
+func (x *XDG) ReadPIDFile(name string) (int, error) {
+	path, err := x.pidFilePath(name)
+	if err != nil {
+		return 0, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	pid, ok := parsePID(data)
+	if !ok {
+		return 0, fmt.Errorf("xdgbasedir: invalid pid file %s", path)
+	}
+	return pid, nil
+}
+
+// RemovePIDFile removes $XDG_RUNTIME_DIR/<name>.pid only if it still contains the current pid, so the pid file
+// rewritten by the new instance is left as is. It returns nil if the pid file does not exist.
+func RemovePIDFile(name string) error {
+	return std.RemovePIDFile(name)
+}
+
+// RemovePIDFile removes the pid file under the runtime directory.
+//
+// See the package level RemovePIDFile function for details.
+func (x *XDG) RemovePIDFile(name string) error {
+	path, err := x.pidFilePath(name)
+	if err != nil {
+		return err
+	}
+	// the pid file written by WritePIDFile is not guarded by the lock of PIDFile.Acquire
+	return (&PIDFile{path: path}).release()
+}
+
+// pidFilePath returns the path of the name pid file under the runtime directory.
+func (x *XDG) pidFilePath(name string) (string, error) {
+	if err := validBaseName("pid", name); err != nil {
+		return "", err
+	}
+	if filepath.Ext(name) != ".pid" {
+		name += ".pid"
+	}
+	dir := x.RuntimeDir()
+	if dir == "" {
+		return "", &RuntimeDirError{Dir: dir, Err: errors.New("empty path")}
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// create creates the pid file which contains pid.
+//
+// The content is written to the temporary file first, and then hard linked to the pid file path, which fails if
+// the path exists same as O_CREAT|O_EXCL. So the other processes never see the empty pid file.
+func (p *PIDFile) create(pid int) error {
+	f, err := os.CreateTemp(filepath.Dir(p.path), "."+filepath.Base(p.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	_, err = f.WriteString(strconv.Itoa(pid) + "\n")
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Link(tmp, p.path)
+}
+
+// commMatches reports whether the process name of pid matches to p.Comm.
+//
+// It reports true if p.Comm is empty or /proc/<pid>/comm is not available.
+func (p *PIDFile) commMatches(pid int) bool {
+	if p.Comm == "" {
+		return true
+	}
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/comm")
+	if err != nil {
+		return true
+	}
+
+	want := p.Comm
+	if len(want) > commLen {
+		want = want[:commLen]
+	}
+	return strings.TrimSpace(string(data)) == want
+}
+
+// parsePID parses the content of pid file.
+func parsePID(data []byte) (int, bool) {
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	return pid, true
+}
@@ -0,0 +1,316 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+// setupPIDFile sets the secure runtime directory and returns the PIDFile of "myapp.pid".
+func setupPIDFile(t *testing.T) *PIDFile {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("access mode is not supported on windows")
+	}
+	runtimeDir := t.TempDir()
+	if err := os.Chmod(runtimeDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := app.PIDFile("myapp.pid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestPIDFile(t *testing.T) {
+	// the pid which never be alive, it exceeds the pid_max of linux and the PID_MAX of BSDs
+	const deadPID = 1 << 30
+
+	tests := []struct {
+		name    string
+		content string
+		comm    string
+		wantErr error
+	}{
+		{
+			name: "not exist",
+		},
+		{
+			name:    "held by self",
+			content: strconv.Itoa(os.Getpid()),
+		},
+		{
+			name:    "stale",
+			content: strconv.Itoa(deadPID),
+		},
+		{
+			name:    "corrupted",
+			content: "garbage",
+		},
+		{
+			name:    "held by live process",
+			content: strconv.Itoa(os.Getppid()),
+			wantErr: ErrAlreadyRunning,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := setupPIDFile(t)
+			p.Comm = tt.comm
+			if tt.content != "" {
+				writeFileContent(t, p.Path(), tt.content+"\n")
+			}
+
+			err := p.Acquire()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Acquire() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if got := readString(t, p.Path()); got != tt.content+"\n" {
+					t.Errorf("pid file = %q, want kept %q", got, tt.content)
+				}
+				return
+			}
+
+			if got, want := readString(t, p.Path()), strconv.Itoa(os.Getpid())+"\n"; got != want {
+				t.Errorf("pid file = %q, want %q", got, want)
+			}
+			matches, _ := filepath.Glob(filepath.Join(filepath.Dir(p.Path()), ".*"))
+			if len(matches) != 0 {
+				t.Errorf("temporary files are left: %v", matches)
+			}
+			if err := p.Release(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(p.Path()); !os.IsNotExist(err) {
+				t.Errorf("pid file is not removed: %v", err)
+			}
+		})
+	}
+}
+
+func TestPIDFileComm(t *testing.T) {
+	if _, err := os.Stat("/proc/self/comm"); err != nil {
+		t.Skip("/proc is not available")
+	}
+
+	p := setupPIDFile(t)
+	p.Comm = "not-the-parent-process-name"
+	writeFileContent(t, p.Path(), strconv.Itoa(os.Getppid())+"\n")
+
+	// the live pid which is reused by the other program is stale
+	if err := p.Acquire(); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+}
+
+func TestPIDFileRelease(t *testing.T) {
+	p := setupPIDFile(t)
+
+	// the pid file taken over by the other process must not be removed
+	content := strconv.Itoa(os.Getppid()) + "\n"
+	writeFileContent(t, p.Path(), content)
+	if err := p.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readString(t, p.Path()); got != content {
+		t.Errorf("pid file = %q, want kept %q", got, content)
+	}
+}
+
+func TestPIDFileConcurrent(t *testing.T) {
+	p := setupPIDFile(t)
+
+	if winners := raceAcquire(t, 4); winners != 1 {
+		t.Errorf("%d processes acquired the pid file, want exactly one", winners)
+	}
+	if _, err := os.Stat(p.Path()); !os.IsNotExist(err) {
+		t.Errorf("pid file is not released: %v", err)
+	}
+}
+
+func TestPIDFileTakeOverConcurrent(t *testing.T) {
+	p := setupPIDFile(t)
+
+	for i := 0; i < 5; i++ {
+		// the pid of the dead process, which every contender tries to take over
+		if err := os.WriteFile(p.Path(), []byte(strconv.Itoa(math.MaxInt32)+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if winners := raceAcquire(t, 3); winners != 1 {
+			t.Fatalf("%d processes took over the stale pid file, want exactly one", winners)
+		}
+	}
+}
+
+// raceAcquire runs n helper processes which Acquire the pid file concurrently, and returns the number of winners.
+func raceAcquire(t *testing.T, n int) int {
+	t.Helper()
+
+	type helper struct {
+		cmd   *exec.Cmd
+		stdin io.Closer
+		out   *bufio.Reader
+	}
+	helpers := make([]helper, n)
+	for i := range helpers {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestPIDFileHelperProcess$")
+		cmd.Env = append(os.Environ(), "XDGBASEDIR_WANT_HELPER_PROCESS=1")
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		helpers[i] = helper{cmd: cmd, stdin: stdin, out: bufio.NewReader(stdout)}
+	}
+
+	// every helper holds the result until its stdin is closed
+	winners := 0
+	for _, h := range helpers {
+		line, err := h.out.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch line {
+		case "acquired\n":
+			winners++
+		case "busy\n":
+		default:
+			t.Errorf("helper process: %q", line)
+		}
+	}
+	for _, h := range helpers {
+		h.stdin.Close()
+		if err := h.cmd.Wait(); err != nil {
+			t.Error(err)
+		}
+	}
+	return winners
+}
+
+// TestPIDFileHelperProcess is the helper process of TestPIDFileConcurrent. It is not a real test.
+func TestPIDFileHelperProcess(t *testing.T) {
+	if os.Getenv("XDGBASEDIR_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	p, err := app.PIDFile("myapp.pid")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	switch err := p.Acquire(); {
+	case err == nil:
+		fmt.Println("acquired")
+	case errors.Is(err, ErrAlreadyRunning):
+		fmt.Println("busy")
+	default:
+		fmt.Println(err)
+	}
+	io.Copy(io.Discard, os.Stdin)
+
+	p.Release()
+	os.Exit(0)
+}
+
+func TestWritePIDFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("access mode is not supported on windows")
+	}
+	runtimeDir := filepath.Join(t.TempDir(), "runtime")
+	x := New(WithEnv(map[string]string{"XDG_RUNTIME_DIR": runtimeDir}))
+
+	if _, err := x.ReadPIDFile("myappd"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadPIDFile() before write error = %v, want fs.ErrNotExist", err)
+	}
+	if err := x.RemovePIDFile("myappd"); err != nil {
+		t.Errorf("RemovePIDFile() before write error = %v", err)
+	}
+
+	path, err := x.WritePIDFile("myappd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(runtimeDir, "myappd.pid"); path != want {
+		t.Errorf("WritePIDFile() = %q, want %q", path, want)
+	}
+	if err := validateRuntimeDir(runtimeDir); err != nil {
+		t.Errorf("runtime directory is not secure: %v", err)
+	}
+	// overwritten, and the extension is not doubled
+	if _, err := x.WritePIDFile("myappd.pid"); err != nil {
+		t.Fatal(err)
+	}
+	if pid, err := x.ReadPIDFile("myappd"); err != nil || pid != os.Getpid() {
+		t.Errorf("ReadPIDFile() = %d, %v, want %d", pid, err, os.Getpid())
+	}
+	if entries, _ := os.ReadDir(runtimeDir); len(entries) != 1 {
+		t.Errorf("runtime directory has %d entries, want only the pid file", len(entries))
+	}
+
+	// the pid file rewritten by the other instance is not removed
+	if err := os.WriteFile(path, []byte("1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.RemovePIDFile("myappd"); err != nil {
+		t.Fatal(err)
+	}
+	if pid, err := x.ReadPIDFile("myappd"); err != nil || pid != 1 {
+		t.Errorf("ReadPIDFile() after RemovePIDFile of other's = %d, %v, want 1", pid, err)
+	}
+
+	if _, err := x.WritePIDFile("myappd"); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.RemovePIDFile("myappd"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("pid file is not removed: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := x.ReadPIDFile("myappd"); err == nil {
+		t.Error("ReadPIDFile() of the invalid content expected error")
+	}
+	for _, name := range []string{"", "..", "a/b"} {
+		if _, err := x.WritePIDFile(name); err == nil {
+			t.Errorf("WritePIDFile(%q) expected error", name)
+		}
+	}
+}
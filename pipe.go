@@ -0,0 +1,35 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import "strings"
+
+// PipePath returns the local IPC endpoint for name, so the cross-platform IPC code can call the one function.
+// On windows, which has no runtime directory, it returns the named pipe path \\.\pipe\<name>. Elsewhere, it returns
+// the unix domain socket path $XDG_RUNTIME_DIR/<name>.sock same as SocketPath.
+//
+// The name is sanitized on all platforms, so both ends get the same endpoint: the characters other than the ASCII
+// letters, digits, '.', '-' and '_' are replaced with '_', and the empty name becomes "_". On windows, the name is
+// truncated to fit in the 256 characters limit of the pipe name.
+//
+// Unlike SocketPath, PipePath neither ensures RuntimeDir nor checks the socket path length. Call EnsureRuntimeDir
+// before listening on the unix domain socket.
+func PipePath(name string) string {
+	return std.PipePath(name)
+}
+
+// sanitizePipeName replaces the characters of name not allowed in the pipe name with '_'.
+func sanitizePipeName(name string) string {
+	if name == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
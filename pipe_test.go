@@ -0,0 +1,49 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPipePath(t *testing.T) {
+	runtimeDir := filepath.Join(t.TempDir(), "run")
+	x := New(WithEnv(map[string]string{"XDG_RUNTIME_DIR": runtimeDir}))
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "mydaemon", want: "mydaemon"},
+		{name: "mydaemon.sock", want: "mydaemon.sock"},
+		{name: "my daemon/ctl", want: "my_daemon_ctl"},
+		{name: `..\..\evil`, want: ".._.._evil"},
+		{name: "dæmon", want: "d_mon"},
+		{name: "", want: "_"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := `\\.\pipe\` + tt.want
+			if runtime.GOOS != "windows" {
+				if !strings.HasSuffix(tt.want, ".sock") {
+					tt.want += ".sock"
+				}
+				want = filepath.Join(runtimeDir, tt.want)
+			}
+			if got := x.PipePath(tt.name); got != want {
+				t.Errorf("PipePath(%q) = %q, want %q", tt.name, got, want)
+			}
+		})
+	}
+
+	if runtime.GOOS == "windows" {
+		if got := x.PipePath(strings.Repeat("a", 300)); len(got) != 256 {
+			t.Errorf("len(PipePath(long)) = %d, want 256", len(got))
+		}
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+// +build !windows
+
+package xdgbasedir
+
+import "path/filepath"
+
+// PipePath returns the unix domain socket path under the runtime directory.
+//
+// See the package level PipePath function for details.
+func (x *XDG) PipePath(name string) string {
+	name = sanitizePipeName(name)
+	if filepath.Ext(name) != ".sock" {
+		name += ".sock"
+	}
+	return filepath.Join(x.RuntimeDir(), name)
+}
@@ -0,0 +1,25 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package xdgbasedir
+
+// pipePrefix is the prefix of the local named pipe path.
+const pipePrefix = `\\.\pipe\`
+
+// maxPipePathLen is the maximum length of the named pipe path.
+const maxPipePathLen = 256
+
+// PipePath returns the named pipe path.
+//
+// See the package level PipePath function for details.
+func (x *XDG) PipePath(name string) string {
+	name = sanitizePipeName(name)
+	if max := maxPipePathLen - len(pipePrefix); len(name) > max {
+		name = name[:max]
+	}
+	return pipePrefix + name
+}
@@ -0,0 +1,139 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// RelativeToDataDirs reports which entry of DataDirsAll contains the absolute path, and the slash separated path
+// relative to it, such as "/usr/share", "applications/firefox.desktop". The ok is false if no entry contains path.
+//
+// The paths are compared after filepath.Clean, and if no entry contains path lexically, after resolving the symlinks
+// of path and each entry, so the home directory reached via the symlink is still recognized. The returned base is
+// always the entry of DataDirsAll as is. If the entries nest, such as "/usr/share" and "/usr/share/extra", the
+// longest one wins. The comparison is case-insensitive on darwin and windows, whose filesystems are usually so.
+// The rel is "." if path is the entry itself.
+func RelativeToDataDirs(path string) (base, rel string, ok bool) {
+	return std.RelativeToDataDirs(path)
+}
+
+// RelativeToDataDirs reports which data directory contains path.
+//
+// See the package level RelativeToDataDirs function for details.
+func (x *XDG) RelativeToDataDirs(path string) (base, rel string, ok bool) {
+	return x.relativeTo(x.DataDirsAll(), path)
+}
+
+// RelativeToConfigDirs reports which entry of ConfigDirsAll contains the absolute path, and the slash separated path
+// relative to it, such as for the "user config" or "system config" badge of the file.
+//
+// See RelativeToDataDirs for the semantics.
+func RelativeToConfigDirs(path string) (base, rel string, ok bool) {
+	return std.RelativeToConfigDirs(path)
+}
+
+// RelativeToConfigDirs reports which configuration directory contains path.
+//
+// See the package level RelativeToConfigDirs function for details.
+func (x *XDG) RelativeToConfigDirs(path string) (base, rel string, ok bool) {
+	return x.relativeTo(x.ConfigDirsAll(), path)
+}
+
+// relativeTo returns the longest entry of dirs which contains path, and path relative to it.
+func (x *XDG) relativeTo(dirs []string, path string) (string, string, bool) {
+	if !filepath.IsAbs(path) {
+		return "", "", false
+	}
+	path = filepath.Clean(path)
+	if i, rel := longestBase(dirs, path); i >= 0 {
+		return dirs[i], rel, true
+	}
+
+	// the filesystem of WithFS has no symlinks
+	if x.fsys != nil {
+		return "", "", false
+	}
+	resolved, err := evalSymlinks(path)
+	if err != nil {
+		return "", "", false
+	}
+	bases := make([]string, len(dirs))
+	for i, dir := range dirs {
+		bases[i] = dir
+		if r, err := evalSymlinks(dir); err == nil {
+			bases[i] = r
+		}
+	}
+	if i, rel := longestBase(bases, resolved); i >= 0 {
+		return dirs[i], rel, true
+	}
+	return "", "", false
+}
+
+// evalSymlinks resolves the symlinks of path. If path does not exist, its longest existing ancestor is resolved,
+// so the path of the file not yet created is still recognized.
+func evalSymlinks(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err == nil || !os.IsNotExist(err) {
+		return resolved, err
+	}
+	dir, file := filepath.Split(path)
+	dir = filepath.Clean(dir)
+	if dir == path {
+		return "", err
+	}
+	parent, err := evalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(parent, file), nil
+}
+
+// longestBase returns the index of the longest entry of bases which contains path, and the slash separated path
+// relative to it. The index is -1 if no entry contains path.
+func longestBase(bases []string, path string) (int, string) {
+	best, rel := -1, ""
+	for i, base := range bases {
+		if best >= 0 && len(base) <= len(bases[best]) {
+			continue
+		}
+		if r, ok := relPath(base, path); ok {
+			best, rel = i, r
+		}
+	}
+	return best, rel
+}
+
+// relPath returns path relative to base if base contains path.
+func relPath(base, path string) (string, bool) {
+	if len(path) < len(base) || !equalPath(path[:len(base)], base) {
+		return "", false
+	}
+	rest := path[len(base):]
+	switch {
+	case rest == "":
+		return ".", true
+	case strings.HasSuffix(base, string(filepath.Separator)):
+		// the root directory such as "/" or `C:\`
+	case rest[0] == filepath.Separator:
+		rest = rest[1:]
+	default:
+		// the sibling such as "/usr/share2" of "/usr/share"
+		return "", false
+	}
+	return filepath.ToSlash(rest), true
+}
+
+// equalPath reports whether the cleaned paths a and b are the same, case-insensitively on darwin and windows.
+func equalPath(a, b string) bool {
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
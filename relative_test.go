@@ -0,0 +1,134 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRelativeToDataDirs(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	real := filepath.Join(root, "real")
+	link := filepath.Join(root, "link")
+	if err := os.MkdirAll(filepath.Join(real, ".local", "share", "applications"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(real, link); err != nil {
+		t.Skipf("symlink is not supported: %v", err)
+	}
+	home := filepath.Join(link, ".local", "share")
+	system := filepath.Join(root, "usr", "share")
+	extra := filepath.Join(system, "extra")
+	t.Setenv("XDG_DATA_HOME", home)
+	t.Setenv("XDG_DATA_DIRS", strings.Join([]string{system, extra}, string(filepath.ListSeparator)))
+
+	tests := []struct {
+		name     string
+		path     string
+		wantBase string
+		wantRel  string
+		wantOK   bool
+	}{
+		{
+			name:     "home",
+			path:     filepath.Join(home, "applications", "editor.desktop"),
+			wantBase: home,
+			wantRel:  "applications/editor.desktop",
+			wantOK:   true,
+		},
+		{
+			name:     "home via resolved symlink",
+			path:     filepath.Join(real, ".local", "share", "applications", "editor.desktop"),
+			wantBase: home,
+			wantRel:  "applications/editor.desktop",
+			wantOK:   true,
+		},
+		{
+			name:     "not cleaned",
+			path:     filepath.Join(system, "icons") + string(filepath.Separator) + ".." + string(filepath.Separator) + "applications" + string(filepath.Separator) + "browser.desktop",
+			wantBase: system,
+			wantRel:  "applications/browser.desktop",
+			wantOK:   true,
+		},
+		{
+			name:     "longest base",
+			path:     filepath.Join(extra, "applications", "browser.desktop"),
+			wantBase: extra,
+			wantRel:  "applications/browser.desktop",
+			wantOK:   true,
+		},
+		{
+			name:     "base itself",
+			path:     system,
+			wantBase: system,
+			wantRel:  ".",
+			wantOK:   true,
+		},
+		{
+			name: "sibling",
+			path: system + "2",
+		},
+		{
+			name: "outside",
+			path: filepath.Join(root, "opt", "app.desktop"),
+		},
+		{
+			name: "relative",
+			path: filepath.Join("usr", "share", "app.desktop"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, rel, ok := RelativeToDataDirs(tt.path)
+			if base != tt.wantBase || rel != tt.wantRel || ok != tt.wantOK {
+				t.Errorf("RelativeToDataDirs(%q) = %q, %q, %v, want %q, %q, %v", tt.path, base, rel, ok, tt.wantBase, tt.wantRel, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRelativeToConfigDirs(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	real := filepath.Join(root, "real", ".config")
+	if err := os.MkdirAll(real, 0700); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(real, link); err != nil {
+		t.Skipf("symlink is not supported: %v", err)
+	}
+	etc := filepath.Join(root, "etc", "xdg")
+	// the home is the real directory, and the path is via the symlink
+	t.Setenv("XDG_CONFIG_HOME", real)
+	t.Setenv("XDG_CONFIG_DIRS", etc)
+
+	base, rel, ok := RelativeToConfigDirs(filepath.Join(link, "myapp", "config.toml"))
+	if base != real || rel != "myapp/config.toml" || !ok {
+		t.Errorf("RelativeToConfigDirs() = %q, %q, %v, want %q, %q, true", base, rel, ok, real, "myapp/config.toml")
+	}
+
+	upper := strings.ToUpper(filepath.Join(etc, "myapp", "config.toml"))
+	base, rel, ok = RelativeToConfigDirs(upper)
+	switch runtime.GOOS {
+	case "darwin", "windows":
+		if base != etc || !strings.EqualFold(rel, "myapp/config.toml") || !ok {
+			t.Errorf("RelativeToConfigDirs(%q) = %q, %q, %v, want %q, %q, true", upper, base, rel, ok, etc, "MYAPP/CONFIG.TOML")
+		}
+	default:
+		if ok {
+			t.Errorf("RelativeToConfigDirs(%q) = %q, %q, %v, want case-sensitive mismatch", upper, base, rel, ok)
+		}
+	}
+}
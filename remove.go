@@ -0,0 +1,139 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Removal is the per-app directory which is removed by App.RemoveAll.
+type Removal struct {
+	// Kind is the kind of base directory.
+	Kind Kind
+	// Path is the per-app directory path.
+	Path string
+	// Files is the number of files in Path, excluding the directories.
+	Files int
+	// Bytes is the total size of files in Path.
+	Bytes int64
+}
+
+// RemoveAll removes the per-app directories of kinds, such as for the "purge my data" command,
+// and returns what was removed.
+//
+// If no kind is given, only the cache directory is removed. The data, config and state directories require
+// the explicit opt-in, such as RemoveAll(KindCacheHome, KindStateHome). The supported kinds are KindDataHome,
+// KindConfigHome, KindCacheHome, KindStateHome, KindBinHome and KindRuntimeDir.
+//
+// RemoveAll refuses to operate if the base directory is not sane, such as XDG_CACHE_HOME=/ or the user home
+// directory itself. The symlinks are removed themselves and never followed. The nonexistent directories are skipped.
+// Use PreviewRemoveAll to list them without removing.
+func (a *App) RemoveAll(kinds ...Kind) ([]Removal, error) {
+	return a.removeAll(false, kinds)
+}
+
+// PreviewRemoveAll returns what RemoveAll would remove without removing it, which is suitable for the confirmation.
+func (a *App) PreviewRemoveAll(kinds ...Kind) ([]Removal, error) {
+	return a.removeAll(true, kinds)
+}
+
+// removeAll removes the per-app directories of kinds, or lists them if dryRun.
+func (a *App) removeAll(dryRun bool, kinds []Kind) ([]Removal, error) {
+	if len(kinds) == 0 {
+		kinds = []Kind{KindCacheHome}
+	}
+
+	// validate all kinds first, so nothing is removed on the invalid request
+	dirs := make([]string, len(kinds))
+	for i, kind := range kinds {
+		dir, err := a.removableDir(kind)
+		if err != nil {
+			return nil, err
+		}
+		dirs[i] = dir
+	}
+
+	var removals []Removal
+	for i, dir := range dirs {
+		r := Removal{Kind: kinds[i], Path: dir}
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			r.Files++
+			if fi, err := d.Info(); err == nil {
+				r.Bytes += fi.Size()
+			}
+			return nil
+		})
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return removals, err
+		}
+
+		if !dryRun {
+			if err := os.RemoveAll(dir); err != nil {
+				return removals, err
+			}
+		}
+		removals = append(removals, r)
+	}
+	return removals, nil
+}
+
+// removableDir returns the per-app directory of kind, after checking its base directory is sane to remove under.
+func (a *App) removableDir(kind Kind) (string, error) {
+	var base string
+	switch kind {
+	case KindDataHome:
+		base = a.x.DataHome()
+	case KindConfigHome:
+		base = a.x.ConfigHome()
+	case KindCacheHome:
+		base = a.x.CacheHome()
+	case KindStateHome:
+		base = a.x.StateHome()
+	case KindRuntimeDir:
+		base = a.x.RuntimeDir()
+	case KindBinHome:
+		base = a.x.BinHome()
+	default:
+		return "", fmt.Errorf("xdgbasedir: cannot remove %v", kind)
+	}
+
+	if !filepath.IsAbs(base) {
+		return "", fmt.Errorf("xdgbasedir: refusing to remove under %v %q: not absolute", kind, base)
+	}
+	base = filepath.Clean(base)
+	if base == filepath.VolumeName(base)+string(filepath.Separator) {
+		return "", fmt.Errorf("xdgbasedir: refusing to remove under %v %q: filesystem root", kind, base)
+	}
+	home := a.x.home()
+	if home != "" {
+		home = filepath.Clean(home)
+	}
+	if home != "" && base == home {
+		return "", fmt.Errorf("xdgbasedir: refusing to remove under %v %q: user home directory", kind, base)
+	}
+
+	dir := filepath.Join(base, a.path)
+	if rel, err := filepath.Rel(base, dir); err != nil || rel == "." || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("xdgbasedir: refusing to remove %q: not under %v %q", dir, kind, base)
+	}
+	// such as XDG_CACHE_HOME=/home, which makes the per-app directory of "gopher" the home of gopher
+	if _, ok := relPath(dir, home); home != "" && ok {
+		return "", fmt.Errorf("xdgbasedir: refusing to remove %q under %v %q: contains user home directory", dir, kind, base)
+	}
+	return dir, nil
+}
@@ -0,0 +1,180 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// setupRemove creates the per-app files of each base directory, and returns the root and app.
+func setupRemove(t *testing.T) (string, *App) {
+	t.Helper()
+
+	root := t.TempDir()
+	for _, env := range []string{"XDG_DATA_HOME", "XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_STATE_HOME"} {
+		t.Setenv(env, filepath.Join(root, env))
+		writeFileContent(t, filepath.Join(root, env, "myapp", "sub", "file"), "12345")
+		writeFileContent(t, filepath.Join(root, env, "other", "file"), "other")
+	}
+	t.Setenv("XDG_RUNTIME_DIR", filepath.Join(root, "not-exist"))
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root, app
+}
+
+func TestApp_RemoveAll(t *testing.T) {
+	tests := []struct {
+		name      string
+		kinds     []Kind
+		preview   bool
+		wantGone  []string
+		wantKinds []Kind
+	}{
+		{
+			name:      "default cache only",
+			wantGone:  []string{"XDG_CACHE_HOME"},
+			wantKinds: []Kind{KindCacheHome},
+		},
+		{
+			name:      "explicit opt-in",
+			kinds:     []Kind{KindConfigHome, KindStateHome},
+			wantGone:  []string{"XDG_CONFIG_HOME", "XDG_STATE_HOME"},
+			wantKinds: []Kind{KindConfigHome, KindStateHome},
+		},
+		{
+			name:      "skip nonexistent",
+			kinds:     []Kind{KindDataHome, KindRuntimeDir},
+			wantGone:  []string{"XDG_DATA_HOME"},
+			wantKinds: []Kind{KindDataHome},
+		},
+		{
+			name:      "preview",
+			kinds:     []Kind{KindCacheHome, KindDataHome},
+			preview:   true,
+			wantKinds: []Kind{KindCacheHome, KindDataHome},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, app := setupRemove(t)
+
+			remove := app.RemoveAll
+			if tt.preview {
+				remove = app.PreviewRemoveAll
+			}
+			removals, err := remove(tt.kinds...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(removals) != len(tt.wantKinds) {
+				t.Fatalf("removed %+v, want kinds %v", removals, tt.wantKinds)
+			}
+			for i, r := range removals {
+				if r.Kind != tt.wantKinds[i] || r.Files != 1 || r.Bytes != 5 {
+					t.Errorf("removals[%d] = %+v, want %v with 1 file 5 bytes", i, r, tt.wantKinds[i])
+				}
+			}
+
+			gone := make(map[string]bool)
+			for _, env := range tt.wantGone {
+				gone[env] = true
+			}
+			for _, env := range []string{"XDG_DATA_HOME", "XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_STATE_HOME"} {
+				_, err := os.Stat(filepath.Join(root, env, "myapp"))
+				if exist := err == nil; exist == gone[env] {
+					t.Errorf("%s/myapp exists = %v, want %v", env, exist, !gone[env])
+				}
+				if _, err := os.Stat(filepath.Join(root, env, "other", "file")); err != nil {
+					t.Errorf("the other app's file is removed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestApp_RemoveAllRefuse(t *testing.T) {
+	root := string(filepath.Separator)
+	if runtime.GOOS == "windows" {
+		root = `C:\`
+	}
+	home := filepath.Join(root, "home", "gopher")
+
+	tests := []struct {
+		name  string
+		app   string
+		env   map[string]string
+		kinds []Kind
+	}{
+		{name: "filesystem root", env: map[string]string{"XDG_CACHE_HOME": root}},
+		{name: "user home", env: map[string]string{"XDG_CACHE_HOME": home}},
+		{name: "app dir is user home", app: "gopher", env: map[string]string{"XDG_CACHE_HOME": filepath.Join(root, "home")}},
+		{
+			name: "app dir is ancestor of user home",
+			app:  "users",
+			env: map[string]string{
+				homeEnv():        filepath.Join(root, "srv", "users", "gopher"),
+				"XDG_STATE_HOME": filepath.Join(root, "srv"),
+			},
+			kinds: []Kind{KindCacheHome, KindStateHome},
+		},
+		{name: "relative", env: map[string]string{"XDG_CACHE_HOME": "cache"}},
+		{name: "search dirs", kinds: []Kind{KindDataDirs}},
+		{name: "unknown kind", kinds: []Kind{Kind(-1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := map[string]string{homeEnv(): home}
+			for k, v := range tt.env {
+				env[k] = v
+			}
+			name := tt.app
+			if name == "" {
+				name = "myapp"
+			}
+			app, err := New(WithLookupEnv(mapLookupEnv(env))).App(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := app.PreviewRemoveAll(tt.kinds...); err == nil {
+				t.Error("PreviewRemoveAll() expected error")
+			}
+		})
+	}
+}
+
+func TestApp_RemoveAllSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlink requires the privilege on windows")
+	}
+
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+	outside := t.TempDir()
+	writeFileContent(t, filepath.Join(outside, "precious"), "data")
+	if err := os.Symlink(outside, filepath.Join(cacheHome, "myapp")); err != nil {
+		t.Fatal(err)
+	}
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.RemoveAll(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Lstat(filepath.Join(cacheHome, "myapp")); !os.IsNotExist(err) {
+		t.Errorf("symlink is not removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "precious")); err != nil {
+		t.Errorf("file outside the tree is removed: %v", err)
+	}
+}
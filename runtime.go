@@ -0,0 +1,406 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrSocketPathTooLong is returned when the path exceeds the limit of unix domain socket address.
+var ErrSocketPathTooLong = errors.New("xdgbasedir: socket path too long")
+
+// ErrInsecureRuntimeDir is wrapped by the *RuntimeDirError when the runtime directory exists but other users can
+// access or replace it, such as its access mode is not 0700, it is owned by another user, or it is a symlink.
+//
+// The callers can refuse to start on this error via errors.Is, and fall back on the other errors such as the
+// missing directory. The wrapping message includes the observed access mode and owner.
+var ErrInsecureRuntimeDir = errors.New("xdgbasedir: insecure runtime directory")
+
+// RuntimeDirError is returned when no secure runtime directory is available.
+//
+// Callers can fall back to an abstract socket or the state directory on this error.
+type RuntimeDirError struct {
+	Dir string
+	Err error
+}
+
+// Error implements error.
+func (e *RuntimeDirError) Error() string {
+	return "xdgbasedir: runtime directory " + e.Dir + " is not available: " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *RuntimeDirError) Unwrap() error { return e.Err }
+
+// ValidateRuntimeDir validates RuntimeDir is the directory which is owned by the current user and its access mode
+// is exactly 0700, as the specification requires, such as before placing the sockets in it.
+//
+// The symlink is rejected. The returned error is the *RuntimeDirError which describes the problem, such as
+// the observed access mode. The access mode and owner checks are skipped on windows.
+func ValidateRuntimeDir() error {
+	return std.ValidateRuntimeDir()
+}
+
+// ValidateRuntimeDir validates the runtime directory.
+//
+// See the package level ValidateRuntimeDir function for details.
+func (x *XDG) ValidateRuntimeDir() error {
+	return validateRuntimeDir(x.RuntimeDir())
+}
+
+// RuntimeDirOrFallback returns $XDG_RUNTIME_DIR if it is set. Otherwise, it creates the replacement directory
+// os.TempDir()/xdg-runtime-<uid> with 0700 and returns it, emitting the warning, as the specification requires
+// instead of synthesizing /run/user/<uid> which may not exist.
+//
+// The warning is logged by the standard logger of the log package, or the logger injected by WithLogger.
+// The replacement directory is ensured same as EnsureRuntimeDir, so the existing one must be owned by the current
+// user and not be a symlink, because os.TempDir() is writable by other users; otherwise the *RuntimeDirError is
+// returned. The directory lives as long
+// as os.TempDir(), not the login session. On windows, the uid is always -1.
+func RuntimeDirOrFallback() (string, error) {
+	return std.RuntimeDirOrFallback()
+}
+
+// RuntimeDirOrFallback returns the runtime directory, or creates the replacement in os.TempDir().
+//
+// See the package level RuntimeDirOrFallback function for details.
+func (x *XDG) RuntimeDirOrFallback() (string, error) {
+	if dir := x.getenv(EnvRuntimeDir); dir != "" {
+		return x.expandUser(dir), nil
+	}
+
+	dir := filepath.Join(os.TempDir(), "xdg-runtime-"+strconv.Itoa(os.Getuid()))
+	// the existing directory may be created by another user, because os.TempDir() is shared
+	if err := ensureRuntimeDir(dir); err != nil {
+		return "", err
+	}
+	x.warnf("xdgbasedir: %s is not set, using %s instead", EnvRuntimeDir, dir)
+	return dir, nil
+}
+
+// EnsureRuntimeDir creates RuntimeDir with 0700 if it does not exist, and returns it, such as before placing
+// the sockets, pid files and lock files in it.
+//
+// Only RuntimeDir itself is created by os.Mkdir, never its parents, so the loose access mode of the parents is
+// never introduced. The existing directory owned by the current user is tightened to 0700. The symlink, the
+// non-directory and the directory owned by another user are rejected, because they can't be fixed safely.
+// The returned error is the *RuntimeDirError which describes the problem and the remedy. The access mode and
+// owner checks are skipped on windows.
+func EnsureRuntimeDir() (string, error) {
+	return std.EnsureRuntimeDir()
+}
+
+// EnsureRuntimeDir creates the runtime directory with 0700 if it does not exist.
+//
+// See the package level EnsureRuntimeDir function for details.
+func (x *XDG) EnsureRuntimeDir() (string, error) {
+	dir := x.RuntimeDir()
+	if err := ensureRuntimeDir(dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// CleanRuntimeDir removes the stale files directly under RuntimeDir whose modification time is older than maxAge,
+// such as the orphaned sockets and pid files left by the crashed daemons, and returns the number of removed files.
+//
+// The directories such as the per-app directories are skipped. The files in use are skipped too: the file locked
+// by another process such as by LockFile, the pid file such as by WritePIDFile whose process is still running, the
+// unix domain socket which still accepts the connection, and the file with the sticky bit, which the specification
+// exempts from the periodic clean-up. RuntimeDir is validated same
+// as ValidateRuntimeDir before removing anything, and the missing RuntimeDir has nothing to clean. The error of
+// the file doesn't stop the clean-up, and the first error is returned.
+func CleanRuntimeDir(maxAge time.Duration) (removed int, err error) {
+	return std.CleanRuntimeDir(maxAge)
+}
+
+// CleanRuntimeDir removes the stale files under the runtime directory.
+//
+// See the package level CleanRuntimeDir function for details.
+func (x *XDG) CleanRuntimeDir(maxAge time.Duration) (removed int, err error) {
+	dir := x.RuntimeDir()
+	if err := validateRuntimeDir(dir); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		ok, rerr := removeStale(path, cutoff)
+		if ok {
+			removed++
+		}
+		if rerr != nil && err == nil {
+			err = rerr
+		}
+	}
+	return removed, err
+}
+
+// removeStale removes the path if it is modified before cutoff and not in use, and reports whether it is removed.
+func removeStale(path string, cutoff time.Time) (bool, error) {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !fi.ModTime().Before(cutoff) || fi.Mode()&os.ModeSticky != 0 {
+		return false, nil
+	}
+
+	switch {
+	case fi.Mode()&os.ModeSocket != 0:
+		if socketAlive(path) {
+			return false, nil
+		}
+	case fi.Mode().IsRegular():
+		// reading is enough to lock, so the read-only file is cleaned too, and without O_CREATE the file removed in
+		// the meantime is not recreated and counted
+		f, err := os.OpenFile(path, os.O_RDONLY, 0)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return false, nil
+			}
+			return false, err
+		}
+		// the file is removed while holding the lock, so no process can lock it in the meantime
+		ok, err := lockFile(f, false)
+		if !ok {
+			f.Close()
+			return false, err
+		}
+		l := &FileLock{path: path, f: f}
+		if !l.isCurrent() {
+			// replaced after the stat
+			return false, l.Unlock()
+		}
+		// the pid file written by WritePIDFile is never locked, so its process tells whether it is in use
+		data, err := io.ReadAll(io.LimitReader(f, 32))
+		if err != nil {
+			l.Unlock()
+			return false, err
+		}
+		if pid, ok := parsePID(data); ok && processAlive(pid) {
+			return false, l.Unlock()
+		}
+		return true, l.release()
+	}
+
+	// the symlink and the named pipe can't be locked
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// socketAlive reports whether the unix domain socket of path accepts the connection.
+func socketAlive(path string) bool {
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		// the full backlog of the busy listener
+		var nerr net.Error
+		return errors.As(err, &nerr) && nerr.Timeout()
+	}
+	conn.Close()
+	return true
+}
+
+// SocketPath returns the unix domain socket path $XDG_RUNTIME_DIR/<name>.sock, such as for the control socket of
+// the daemon which is not scoped by the application directory. The ".sock" extension is not appended if name
+// already has it.
+//
+// SocketPath ensures RuntimeDir same as EnsureRuntimeDir, so the caller can listen on the path right away.
+// The name must not be empty or contain the path separators. If no secure runtime directory is available, such as
+// $XDG_RUNTIME_DIR is unset and /run/user/<uid> can't be created, it returns the *RuntimeDirError. If the path
+// exceeds the platform's sun_path limit, it returns the error wraps ErrSocketPathTooLong. Use App.SocketPath for
+// the per-app sockets.
+func SocketPath(name string) (string, error) {
+	return std.SocketPath(name)
+}
+
+// SocketPath returns the unix domain socket path under the runtime directory.
+//
+// See the package level SocketPath function for details.
+func (x *XDG) SocketPath(name string) (string, error) {
+	if err := validBaseName("socket", name); err != nil {
+		return "", err
+	}
+	if filepath.Ext(name) != ".sock" {
+		name += ".sock"
+	}
+
+	dir := x.RuntimeDir()
+	if err := ensureRuntimeDir(dir); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	if err := checkSocketPath(runtime.GOOS, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// RuntimeDirOwnedByUser reports whether RuntimeDir is owned by the current user. The runtime directory owned by
+// another user is the security red flag, because that user can replace the sockets in it.
+//
+// It returns the error if RuntimeDir can't be stat, and the symlink itself is checked. It always reports true on
+// windows, which has no uid based owner. ValidateRuntimeDir includes this check.
+func RuntimeDirOwnedByUser() (bool, error) {
+	return std.RuntimeDirOwnedByUser()
+}
+
+// RuntimeDirOwnedByUser reports whether the runtime directory is owned by the current user.
+//
+// See the package level RuntimeDirOwnedByUser function for details.
+func (x *XDG) RuntimeDirOwnedByUser() (bool, error) {
+	dir := x.RuntimeDir()
+	if dir == "" {
+		return false, &RuntimeDirError{Dir: dir, Err: errors.New("empty path")}
+	}
+	fi, err := os.Lstat(dir)
+	if err != nil {
+		return false, err
+	}
+	return checkOwner(fi) == nil, nil
+}
+
+// validateRuntimeDir validates the dir is the directory which owned by the current user and its access mode is 0700.
+func validateRuntimeDir(dir string) error {
+	if dir == "" {
+		return &RuntimeDirError{Dir: dir, Err: errors.New("empty path")}
+	}
+
+	fi, err := os.Lstat(dir)
+	if err != nil {
+		return &RuntimeDirError{Dir: dir, Err: err}
+	}
+	if err := checkSecureDir(fi); err != nil {
+		return &RuntimeDirError{Dir: dir, Err: err}
+	}
+	return nil
+}
+
+// validBaseName validates the name of what, such as "socket", is the single path element.
+func validBaseName(what, name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("xdgbasedir: invalid %s name %q", what, name)
+	}
+	return nil
+}
+
+// ensureRuntimeDir creates the dir with 0700 if not exists, tightens the access mode of the existing dir owned by
+// the current user to 0700, and validates it same as validateRuntimeDir.
+//
+// Only the dir itself is created, because its parent such as /run/user is owned by the system, and creating the
+// parents would give them the loose access mode.
+func ensureRuntimeDir(dir string) error {
+	if dir == "" {
+		return &RuntimeDirError{Dir: dir, Err: errors.New("empty path, set $" + EnvRuntimeDir)}
+	}
+
+	if err := os.Mkdir(dir, 0700); err != nil && !os.IsExist(err) {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = fmt.Errorf("%w; set $%s to the directory owned by the user with 0700", err, EnvRuntimeDir)
+		}
+		return &RuntimeDirError{Dir: dir, Err: err}
+	}
+
+	// the umask may drop the bits of the new dir, and the existing dir may be loose
+	if runtime.GOOS != "windows" {
+		fi, err := os.Lstat(dir)
+		if err != nil {
+			return &RuntimeDirError{Dir: dir, Err: err}
+		}
+		if fi.IsDir() && fi.Mode()&os.ModeSymlink == 0 && fi.Mode().Perm() != 0700 && checkOwner(fi) == nil {
+			if err := os.Chmod(dir, 0700); err != nil {
+				return &RuntimeDirError{Dir: dir, Err: err}
+			}
+		}
+	}
+	return validateRuntimeDir(dir)
+}
+
+// checkSecureDir checks the fi is not a symlink but the directory, which is owned by the current user and
+// only the user having read and write access to it. The insecure fi is reported by ErrInsecureRuntimeDir.
+//
+// The access mode check is skipped on windows.
+func checkSecureDir(fi os.FileInfo) error {
+	if fi.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("%w: is a symlink", ErrInsecureRuntimeDir)
+	}
+	if !fi.IsDir() {
+		return errors.New("not a directory")
+	}
+	if runtime.GOOS != "windows" && fi.Mode().Perm() != 0700 {
+		return fmt.Errorf("%w: access mode is %#o, want 0700, %s", ErrInsecureRuntimeDir, fi.Mode().Perm(), fileOwner(fi))
+	}
+	if err := checkOwner(fi); err != nil {
+		return fmt.Errorf("%w: %v", ErrInsecureRuntimeDir, err)
+	}
+	return nil
+}
+
+// mkdirSecure creates the dir with 0700 if not exists, and checks the existing dir is secure.
+//
+// mkdirSecure never follows the symlink of dir, so the caller should guarantee the parent of dir is secure.
+func mkdirSecure(dir string) error {
+	err := os.Mkdir(dir, 0700)
+	if err == nil || !os.IsExist(err) {
+		return err
+	}
+
+	fi, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if err := checkSecureDir(fi); err != nil {
+		return fmt.Errorf("xdgbasedir: %s %w", dir, err)
+	}
+	return nil
+}
+
+// checkSocketPath checks the path length fits in the sun_path of sockaddr_un on goos.
+func checkSocketPath(goos, path string) error {
+	if max := maxSocketPathLen(goos); len(path) > max {
+		return fmt.Errorf("%w: %s is %d bytes, limit is %d bytes on %s", ErrSocketPathTooLong, path, len(path), max, goos)
+	}
+	return nil
+}
+
+// maxSocketPathLen returns the maximum length of unix domain socket path on goos, excluding the trailing NUL byte.
+func maxSocketPathLen(goos string) int {
+	switch goos {
+	case "linux", "android", "solaris", "illumos", "windows":
+		return 108 - 1
+	default:
+		// darwin and BSDs
+		return 104 - 1
+	}
+}
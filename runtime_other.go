@@ -0,0 +1,46 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !unix && !windows
+// +build !unix,!windows
+
+package xdgbasedir
+
+import (
+	"errors"
+	"os"
+	"strconv"
+)
+
+// checkSafeParent checks the fi is the directory which other users can't replace its entries.
+//
+// The platform has no uid based owner, so the parent is never trusted.
+func checkSafeParent(fi os.FileInfo) error {
+	if fi.Mode()&os.ModeSymlink != 0 {
+		return errors.New("is a symlink")
+	}
+	if !fi.IsDir() {
+		return errors.New("not a directory")
+	}
+	return errors.New("owner unknown")
+}
+
+// checkOwner checks the fi is owned by the current user.
+//
+// The platform has no uid based owner, so any owner is accepted same as windows.
+func checkOwner(fi os.FileInfo) error {
+	return nil
+}
+
+// fileOwner returns the description of the owner of fi.
+func fileOwner(fi os.FileInfo) string {
+	return "owner unknown"
+}
+
+// processAlive reports whether the process of pid is alive.
+func processAlive(pid int) bool {
+	// plan9 lists the processes in /proc, and js and wasip1 have no other processes to find
+	_, err := os.Stat("/proc/" + strconv.Itoa(pid))
+	return err == nil
+}
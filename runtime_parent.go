@@ -0,0 +1,39 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+// +build !windows
+
+package xdgbasedir
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// RuntimeDirParentSafe reports whether the parent directory of RuntimeDir, such as /run/user, is safe to trust
+// the runtime directory in it. It catches the misconfigured mount, such as the tmpfs mounted with the loose mode.
+//
+// The parent is safe if it is the directory, not a symlink, owned by root or the current user, and either not
+// writable by the group and others, or has the sticky bit so other users can't rename or remove the runtime
+// directory. It returns the error if RuntimeDir is empty or its parent can't be stat.
+func RuntimeDirParentSafe() (bool, error) {
+	return std.RuntimeDirParentSafe()
+}
+
+// RuntimeDirParentSafe reports whether the parent directory of the runtime directory is safe.
+//
+// See the package level RuntimeDirParentSafe function for details.
+func (x *XDG) RuntimeDirParentSafe() (bool, error) {
+	dir := x.RuntimeDir()
+	if dir == "" {
+		return false, &RuntimeDirError{Dir: dir, Err: errors.New("empty path")}
+	}
+	fi, err := os.Lstat(filepath.Dir(filepath.Clean(dir)))
+	if err != nil {
+		return false, err
+	}
+	return checkSafeParent(fi) == nil, nil
+}
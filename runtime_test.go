@@ -0,0 +1,565 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestApp_RuntimeFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("access mode is not supported on windows")
+	}
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("secure runtime dir", func(t *testing.T) {
+		runtimeDir := t.TempDir()
+		if err := os.Chmod(runtimeDir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+		got, err := app.RuntimeFile("sock/control")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := filepath.Join(runtimeDir, "myapp", "sock", "control"); got != want {
+			t.Errorf("RuntimeFile() = %v, want %v", got, want)
+		}
+		fi, err := os.Stat(filepath.Join(runtimeDir, "myapp"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode().Perm() != 0700 {
+			t.Errorf("app directory mode = %#o, want 0700", fi.Mode().Perm())
+		}
+	})
+
+	t.Run("loose access mode", func(t *testing.T) {
+		runtimeDir := t.TempDir()
+		if err := os.Chmod(runtimeDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+		_, err := app.RuntimeFile("control")
+		var rerr *RuntimeDirError
+		if !errors.As(err, &rerr) {
+			t.Fatalf("RuntimeFile() error = %v, want *RuntimeDirError", err)
+		}
+		if rerr.Dir != runtimeDir {
+			t.Errorf("RuntimeDirError.Dir = %v, want %v", rerr.Dir, runtimeDir)
+		}
+	})
+
+	t.Run("not exist", func(t *testing.T) {
+		runtimeDir := filepath.Join(t.TempDir(), "not-exist")
+		t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+		_, err := app.RuntimeFile("control")
+		var rerr *RuntimeDirError
+		if !errors.As(err, &rerr) || !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("RuntimeFile() error = %v, want *RuntimeDirError wraps fs.ErrNotExist", err)
+		}
+	})
+
+	t.Run("app dir is symlink", func(t *testing.T) {
+		runtimeDir := t.TempDir()
+		if err := os.Chmod(runtimeDir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+		if err := os.Symlink(t.TempDir(), filepath.Join(runtimeDir, "myapp")); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := app.RuntimeFile("control"); err == nil {
+			t.Error("RuntimeFile() expected error for symlinked app directory")
+		}
+	})
+
+	t.Run("vendor qualified", func(t *testing.T) {
+		runtimeDir := t.TempDir()
+		if err := os.Chmod(runtimeDir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+		app, err := NewApp("acme", "widgetd")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := app.RuntimeFile("control")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := filepath.Join(runtimeDir, "acme", "widgetd", "control"); got != want {
+			t.Errorf("RuntimeFile() = %v, want %v", got, want)
+		}
+		for _, dir := range []string{filepath.Join(runtimeDir, "acme"), filepath.Join(runtimeDir, "acme", "widgetd")} {
+			if fi, err := os.Stat(dir); err != nil || fi.Mode().Perm() != 0700 {
+				t.Errorf("%s is not created with 0700: %v", dir, err)
+			}
+		}
+	})
+
+	t.Run("traversal", func(t *testing.T) {
+		runtimeDir := t.TempDir()
+		t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+		if _, err := app.RuntimeFile("../control"); err == nil {
+			t.Error("RuntimeFile() expected error for traversal path")
+		}
+	})
+}
+
+func TestApp_RuntimePath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("access mode is not supported on windows")
+	}
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runtimeDir := t.TempDir()
+	if err := os.Chmod(runtimeDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+	if _, err := app.RuntimePath("control.sock"); err != nil {
+		t.Errorf("RuntimePath() error = %v", err)
+	}
+	if _, err := app.RuntimePath(strings.Repeat("s", 110)); !errors.Is(err, ErrSocketPathTooLong) {
+		t.Errorf("RuntimePath() error = %v, want ErrSocketPathTooLong", err)
+	}
+}
+
+func TestApp_SocketPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("access mode is not supported on windows")
+	}
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the synthetic deep runtime dir which leaves 20 bytes for the socket name within the sun_path limit
+	root := t.TempDir()
+	pad := maxSocketPathLen(runtime.GOOS) - len(root) - len("/myapp/") - 21
+	if pad < 1 {
+		// such as /var/folders on darwin
+		t.Skipf("the temporary directory %s is too long for the sun_path limit", root)
+	}
+	runtimeDir := filepath.Join(root, strings.Repeat("d", pad))
+	if err := os.Mkdir(runtimeDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+	tests := []struct {
+		name    string
+		sock    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "append extension",
+			sock: "control",
+			want: filepath.Join(runtimeDir, "myapp", "control.sock"),
+		},
+		{
+			name: "has extension",
+			sock: "control.sock",
+			want: filepath.Join(runtimeDir, "myapp", "control.sock"),
+		},
+		{
+			name: "limit",
+			sock: strings.Repeat("s", 15),
+			want: filepath.Join(runtimeDir, "myapp", strings.Repeat("s", 15)+".sock"),
+		},
+		{
+			name:    "exceeded",
+			sock:    strings.Repeat("s", 16),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := app.SocketPath(tt.sock)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SocketPath(%q) error = %v, wantErr %v", tt.sock, err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrSocketPathTooLong) {
+				t.Errorf("SocketPath(%q) error = %v, want ErrSocketPathTooLong", tt.sock, err)
+			}
+			if got != tt.want {
+				t.Errorf("SocketPath(%q) = %v, want %v", tt.sock, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_checkSocketPath(t *testing.T) {
+	// synthetic long runtime dir such as "/run/user/1000/<deep>/myapp/"
+	dir := "/run/user/1000/" + strings.Repeat("d", 70) + "/myapp/"
+
+	tests := []struct {
+		name    string
+		goos    string
+		path    string
+		wantErr bool
+	}{
+		{
+			name: "linux short",
+			goos: "linux",
+			path: "/run/user/1000/myapp/control.sock",
+		},
+		{
+			name: "linux limit",
+			goos: "linux",
+			path: dir + strings.Repeat("s", 107-len(dir)),
+		},
+		{
+			name:    "linux exceeded",
+			goos:    "linux",
+			path:    dir + strings.Repeat("s", 108-len(dir)),
+			wantErr: true,
+		},
+		{
+			name: "darwin limit",
+			goos: "darwin",
+			path: dir + strings.Repeat("s", 103-len(dir)),
+		},
+		{
+			name:    "darwin exceeded",
+			goos:    "darwin",
+			path:    dir + strings.Repeat("s", 104-len(dir)),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSocketPath(tt.goos, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkSocketPath(%q, %d bytes) error = %v, wantErr %v", tt.goos, len(tt.path), err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrSocketPathTooLong) {
+				t.Errorf("checkSocketPath() error = %v, want ErrSocketPathTooLong", err)
+			}
+		})
+	}
+}
+
+func TestValidateRuntimeDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("access mode is not supported on windows")
+	}
+	root := t.TempDir()
+	mkdir := func(name string, perm os.FileMode) string {
+		dir := filepath.Join(root, name)
+		if err := os.Mkdir(dir, perm); err != nil {
+			t.Fatal(err)
+		}
+		// ignore the umask
+		if err := os.Chmod(dir, perm); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	secure := mkdir("secure", 0700)
+	file := filepath.Join(root, "file")
+	writeFile(t, file)
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(secure, link); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		dir      string
+		wantErr  string
+		insecure bool
+	}{
+		{name: "0700", dir: secure},
+		{name: "0755", dir: mkdir("open", 0755), wantErr: "access mode is 0755, want 0700, owned by uid", insecure: true},
+		{name: "0600", dir: mkdir("noexec", 0600), wantErr: "access mode is 0600, want 0700", insecure: true},
+		{name: "file", dir: file, wantErr: "not a directory"},
+		{name: "symlink", dir: link, wantErr: "is a symlink", insecure: true},
+		{name: "missing", dir: filepath.Join(root, "missing"), wantErr: "no such file or directory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_RUNTIME_DIR", tt.dir)
+			x := New(WithHome(root))
+
+			err := x.ValidateRuntimeDir()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateRuntimeDir() error = %v", err)
+				}
+				return
+			}
+			var rerr *RuntimeDirError
+			if !errors.As(err, &rerr) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateRuntimeDir() error = %v, want *RuntimeDirError containing %q", err, tt.wantErr)
+			}
+			if got := errors.Is(err, ErrInsecureRuntimeDir); got != tt.insecure {
+				t.Errorf("errors.Is(%v, ErrInsecureRuntimeDir) = %v, want %v", err, got, tt.insecure)
+			}
+		})
+	}
+}
+
+func TestRuntimeDirOwnedByUser(t *testing.T) {
+	root := t.TempDir()
+	owned := filepath.Join(root, "owned")
+	if err := os.Mkdir(owned, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("XDG_RUNTIME_DIR", owned)
+	got, err := RuntimeDirOwnedByUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got {
+		t.Error("RuntimeDirOwnedByUser() = false, want true")
+	}
+
+	t.Setenv("XDG_RUNTIME_DIR", filepath.Join(root, "missing"))
+	if _, err := RuntimeDirOwnedByUser(); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("RuntimeDirOwnedByUser() error = %v, want fs.ErrNotExist", err)
+	}
+
+	if runtime.GOOS == "windows" || os.Getuid() != 0 {
+		return
+	}
+	// only root can give the directory to another user
+	other := filepath.Join(root, "other")
+	if err := os.Mkdir(other, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chown(other, 65534, 65534); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_RUNTIME_DIR", other)
+	got, err = RuntimeDirOwnedByUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got {
+		t.Error("RuntimeDirOwnedByUser() = true for the directory of another user, want false")
+	}
+	if err := ValidateRuntimeDir(); !errors.Is(err, ErrInsecureRuntimeDir) || !strings.Contains(err.Error(), "owned by uid 65534") {
+		t.Errorf("ValidateRuntimeDir() error = %v, want ErrInsecureRuntimeDir with the owner", err)
+	}
+}
+
+func TestRuntimeDirOrFallback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("access mode is not supported on windows")
+	}
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	fallback := filepath.Join(tmp, "xdg-runtime-"+strconv.Itoa(os.Getuid()))
+
+	var buf bytes.Buffer
+	x := New(WithLogger(log.New(&buf, "", 0)), WithEnv(map[string]string{"XDG_RUNTIME_DIR": "/run/user/1000"}))
+	if got, err := x.RuntimeDirOrFallback(); err != nil || got != "/run/user/1000" {
+		t.Errorf("RuntimeDirOrFallback() = %q, %v, want %q", got, err, "/run/user/1000")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("RuntimeDirOrFallback() logged %q for the set variable", buf.String())
+	}
+
+	x = x.With(WithEnv(map[string]string{"XDG_RUNTIME_DIR": ""}))
+	for i := 0; i < 2; i++ {
+		buf.Reset()
+		got, err := x.RuntimeDirOrFallback()
+		if err != nil || got != fallback {
+			t.Fatalf("RuntimeDirOrFallback() = %q, %v, want %q", got, err, fallback)
+		}
+		if err := validateRuntimeDir(got); err != nil {
+			t.Errorf("fallback directory is not secure: %v", err)
+		}
+		if want := "XDG_RUNTIME_DIR is not set, using " + fallback; !strings.Contains(buf.String(), want) {
+			t.Errorf("RuntimeDirOrFallback() logged %q, want %q", buf.String(), want)
+		}
+	}
+
+	// the own replacement with the loose mode is tightened, but the symlink is never trusted
+	if err := os.Chmod(fallback, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := x.RuntimeDirOrFallback(); err != nil {
+		t.Fatalf("RuntimeDirOrFallback() with 0755 error = %v", err)
+	}
+	if err := validateRuntimeDir(fallback); err != nil {
+		t.Errorf("fallback directory is not tightened: %v", err)
+	}
+	var rerr *RuntimeDirError
+	if err := os.Remove(fallback); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(tmp, fallback); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := x.RuntimeDirOrFallback(); !errors.As(err, &rerr) {
+		t.Errorf("RuntimeDirOrFallback() with symlink error = %v, want *RuntimeDirError", err)
+	}
+}
+
+func TestSocketPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("access mode is not supported on windows")
+	}
+	root := t.TempDir()
+
+	tests := []struct {
+		name       string
+		runtimeDir string
+		sock       string
+		want       string
+		wantErr    string
+	}{
+		{
+			name:       "created",
+			runtimeDir: filepath.Join(root, "runtime"),
+			sock:       "control",
+			want:       filepath.Join(root, "runtime", "control.sock"),
+		},
+		{
+			name:       "has extension",
+			runtimeDir: filepath.Join(root, "runtime"),
+			sock:       "control.sock",
+			want:       filepath.Join(root, "runtime", "control.sock"),
+		},
+		{name: "separator", runtimeDir: filepath.Join(root, "runtime"), sock: "a/b", wantErr: "invalid socket name"},
+		{name: "backslash", runtimeDir: filepath.Join(root, "runtime"), sock: `a\b`, wantErr: "invalid socket name"},
+		{name: "dot dot", runtimeDir: filepath.Join(root, "runtime"), sock: "..", wantErr: "invalid socket name"},
+		{name: "empty", runtimeDir: filepath.Join(root, "runtime"), sock: "", wantErr: "invalid socket name"},
+		{
+			name:       "parent missing",
+			runtimeDir: filepath.Join(root, "missing", "runtime"),
+			sock:       "control",
+			wantErr:    "no such file or directory",
+		},
+		{
+			name:       "too long",
+			runtimeDir: filepath.Join(root, "runtime"),
+			sock:       strings.Repeat("s", 108),
+			wantErr:    ErrSocketPathTooLong.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := New(WithEnv(map[string]string{"XDG_RUNTIME_DIR": tt.runtimeDir}))
+			got, err := x.SocketPath(tt.sock)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("SocketPath(%q) error = %v, want %q", tt.sock, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("SocketPath(%q) = %q, %v, want %q", tt.sock, got, err, tt.want)
+			}
+			if err := validateRuntimeDir(tt.runtimeDir); err != nil {
+				t.Errorf("runtime directory is not secure: %v", err)
+			}
+		})
+	}
+
+	// the existing symlink is never followed
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(filepath.Join(root, "runtime"), link); err != nil {
+		t.Fatal(err)
+	}
+	var rerr *RuntimeDirError
+	if _, err := New(WithEnv(map[string]string{"XDG_RUNTIME_DIR": link})).SocketPath("control"); !errors.As(err, &rerr) {
+		t.Errorf("SocketPath() in symlink error = %v, want *RuntimeDirError", err)
+	}
+}
+
+func TestEnsureRuntimeDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("access mode is not supported on windows")
+	}
+	root := t.TempDir()
+	mkdir := func(name string, perm os.FileMode) string {
+		dir := filepath.Join(root, name)
+		if err := os.Mkdir(dir, perm); err != nil {
+			t.Fatal(err)
+		}
+		// ignore the umask
+		if err := os.Chmod(dir, perm); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	file := filepath.Join(root, "file")
+	writeFile(t, file)
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(mkdir("target", 0755), link); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		dir     string
+		wantErr string
+	}{
+		{name: "created", dir: filepath.Join(root, "new")},
+		{name: "0700", dir: mkdir("secure", 0700)},
+		{name: "0755 tightened", dir: mkdir("open", 0755)},
+		{name: "0500 tightened", dir: mkdir("readonly", 0500)},
+		{name: "no parents", dir: filepath.Join(root, "missing", "runtime"), wantErr: "set $XDG_RUNTIME_DIR"},
+		{name: "file", dir: file, wantErr: "not a directory"},
+		{name: "symlink", dir: link, wantErr: "is a symlink"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(WithEnv(map[string]string{"XDG_RUNTIME_DIR": tt.dir})).EnsureRuntimeDir()
+			if tt.wantErr != "" {
+				var rerr *RuntimeDirError
+				if !errors.As(err, &rerr) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("EnsureRuntimeDir() error = %v, want *RuntimeDirError with %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.dir {
+				t.Fatalf("EnsureRuntimeDir() = %q, %v, want %q", got, err, tt.dir)
+			}
+			if err := validateRuntimeDir(got); err != nil {
+				t.Errorf("runtime directory is not secure: %v", err)
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "missing")); !os.IsNotExist(err) {
+		t.Errorf("parent directory is created: %v", err)
+	}
+	if fi, err := os.Stat(filepath.Join(root, "target")); err != nil || fi.Mode().Perm() != 0755 {
+		t.Errorf("symlink target is modified: %v", err)
+	}
+	if err := ensureRuntimeDir(""); err == nil || !strings.Contains(err.Error(), "empty path") {
+		t.Errorf("ensureRuntimeDir(\"\") error = %v, want empty path", err)
+	}
+}
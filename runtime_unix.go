@@ -0,0 +1,64 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build unix
+// +build unix
+
+package xdgbasedir
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkSafeParent checks the fi is the directory which other users can't replace its entries.
+func checkSafeParent(fi os.FileInfo) error {
+	if fi.Mode()&os.ModeSymlink != 0 {
+		return errors.New("is a symlink")
+	}
+	if !fi.IsDir() {
+		return errors.New("not a directory")
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return errors.New("owner unknown")
+	}
+	if uid := os.Getuid(); st.Uid != 0 && int(st.Uid) != uid {
+		return fmt.Errorf("owned by uid %d, want 0 or %d", st.Uid, uid)
+	}
+	if fi.Mode().Perm()&0022 != 0 && fi.Mode()&os.ModeSticky == 0 {
+		return fmt.Errorf("access mode is %#o without the sticky bit", fi.Mode().Perm())
+	}
+	return nil
+}
+
+// checkOwner checks the fi is owned by the current user.
+func checkOwner(fi os.FileInfo) error {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	if uid := os.Getuid(); int(st.Uid) != uid {
+		return fmt.Errorf("owned by uid %d, want %d", st.Uid, uid)
+	}
+	return nil
+}
+
+// fileOwner returns the description of the owner of fi, such as "owned by uid 1000".
+func fileOwner(fi os.FileInfo) string {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "owner unknown"
+	}
+	return fmt.Sprintf("owned by uid %d", st.Uid)
+}
+
+// processAlive reports whether the process of pid is alive.
+func processAlive(pid int) bool {
+	// the signal 0 only checks the existence, EPERM means the process of the other user is alive
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
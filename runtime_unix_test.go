@@ -0,0 +1,160 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build unix
+// +build unix
+
+package xdgbasedir
+
+import (
+	"errors"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRuntimeDirParentSafe(t *testing.T) {
+	root := t.TempDir()
+	mkdir := func(name string, mode os.FileMode) string {
+		t.Helper()
+		dir := filepath.Join(root, name)
+		if err := os.Mkdir(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		// bypass the umask
+		if err := os.Chmod(dir, mode); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(mkdir("target", 0755), link); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		parent string
+		want   bool
+	}{
+		{name: "0755", parent: mkdir("user", 0755), want: true},
+		{name: "0777 sticky", parent: mkdir("tmp", 0777|os.ModeSticky), want: true},
+		{name: "0777", parent: mkdir("open", 0777)},
+		{name: "0775", parent: mkdir("group", 0775)},
+		{name: "symlink", parent: link},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := New(WithEnv(map[string]string{"XDG_RUNTIME_DIR": filepath.Join(tt.parent, "1000") + "/"}))
+			got, err := x.RuntimeDirParentSafe()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("RuntimeDirParentSafe() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	x := New(WithEnv(map[string]string{"XDG_RUNTIME_DIR": filepath.Join(root, "missing", "1000")}))
+	if _, err := x.RuntimeDirParentSafe(); !os.IsNotExist(err) {
+		t.Errorf("RuntimeDirParentSafe() of the missing parent error = %v, want not exist", err)
+	}
+}
+
+func TestCleanRuntimeDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "run")
+	if err := os.Mkdir(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	x := New(WithEnv(map[string]string{"XDG_RUNTIME_DIR": dir}))
+	old := time.Now().Add(-2 * time.Hour)
+	touch := func(name string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			writeFile(t, path)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	listen := func(name string) *net.UnixListener {
+		t.Helper()
+		ln, err := net.ListenUnix("unix", &net.UnixAddr{Name: filepath.Join(dir, name), Net: "unix"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { ln.Close() })
+		touch(name)
+		return ln
+	}
+
+	touch("stale.pid")
+	writeFile(t, filepath.Join(dir, "fresh.pid"))
+	// the pid files written by WritePIDFile are not locked
+	writeFileContent(t, filepath.Join(dir, "alive.pid"), strconv.Itoa(os.Getpid())+"\n")
+	touch("alive.pid")
+	writeFileContent(t, filepath.Join(dir, "dead.pid"), strconv.Itoa(math.MaxInt32)+"\n")
+	touch("dead.pid")
+	readonly := touch("readonly.lock")
+	if err := os.Chmod(readonly, 0400); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "myapp"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	touch("myapp")
+	l := &FileLock{path: touch("daemon.lock")}
+	if ok, err := l.TryLock(); !ok || err != nil {
+		t.Fatalf("TryLock() = %v, %v", ok, err)
+	}
+	t.Cleanup(func() { l.Unlock() })
+	listen("alive.sock")
+	orphan := listen("orphan.sock")
+	orphan.SetUnlinkOnClose(false)
+	orphan.Close()
+	sticky := touch("sticky")
+	if err := os.Chmod(sticky, 0600|os.ModeSticky); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := x.CleanRuntimeDir(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+	want := []string{"alive.pid", "alive.sock", "daemon.lock", "fresh.pid", "myapp", "sticky"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CleanRuntimeDir() left %v, want %v", got, want)
+	}
+	if removed != 4 {
+		t.Errorf("CleanRuntimeDir() = %d, want 4", removed)
+	}
+
+	// the missing runtime directory has nothing to clean, and the insecure one is refused
+	missing := New(WithEnv(map[string]string{"XDG_RUNTIME_DIR": filepath.Join(dir, "missing")}))
+	if removed, err := missing.CleanRuntimeDir(0); removed != 0 || err != nil {
+		t.Errorf("CleanRuntimeDir() of the missing dir = %d, %v, want 0, nil", removed, err)
+	}
+	if err := os.Chmod(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := x.CleanRuntimeDir(0); !errors.Is(err, ErrInsecureRuntimeDir) {
+		t.Errorf("CleanRuntimeDir() of the insecure dir error = %v, want ErrInsecureRuntimeDir", err)
+	}
+}
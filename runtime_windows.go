@@ -0,0 +1,50 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package xdgbasedir
+
+import (
+	"os"
+	"syscall"
+)
+
+const (
+	// processQueryLimitedInformation is the PROCESS_QUERY_LIMITED_INFORMATION access right.
+	processQueryLimitedInformation = 0x1000
+	// stillActive is the STILL_ACTIVE exit code of the running process.
+	stillActive = 259
+)
+
+// checkOwner checks the fi is owned by the current user.
+//
+// TODO(zchee): windows does not have the uid based owner. Should check the owner SID via security descriptor.
+func checkOwner(fi os.FileInfo) error {
+	return nil
+}
+
+// fileOwner returns the description of the owner of fi.
+//
+// TODO(zchee): same as checkOwner, should describe the owner SID.
+func fileOwner(fi os.FileInfo) string {
+	return "owner unknown"
+}
+
+// processAlive reports whether the process of pid is alive.
+func processAlive(pid int) bool {
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		// access denied means the process of the other user is alive
+		return err == syscall.ERROR_ACCESS_DENIED
+	}
+	defer syscall.CloseHandle(h)
+
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+		return true
+	}
+	return code == stillActive
+}
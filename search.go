@@ -0,0 +1,263 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// NotFoundError is returned when the file is not found in any of the searched directories.
+//
+// NotFoundError matches to fs.ErrNotExist via errors.Is.
+type NotFoundError struct {
+	// Name is the searched relative path. It is empty for FirstExisting.
+	Name string
+	// Paths is the candidate paths tried, in search order.
+	Paths []string
+}
+
+// Error implements error.
+func (e *NotFoundError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("xdgbasedir: none of [%s] exists", strings.Join(e.Paths, ", "))
+	}
+	return fmt.Sprintf("xdgbasedir: %s not found in [%s]", e.Name, strings.Join(e.Paths, ", "))
+}
+
+// Is reports whether the target is fs.ErrNotExist.
+func (e *NotFoundError) Is(target error) bool { return target == fs.ErrNotExist }
+
+// SearchDataFile searches the rel file in $XDG_DATA_HOME first, and then each entry of $XDG_DATA_DIRS in order,
+// and returns the first existing regular file path, such as SearchDataFile("mime/globs2") for the files shared
+// across applications. It follows symlinks.
+//
+// The data directories are normalized same as DataDirsAll. If the file is not found, SearchDataFile returns
+// the *NotFoundError which carries the list of candidate paths. Use App.SearchDataFile for the per-app files.
+func SearchDataFile(rel string) (string, error) {
+	return std.SearchDataFile(rel)
+}
+
+// SearchDataFile searches the rel file in the data directories.
+//
+// See the package level SearchDataFile function for details.
+func (x *XDG) SearchDataFile(rel string) (string, error) {
+	clean, err := cleanRel(rel)
+	if err != nil {
+		return "", err
+	}
+	return x.searchFile(x.DataDirsAll(), rel, clean)
+}
+
+// SearchConfigFile searches the rel file in $XDG_CONFIG_HOME first, and then each entry of $XDG_CONFIG_DIRS in order,
+// and returns the first existing regular file path, such as SearchConfigFile("mimeapps.list"). It follows symlinks.
+//
+// The configuration directories are normalized same as ConfigDirsAll. If the file is not found, SearchConfigFile
+// returns the *NotFoundError which carries the list of candidate paths. Use App.SearchConfigFile for the per-app files.
+func SearchConfigFile(rel string) (string, error) {
+	return std.SearchConfigFile(rel)
+}
+
+// SearchConfigFile searches the rel file in the configuration directories.
+//
+// See the package level SearchConfigFile function for details.
+func (x *XDG) SearchConfigFile(rel string) (string, error) {
+	clean, err := cleanRel(rel)
+	if err != nil {
+		return "", err
+	}
+	return x.searchFile(x.ConfigDirsAll(), rel, clean)
+}
+
+// SearchConfigFileExt searches the stem file with each of exts, such as SearchConfigFileExt("myapp/config",
+// []string{".toml", ".yaml", ".json"}), and returns the first existing regular file path and its extension.
+//
+// The base directories take precedence over the extensions: exts are tried in order within $XDG_CONFIG_HOME first,
+// and then within each entry of $XDG_CONFIG_DIRS, so any user file beats any system file, and "config.toml" beats
+// "config.yaml" within the same directory. If no file is found, SearchConfigFileExt returns the *NotFoundError which
+// carries every candidate path in the search order. It follows symlinks.
+func SearchConfigFileExt(stem string, exts []string) (path, ext string, err error) {
+	return std.SearchConfigFileExt(stem, exts)
+}
+
+// SearchConfigFileExt searches the stem file with each of exts in the configuration directories.
+//
+// See the package level SearchConfigFileExt function for details.
+func (x *XDG) SearchConfigFileExt(stem string, exts []string) (path, ext string, err error) {
+	return x.searchFileExt(x.ConfigDirsAll(), stem, exts)
+}
+
+// SearchDataFileExt searches the stem file with each of exts in $XDG_DATA_HOME first, and then each entry of
+// $XDG_DATA_DIRS in order.
+//
+// See SearchConfigFileExt for the semantics.
+func SearchDataFileExt(stem string, exts []string) (path, ext string, err error) {
+	return std.SearchDataFileExt(stem, exts)
+}
+
+// SearchDataFileExt searches the stem file with each of exts in the data directories.
+//
+// See the package level SearchDataFileExt function for details.
+func (x *XDG) SearchDataFileExt(stem string, exts []string) (path, ext string, err error) {
+	return x.searchFileExt(x.DataDirsAll(), stem, exts)
+}
+
+// SearchAllDataFiles returns all existing regular files of rel in $XDG_DATA_HOME and each entry of $XDG_DATA_DIRS,
+// such as every "applications/mimeinfo.cache" for assembling the shared database. It follows symlinks.
+//
+// The result is ordered most-important-first, and the same directory appeared twice in the environment is searched
+// once. SearchAllDataFiles returns the empty result without error if no file is found, so the error means rel is
+// not resolvable. Use App.FindDataFiles for the per-app files.
+func SearchAllDataFiles(rel string) ([]string, error) {
+	return std.SearchAllDataFiles(rel)
+}
+
+// SearchAllDataFiles returns all existing regular files of rel in the data directories.
+//
+// See the package level SearchAllDataFiles function for details.
+func (x *XDG) SearchAllDataFiles(rel string) ([]string, error) {
+	clean, err := cleanRel(rel)
+	if err != nil {
+		return nil, err
+	}
+	return x.findFiles(x.DataDirsAll(), clean), nil
+}
+
+// SearchAllConfigFiles returns all existing regular files of rel in $XDG_CONFIG_HOME and each entry of
+// $XDG_CONFIG_DIRS.
+//
+// See SearchAllDataFiles for the semantics.
+func SearchAllConfigFiles(rel string) ([]string, error) {
+	return std.SearchAllConfigFiles(rel)
+}
+
+// SearchAllConfigFiles returns all existing regular files of rel in the configuration directories.
+//
+// See the package level SearchAllConfigFiles function for details.
+func (x *XDG) SearchAllConfigFiles(rel string) ([]string, error) {
+	clean, err := cleanRel(rel)
+	if err != nil {
+		return nil, err
+	}
+	return x.findFiles(x.ConfigDirsAll(), clean), nil
+}
+
+// dirList returns the preference-ordered list of home followed by the dirs separated by filepath.ListSeparator.
+func dirList(home, dirs string) []string {
+	return normalizeDirs(dirEntries(home, dirs))
+}
+
+// dirEntries returns the raw entries of home followed by the dirs separated by filepath.ListSeparator.
+func dirEntries(home, dirs string) []string {
+	return append([]string{home}, filepath.SplitList(dirs)...)
+}
+
+// normalizeDirs normalizes the list of directories.
+//
+// It skips the empty and relative entries because the specification says all paths must be absolute,
+// and removes the duplicated entries keeping the first one.
+func normalizeDirs(dirs []string) []string {
+	return filterDirs(dirs, nil)
+}
+
+// filterDirs is normalizeDirs which calls visit for each entry of dirs in order, with the reason of the rejection
+// such as "relative", or the empty reason for the accepted one. The visit can be nil.
+func filterDirs(dirs []string, visit func(dir, rejected string)) []string {
+	if visit == nil {
+		visit = func(string, string) {}
+	}
+	list := make([]string, 0, len(dirs))
+	seen := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		switch {
+		case dir == "":
+			visit(dir, "empty")
+			continue
+		case !filepath.IsAbs(dir):
+			visit(dir, "relative")
+			continue
+		}
+		dir = filepath.Clean(dir)
+		if seen[dir] {
+			visit(dir, "duplicate")
+			continue
+		}
+		seen[dir] = true
+		visit(dir, "")
+		list = append(list, dir)
+	}
+	return list
+}
+
+// searchFileExt returns the first existing regular file of stem with each of exts in dirs, trying every ext within
+// a directory before the next directory.
+func (x *XDG) searchFileExt(dirs []string, stem string, exts []string) (string, string, error) {
+	if len(exts) == 0 {
+		return "", "", fmt.Errorf("xdgbasedir: no extension to search %q", stem)
+	}
+	clean := make([]string, len(exts))
+	for i, ext := range exts {
+		rel, err := cleanRel(stem + ext)
+		if err != nil {
+			return "", "", err
+		}
+		clean[i] = rel
+	}
+
+	c := existsConfig{require: requireFile}
+	paths := make([]string, 0, len(dirs)*len(exts))
+	for _, dir := range dirs {
+		for i, rel := range clean {
+			path := filepath.Join(dir, rel)
+			paths = append(paths, path)
+			if x.probe(path, &c) == nil {
+				return path, exts[i], nil
+			}
+		}
+	}
+	return "", "", &NotFoundError{Name: stem + "{" + strings.Join(exts, ",") + "}", Paths: paths}
+}
+
+// findFiles returns all existing regular files of rel in dirs, in the order of dirs. It follows symlinks.
+func (x *XDG) findFiles(dirs []string, rel string) []string {
+	c := existsConfig{require: requireFile}
+	var paths []string
+	for _, dir := range dirs {
+		path := filepath.Join(dir, rel)
+		if x.probe(path, &c) == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// searchFile returns the first existing regular file of rel in dirs. It follows symlinks.
+//
+// If rel is not found, searchFile returns the *NotFoundError which has the name. The opts are passed to
+// FirstExisting, such as WithProbe for Explain.
+func (x *XDG) searchFile(dirs []string, name, rel string, opts ...ExistsOption) (string, error) {
+	paths := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		paths = append(paths, filepath.Join(dir, rel))
+	}
+
+	var key string
+	if x.negCache != nil {
+		key = negKey(dirs, rel)
+		if x.negCache.has(key) {
+			return "", &NotFoundError{Name: name, Paths: paths}
+		}
+	}
+	path, err := x.FirstExisting(paths, append([]ExistsOption{RequireFile()}, opts...)...)
+	if err != nil {
+		err.(*NotFoundError).Name = name
+		if x.negCache != nil {
+			x.negCache.add(key)
+		}
+	}
+	return path, err
+}
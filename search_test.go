@@ -0,0 +1,1042 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// writeFile writes the empty file to path with creating its parent directories.
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(path), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_normalizeDirs(t *testing.T) {
+	abs := func(elem ...string) string {
+		return filepath.Join(append([]string{string(filepath.Separator)}, elem...)...)
+	}
+	if runtime.GOOS == "windows" {
+		abs = func(elem ...string) string {
+			return filepath.Join(append([]string{`C:\`}, elem...)...)
+		}
+	}
+
+	tests := []struct {
+		name string
+		dirs []string
+		want []string
+	}{
+		{
+			name: "as is",
+			dirs: []string{abs("usr", "local", "share"), abs("usr", "share")},
+			want: []string{abs("usr", "local", "share"), abs("usr", "share")},
+		},
+		{
+			name: "skip empty",
+			dirs: []string{"", abs("usr", "share"), ""},
+			want: []string{abs("usr", "share")},
+		},
+		{
+			name: "skip relative",
+			dirs: []string{filepath.Join("relative", "share"), abs("usr", "share")},
+			want: []string{abs("usr", "share")},
+		},
+		{
+			name: "remove duplicates",
+			dirs: []string{abs("usr", "share"), abs("opt", "share"), abs("usr", "share") + string(filepath.Separator)},
+			want: []string{abs("usr", "share"), abs("opt", "share")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeDirs(tt.dirs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("normalizeDirs(%v) = %v, want %v", tt.dirs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApp_SearchDataFile(t *testing.T) {
+	root := t.TempDir()
+	dataHome := filepath.Join(root, "home", ".local", "share")
+	dataDirs := []string{filepath.Join(root, "usr", "local", "share"), filepath.Join(root, "usr", "share")}
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv("XDG_DATA_DIRS", strings.Join(dataDirs, string(filepath.ListSeparator)))
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, filepath.Join(dataHome, "myapp", "shadowed.txt"))
+	writeFile(t, filepath.Join(dataDirs[1], "myapp", "shadowed.txt"))
+	writeFile(t, filepath.Join(dataDirs[0], "myapp", "local.txt"))
+	writeFile(t, filepath.Join(dataDirs[1], "myapp", "local.txt"))
+	writeFile(t, filepath.Join(dataDirs[1], "myapp", "system.txt"))
+	writeFile(t, filepath.Join(dataDirs[0], "myapp", "dir.txt", "file"))
+	writeFile(t, filepath.Join(dataDirs[1], "myapp", "dir.txt"))
+	writeFile(t, filepath.Join(dataDirs[1], "other", "other.txt"))
+
+	tests := []struct {
+		name    string
+		rel     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "home shadows system",
+			rel:  "shadowed.txt",
+			want: filepath.Join(dataHome, "myapp", "shadowed.txt"),
+		},
+		{
+			name: "local system shadows system",
+			rel:  "local.txt",
+			want: filepath.Join(dataDirs[0], "myapp", "local.txt"),
+		},
+		{
+			name: "system only",
+			rel:  "system.txt",
+			want: filepath.Join(dataDirs[1], "myapp", "system.txt"),
+		},
+		{
+			name: "skip directory",
+			rel:  "dir.txt",
+			want: filepath.Join(dataDirs[1], "myapp", "dir.txt"),
+		},
+		{
+			name:    "other application",
+			rel:     "other.txt",
+			wantErr: true,
+		},
+		{
+			name:    "traversal",
+			rel:     "../other/other.txt",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := app.SearchDataFile(tt.rel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SearchDataFile(%q) error = %v, wantErr %v", tt.rel, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SearchDataFile(%q) = %v, want %v", tt.rel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApp_SearchDataFileNotFound(t *testing.T) {
+	root := t.TempDir()
+	dataHome := filepath.Join(root, "home")
+	dataDirs := []string{filepath.Join(root, "usr", "local", "share"), filepath.Join(root, "usr", "share")}
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv("XDG_DATA_DIRS", strings.Join(dataDirs, string(filepath.ListSeparator)))
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = app.SearchDataFile("missing.txt")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("SearchDataFile() error = %v, want fs.ErrNotExist", err)
+	}
+	var nerr *NotFoundError
+	if !errors.As(err, &nerr) {
+		t.Fatalf("SearchDataFile() error = %T, want *NotFoundError", err)
+	}
+	want := []string{
+		filepath.Join(dataHome, "myapp", "missing.txt"),
+		filepath.Join(dataDirs[0], "myapp", "missing.txt"),
+		filepath.Join(dataDirs[1], "myapp", "missing.txt"),
+	}
+	if !reflect.DeepEqual(nerr.Paths, want) {
+		t.Errorf("NotFoundError.Paths = %v, want %v", nerr.Paths, want)
+	}
+}
+
+func TestSearchDataFile(t *testing.T) {
+	root := t.TempDir()
+	dataHome := filepath.Join(root, "home")
+	dataDirs := []string{filepath.Join(root, "usr", "local", "share"), filepath.Join(root, "usr", "share")}
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	// the empty, relative and duplicated entries are normalized
+	t.Setenv("XDG_DATA_DIRS", strings.Join([]string{"", dataDirs[0], "relative", dataDirs[1], dataDirs[0]}, string(filepath.ListSeparator)))
+
+	writeFile(t, filepath.Join(dataHome, "mime", "globs2"))
+	writeFile(t, filepath.Join(dataDirs[1], "mime", "globs2"))
+	writeFile(t, filepath.Join(dataDirs[1], "icons", "index.theme"))
+
+	tests := []struct {
+		name      string
+		rel       string
+		want      string
+		wantPaths []string
+	}{
+		{
+			name: "home shadows system",
+			rel:  "mime/globs2",
+			want: filepath.Join(dataHome, "mime", "globs2"),
+		},
+		{
+			name: "system only",
+			rel:  "icons/index.theme",
+			want: filepath.Join(dataDirs[1], "icons", "index.theme"),
+		},
+		{
+			name: "not found",
+			rel:  "missing",
+			wantPaths: []string{
+				filepath.Join(dataHome, "missing"),
+				filepath.Join(dataDirs[0], "missing"),
+				filepath.Join(dataDirs[1], "missing"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SearchDataFile(tt.rel)
+			if tt.wantPaths != nil {
+				var nerr *NotFoundError
+				if !errors.As(err, &nerr) || !reflect.DeepEqual(nerr.Paths, tt.wantPaths) {
+					t.Fatalf("SearchDataFile(%q) error = %v, want NotFoundError with %v", tt.rel, err, tt.wantPaths)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("SearchDataFile(%q) = %v, want %v", tt.rel, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := SearchDataFile("../etc/passwd"); err == nil || errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("SearchDataFile() traversal error = %v, want invalid path", err)
+	}
+}
+
+func TestApp_SearchConfigFile(t *testing.T) {
+	root := t.TempDir()
+	configHome := filepath.Join(root, "home", ".config")
+	etc := filepath.Join(root, "etc", "xdg")
+	opt := filepath.Join(root, "opt", "xdg")
+
+	writeFile(t, filepath.Join(configHome, "myapp", "config.toml"))
+	writeFile(t, filepath.Join(etc, "myapp", "config.toml"))
+	writeFile(t, filepath.Join(etc, "myapp", "system.toml"))
+	writeFile(t, filepath.Join(opt, "myapp", "system.toml"))
+	writeFile(t, filepath.Join(opt, "myapp", "opt.toml"))
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	join := func(dirs ...string) string { return strings.Join(dirs, string(filepath.ListSeparator)) }
+	tests := []struct {
+		name       string
+		configHome string
+		configDirs string
+		rel        string
+		want       string
+		wantPaths  []string
+	}{
+		{
+			name:       "home shadows system",
+			configHome: configHome,
+			configDirs: join(etc, opt),
+			rel:        "config.toml",
+			want:       filepath.Join(configHome, "myapp", "config.toml"),
+		},
+		{
+			name:       "first system dir wins",
+			configHome: configHome,
+			configDirs: join(etc, opt),
+			rel:        "system.toml",
+			want:       filepath.Join(etc, "myapp", "system.toml"),
+		},
+		{
+			name:       "order of config dirs",
+			configHome: configHome,
+			configDirs: join(opt, etc),
+			rel:        "system.toml",
+			want:       filepath.Join(opt, "myapp", "system.toml"),
+		},
+		{
+			name:       "empty entries",
+			configHome: configHome,
+			configDirs: join("", etc, "", opt, ""),
+			rel:        "opt.toml",
+			want:       filepath.Join(opt, "myapp", "opt.toml"),
+		},
+		{
+			name:       "relative entries are ignored",
+			configHome: configHome,
+			configDirs: join("relative", etc),
+			rel:        "relative.toml",
+			wantPaths: []string{
+				filepath.Join(configHome, "myapp", "relative.toml"),
+				filepath.Join(etc, "myapp", "relative.toml"),
+			},
+		},
+		{
+			name:       "duplicated entries",
+			configHome: configHome,
+			configDirs: join(etc, etc, configHome),
+			rel:        "missing.toml",
+			wantPaths: []string{
+				filepath.Join(configHome, "myapp", "missing.toml"),
+				filepath.Join(etc, "myapp", "missing.toml"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", tt.configHome)
+			t.Setenv("XDG_CONFIG_DIRS", tt.configDirs)
+
+			got, err := app.SearchConfigFile(tt.rel)
+			if tt.wantPaths != nil {
+				var nerr *NotFoundError
+				if !errors.As(err, &nerr) {
+					t.Fatalf("SearchConfigFile(%q) error = %v, want *NotFoundError", tt.rel, err)
+				}
+				if !reflect.DeepEqual(nerr.Paths, tt.wantPaths) {
+					t.Errorf("NotFoundError.Paths = %v, want %v", nerr.Paths, tt.wantPaths)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("SearchConfigFile(%q) = %v, want %v", tt.rel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchConfigFile(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	configHome := filepath.Join(home, ".config")
+	etc := filepath.Join(root, "etc", "xdg")
+	opt := filepath.Join(root, "opt", "xdg")
+	t.Setenv(homeEnv(), home)
+
+	writeFile(t, filepath.Join(configHome, "mimeapps.list"))
+	writeFile(t, filepath.Join(etc, "mimeapps.list"))
+	writeFile(t, filepath.Join(etc, "user-dirs.defaults"))
+	writeFile(t, filepath.Join(opt, "user-dirs.defaults"))
+	writeFile(t, filepath.Join(opt, "autostart", "app.desktop"))
+	writeFile(t, filepath.Join(configHome, "autostart", "app.desktop", "dir"))
+
+	join := func(dirs ...string) string { return strings.Join(dirs, string(filepath.ListSeparator)) }
+	tests := []struct {
+		name       string
+		configHome string
+		configDirs string
+		rel        string
+		want       string
+		wantPaths  []string
+	}{
+		{
+			name:       "home shadows system",
+			configHome: configHome,
+			configDirs: join(etc, opt),
+			rel:        "mimeapps.list",
+			want:       filepath.Join(configHome, "mimeapps.list"),
+		},
+		{
+			name:       "first system dir wins",
+			configHome: configHome,
+			configDirs: join(etc, opt),
+			rel:        "user-dirs.defaults",
+			want:       filepath.Join(etc, "user-dirs.defaults"),
+		},
+		{
+			name:       "order of config dirs",
+			configHome: configHome,
+			configDirs: join(opt, etc),
+			rel:        "user-dirs.defaults",
+			want:       filepath.Join(opt, "user-dirs.defaults"),
+		},
+		{
+			name:       "skip directory",
+			configHome: configHome,
+			configDirs: join(etc, opt),
+			rel:        "autostart/app.desktop",
+			want:       filepath.Join(opt, "autostart", "app.desktop"),
+		},
+		{
+			name:       "tilde config home",
+			configHome: "~/.config",
+			configDirs: etc,
+			rel:        "mimeapps.list",
+			want:       filepath.Join(configHome, "mimeapps.list"),
+		},
+		{
+			name:       "trailing separators",
+			configHome: configHome + string(filepath.Separator),
+			configDirs: join(etc+string(filepath.Separator), opt),
+			rel:        "user-dirs.defaults",
+			want:       filepath.Join(etc, "user-dirs.defaults"),
+		},
+		{
+			name:       "relative config home is ignored",
+			configHome: "relative",
+			configDirs: join("", opt, ""),
+			rel:        "missing",
+			wantPaths:  []string{filepath.Join(opt, "missing")},
+		},
+		{
+			name:       "duplicated entries",
+			configHome: configHome,
+			configDirs: join(etc, configHome, etc),
+			rel:        "missing",
+			wantPaths: []string{
+				filepath.Join(configHome, "missing"),
+				filepath.Join(etc, "missing"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", tt.configHome)
+			t.Setenv("XDG_CONFIG_DIRS", tt.configDirs)
+
+			got, err := SearchConfigFile(tt.rel)
+			if tt.wantPaths != nil {
+				var nerr *NotFoundError
+				if !errors.As(err, &nerr) {
+					t.Fatalf("SearchConfigFile(%q) error = %v, want *NotFoundError", tt.rel, err)
+				}
+				if !reflect.DeepEqual(nerr.Paths, tt.wantPaths) {
+					t.Errorf("NotFoundError.Paths = %v, want %v", nerr.Paths, tt.wantPaths)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("SearchConfigFile(%q) = %v, want %v", tt.rel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchConfigFileSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlink requires the privilege on windows")
+	}
+
+	root := t.TempDir()
+	configHome := filepath.Join(root, "home")
+	etc := filepath.Join(root, "etc")
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("XDG_CONFIG_DIRS", etc)
+
+	target := filepath.Join(root, "dotfiles", "mimeapps.list")
+	writeFile(t, target)
+	writeFile(t, filepath.Join(etc, "mimeapps.list"))
+	writeFile(t, filepath.Join(etc, "user-dirs.dirs"))
+	if err := os.MkdirAll(configHome, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, filepath.Join(configHome, "mimeapps.list")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, "missing"), filepath.Join(configHome, "user-dirs.dirs")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := SearchConfigFile("mimeapps.list"); err != nil || got != filepath.Join(configHome, "mimeapps.list") {
+		t.Errorf("SearchConfigFile(mimeapps.list) = %v, %v, want the symlink in home", got, err)
+	}
+	if got, err := SearchConfigFile("user-dirs.dirs"); err != nil || got != filepath.Join(etc, "user-dirs.dirs") {
+		t.Errorf("SearchConfigFile(user-dirs.dirs) = %v, %v, want the broken symlink skipped", got, err)
+	}
+}
+
+func TestSearchAllFiles(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	local := filepath.Join(root, "usr", "local", "share")
+	system := filepath.Join(root, "usr", "share")
+	dirs := strings.Join([]string{local, system, local}, string(filepath.ListSeparator))
+	t.Setenv("XDG_DATA_HOME", home)
+	t.Setenv("XDG_DATA_DIRS", dirs)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_CONFIG_DIRS", dirs)
+
+	writeFile(t, filepath.Join(home, "mime", "aliases"))
+	writeFile(t, filepath.Join(system, "mime", "aliases"))
+	writeFile(t, filepath.Join(local, "mime", "aliases", "dir"))
+
+	tests := []struct {
+		name    string
+		search  func(string) ([]string, error)
+		rel     string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "data",
+			search: SearchAllDataFiles,
+			rel:    "mime/aliases",
+			want:   []string{filepath.Join(home, "mime", "aliases"), filepath.Join(system, "mime", "aliases")},
+		},
+		{
+			name:   "config",
+			search: SearchAllConfigFiles,
+			rel:    "mime/aliases",
+			want:   []string{filepath.Join(home, "mime", "aliases"), filepath.Join(system, "mime", "aliases")},
+		},
+		{
+			name:   "nothing found",
+			search: SearchAllDataFiles,
+			rel:    "missing",
+		},
+		{
+			name:    "invalid",
+			search:  SearchAllConfigFiles,
+			rel:     "../mime/aliases",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.search(tt.rel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApp_FindConfigFiles(t *testing.T) {
+	root := t.TempDir()
+	configHome := filepath.Join(root, "home", ".config")
+	etc := filepath.Join(root, "etc", "xdg")
+	opt := filepath.Join(root, "opt", "xdg")
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("XDG_CONFIG_DIRS", strings.Join([]string{etc, opt, etc, configHome}, string(filepath.ListSeparator)))
+
+	writeFile(t, filepath.Join(configHome, "myapp", "config.toml"))
+	writeFile(t, filepath.Join(etc, "myapp", "config.toml"))
+	writeFile(t, filepath.Join(opt, "myapp", "config.toml"))
+	writeFile(t, filepath.Join(opt, "myapp", "system.toml"))
+	writeFile(t, filepath.Join(etc, "myapp", "dir.toml", "file"))
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		rel     string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "all layers",
+			rel:  "config.toml",
+			want: []string{
+				filepath.Join(configHome, "myapp", "config.toml"),
+				filepath.Join(etc, "myapp", "config.toml"),
+				filepath.Join(opt, "myapp", "config.toml"),
+			},
+		},
+		{
+			name: "skip nonexistent",
+			rel:  "system.toml",
+			want: []string{filepath.Join(opt, "myapp", "system.toml")},
+		},
+		{
+			name: "skip directory",
+			rel:  "dir.toml",
+			want: nil,
+		},
+		{
+			name: "not found",
+			rel:  "missing.toml",
+			want: nil,
+		},
+		{
+			name:    "traversal",
+			rel:     "../config.toml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := app.FindConfigFiles(tt.rel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindConfigFiles(%q) error = %v, wantErr %v", tt.rel, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindConfigFiles(%q) = %v, want %v", tt.rel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApp_FindDataFiles(t *testing.T) {
+	root := t.TempDir()
+	dataHome := filepath.Join(root, "home", ".local", "share")
+	usr := filepath.Join(root, "usr", "share")
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv("XDG_DATA_DIRS", strings.Join([]string{usr, usr}, string(filepath.ListSeparator)))
+
+	writeFile(t, filepath.Join(dataHome, "myapp", "themes", "dark.yaml"))
+	writeFile(t, filepath.Join(usr, "myapp", "themes", "dark.yaml"))
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := app.FindDataFiles("themes/dark.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(dataHome, "myapp", "themes", "dark.yaml"),
+		filepath.Join(usr, "myapp", "themes", "dark.yaml"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindDataFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestApp_SearchDataFileSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlink requires the privilege on windows")
+	}
+
+	root := t.TempDir()
+	dataHome := filepath.Join(root, "home")
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv("XDG_DATA_DIRS", filepath.Join(root, "usr", "share"))
+
+	target := filepath.Join(root, "target.txt")
+	writeFile(t, target)
+	if err := os.MkdirAll(filepath.Join(dataHome, "myapp"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dataHome, "myapp", "link.txt")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := app.SearchDataFile("link.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != link {
+		t.Errorf("SearchDataFile() = %v, want %v", got, link)
+	}
+}
+
+func TestApp_ReadConfigFile(t *testing.T) {
+	root := t.TempDir()
+	configHome := filepath.Join(root, "home")
+	etc := filepath.Join(root, "etc", "xdg")
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("XDG_CONFIG_DIRS", etc)
+	writeFileContent(t, filepath.Join(configHome, "myapp", "config.toml"), "home")
+	writeFileContent(t, filepath.Join(etc, "myapp", "config.toml"), "etc")
+	writeFileContent(t, filepath.Join(etc, "myapp", "system.toml"), "etc")
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		rel      string
+		want     string
+		wantPath string
+		wantErr  bool
+	}{
+		{
+			name:     "home shadows system",
+			rel:      "config.toml",
+			want:     "home",
+			wantPath: filepath.Join(configHome, "myapp", "config.toml"),
+		},
+		{
+			name:     "system",
+			rel:      "system.toml",
+			want:     "etc",
+			wantPath: filepath.Join(etc, "myapp", "system.toml"),
+		},
+		{
+			name:    "not found",
+			rel:     "missing.toml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, path, err := app.ReadConfigFile(tt.rel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadConfigFile(%q) error = %v, wantErr %v", tt.rel, err, tt.wantErr)
+			}
+			if string(data) != tt.want || path != tt.wantPath {
+				t.Errorf("ReadConfigFile(%q) = %q, %v, want %q, %v", tt.rel, data, path, tt.want, tt.wantPath)
+			}
+			var nerr *NotFoundError
+			if tt.wantErr && (!errors.As(err, &nerr) || len(nerr.Paths) != 2) {
+				t.Errorf("ReadConfigFile(%q) error = %v, want *NotFoundError with 2 candidates", tt.rel, err)
+			}
+		})
+	}
+}
+
+func TestApp_ReadDataFile(t *testing.T) {
+	root := string(filepath.Separator)
+	if runtime.GOOS == "windows" {
+		root = `C:\`
+	}
+	share := filepath.Join(root, "usr", "share")
+
+	x := New(
+		WithFS(fstest.MapFS{"usr/share/myapp/icons/app.svg": {Data: []byte("<svg/>")}}),
+		WithLookupEnv(mapLookupEnv(map[string]string{
+			"XDG_DATA_HOME": filepath.Join(root, "home", "gopher", ".local", "share"),
+			"XDG_DATA_DIRS": share,
+		})),
+	)
+	app, err := x.App("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, path, err := app.ReadDataFile("icons/app.svg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(share, "myapp", "icons", "app.svg"); string(data) != "<svg/>" || path != want {
+		t.Errorf("ReadDataFile() = %q, %v, want %q, %v", data, path, "<svg/>", want)
+	}
+}
+
+func TestWithFS(t *testing.T) {
+	root := string(filepath.Separator)
+	if runtime.GOOS == "windows" {
+		root = `C:\`
+	}
+	configHome := filepath.Join(root, "home", "gopher", ".config")
+	etc := filepath.Join(root, "etc", "xdg")
+	opt := filepath.Join(root, "opt", "xdg")
+
+	fsys := fstest.MapFS{
+		"home/gopher/.config/myapp/config.toml": {Data: []byte("home")},
+		"etc/xdg/myapp/config.toml":             {Data: []byte("etc")},
+		"etc/xdg/myapp/system.toml":             {Data: []byte("etc")},
+		"opt/xdg/myapp/system.toml":             {Data: []byte("opt")},
+		"opt/xdg/myapp/opt.toml":                {Data: []byte("opt")},
+		"opt/xdg/myapp/dir.toml/file":           {Data: []byte("opt")},
+	}
+	x := New(
+		WithFS(fsys),
+		WithLookupEnv(mapLookupEnv(map[string]string{
+			"XDG_CONFIG_HOME": configHome,
+			"XDG_CONFIG_DIRS": strings.Join([]string{etc, opt}, string(filepath.ListSeparator)),
+		})),
+	)
+	app, err := x.App("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		rel     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "home",
+			rel:  "config.toml",
+			want: filepath.Join(configHome, "myapp", "config.toml"),
+		},
+		{
+			name: "first system dir",
+			rel:  "system.toml",
+			want: filepath.Join(etc, "myapp", "system.toml"),
+		},
+		{
+			name: "last system dir",
+			rel:  "opt.toml",
+			want: filepath.Join(opt, "myapp", "opt.toml"),
+		},
+		{
+			name:    "directory",
+			rel:     "dir.toml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := app.SearchConfigFile(tt.rel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SearchConfigFile(%q) error = %v, wantErr %v", tt.rel, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SearchConfigFile(%q) = %v, want %v", tt.rel, got, tt.want)
+			}
+		})
+	}
+
+	got, err := fs.ReadFile(app.ConfigFS(), "config.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "home" {
+		t.Errorf("ReadFile(ConfigFS(), config.toml) = %q, want %q", got, "home")
+	}
+}
+
+// probeFS is the fs.FS which records the probed names.
+type probeFS struct {
+	fs.FS
+	probed []string
+}
+
+// Open implements fs.FS.
+func (p *probeFS) Open(name string) (fs.File, error) {
+	p.probed = append(p.probed, name)
+	return p.FS.Open(name)
+}
+
+func TestWithFSSearchOrder(t *testing.T) {
+	root := string(filepath.Separator)
+	if runtime.GOOS == "windows" {
+		root = `C:\`
+	}
+	mapfs := fstest.MapFS{
+		"usr/share/mime/globs2":     {Data: []byte("share")},
+		"etc/xdg/mimeapps.list":     {Data: []byte("etc")},
+		"opt/xdg/mimeapps.list":     {Data: []byte("opt")},
+		"opt/xdg/user-dirs.default": {Data: []byte("opt")},
+	}
+	env := map[string]string{
+		"XDG_DATA_HOME":   filepath.Join(root, "home", ".local", "share"),
+		"XDG_DATA_DIRS":   strings.Join([]string{filepath.Join(root, "usr", "local", "share"), filepath.Join(root, "usr", "share")}, string(filepath.ListSeparator)),
+		"XDG_CONFIG_HOME": filepath.Join(root, "home", ".config"),
+		"XDG_CONFIG_DIRS": strings.Join([]string{filepath.Join(root, "etc", "xdg"), filepath.Join(root, "opt", "xdg")}, string(filepath.ListSeparator)),
+	}
+
+	tests := []struct {
+		name       string
+		search     func(*XDG, string) (string, error)
+		rel        string
+		want       string
+		wantProbed []string
+	}{
+		{
+			name:       "data",
+			search:     (*XDG).SearchDataFile,
+			rel:        "mime/globs2",
+			want:       filepath.Join(root, "usr", "share", "mime", "globs2"),
+			wantProbed: []string{"home/.local/share/mime/globs2", "usr/local/share/mime/globs2", "usr/share/mime/globs2"},
+		},
+		{
+			name:       "config stops at the first match",
+			search:     (*XDG).SearchConfigFile,
+			rel:        "mimeapps.list",
+			want:       filepath.Join(root, "etc", "xdg", "mimeapps.list"),
+			wantProbed: []string{"home/.config/mimeapps.list", "etc/xdg/mimeapps.list"},
+		},
+		{
+			name:       "config not found",
+			search:     (*XDG).SearchConfigFile,
+			rel:        "missing",
+			wantProbed: []string{"home/.config/missing", "etc/xdg/missing", "opt/xdg/missing"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := &probeFS{FS: mapfs}
+			x := New(WithFS(fsys), WithLookupEnv(mapLookupEnv(env)))
+
+			got, err := tt.search(x, tt.rel)
+			if (err != nil) != (tt.want == "") {
+				t.Fatalf("search(%q) error = %v", tt.rel, err)
+			}
+			if got != tt.want {
+				t.Errorf("search(%q) = %v, want %v", tt.rel, got, tt.want)
+			}
+			if !reflect.DeepEqual(fsys.probed, tt.wantProbed) {
+				t.Errorf("probed %v, want %v", fsys.probed, tt.wantProbed)
+			}
+		})
+	}
+}
+
+func Test_fsPath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "root", path: string(filepath.Separator), want: "."},
+		{name: "absolute", path: filepath.Join(string(filepath.Separator), "etc", "xdg"), want: "etc/xdg"},
+		{name: "unclean", path: string(filepath.Separator) + filepath.Join("etc", "..", "usr", "share") + string(filepath.Separator), want: "usr/share"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fsPath(tt.path); got != tt.want {
+				t.Errorf("fsPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchConfigFileExt(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	etc := filepath.Join(root, "etc", "xdg")
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_CONFIG_DIRS", etc)
+
+	writeFile(t, filepath.Join(home, "user", "config.json"))
+	writeFile(t, filepath.Join(etc, "user", "config.toml"))
+	writeFile(t, filepath.Join(etc, "system", "config.yaml"))
+	writeFile(t, filepath.Join(etc, "system", "config.json"))
+	writeFile(t, filepath.Join(home, "both", "config.yaml"))
+	writeFile(t, filepath.Join(home, "both", "config.toml"))
+	writeFile(t, filepath.Join(home, "dir", "config.toml", "not a file"))
+	writeFile(t, filepath.Join(home, "dir", "config.yaml"))
+
+	exts := []string{".toml", ".yaml", ".json"}
+	tests := []struct {
+		name      string
+		stem      string
+		exts      []string
+		wantPath  string
+		wantExt   string
+		wantPaths []string
+		wantErr   bool
+	}{
+		{
+			name:     "user beats system regardless of extension",
+			stem:     "user/config",
+			exts:     exts,
+			wantPath: filepath.Join(home, "user", "config.json"),
+			wantExt:  ".json",
+		},
+		{
+			name:     "extension order within a directory",
+			stem:     "both/config",
+			exts:     exts,
+			wantPath: filepath.Join(home, "both", "config.toml"),
+			wantExt:  ".toml",
+		},
+		{
+			name:     "system",
+			stem:     "system/config",
+			exts:     exts,
+			wantPath: filepath.Join(etc, "system", "config.yaml"),
+			wantExt:  ".yaml",
+		},
+		{
+			name:     "skip directory",
+			stem:     "dir/config",
+			exts:     exts,
+			wantPath: filepath.Join(home, "dir", "config.yaml"),
+			wantExt:  ".yaml",
+		},
+		{
+			name: "candidate matrix",
+			stem: "missing/config",
+			exts: []string{".toml", ".json"},
+			wantPaths: []string{
+				filepath.Join(home, "missing", "config.toml"),
+				filepath.Join(home, "missing", "config.json"),
+				filepath.Join(etc, "missing", "config.toml"),
+				filepath.Join(etc, "missing", "config.json"),
+			},
+			wantErr: true,
+		},
+		{
+			name:    "no extension",
+			stem:    "user/config",
+			wantErr: true,
+		},
+		{
+			name:    "invalid",
+			stem:    "../config",
+			exts:    exts,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, ext, err := SearchConfigFileExt(tt.stem, tt.exts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SearchConfigFileExt(%q) error = %v, wantErr %v", tt.stem, err, tt.wantErr)
+			}
+			if path != tt.wantPath || ext != tt.wantExt {
+				t.Errorf("SearchConfigFileExt(%q) = %q, %q, want %q, %q", tt.stem, path, ext, tt.wantPath, tt.wantExt)
+			}
+			if tt.wantPaths != nil {
+				var nf *NotFoundError
+				if !errors.As(err, &nf) || !reflect.DeepEqual(nf.Paths, tt.wantPaths) {
+					t.Errorf("SearchConfigFileExt(%q) error = %v, want candidates %q", tt.stem, err, tt.wantPaths)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchDataFileExt(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	system := filepath.Join(root, "usr", "share")
+	t.Setenv("XDG_DATA_HOME", home)
+	t.Setenv("XDG_DATA_DIRS", system)
+
+	writeFile(t, filepath.Join(system, "myapp", "schema.sql"))
+	writeFile(t, filepath.Join(system, "myapp", "schema.json"))
+
+	path, ext, err := SearchDataFileExt("myapp/schema", []string{".json", ".sql"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(system, "myapp", "schema.json"); path != want || ext != ".json" {
+		t.Errorf("SearchDataFileExt() = %q, %q, want %q, %q", path, ext, want, ".json")
+	}
+}
@@ -0,0 +1,576 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	// userDirsFile is the xdg-user-dirs configuration file name.
+	userDirsFile = "user-dirs.dirs"
+	// userDirsDefaultsFile is the system default configuration file name of xdg-user-dirs.
+	userDirsDefaultsFile = "user-dirs.defaults"
+	// userDirsLocaleFile is the file name which records the locale of the user directory names.
+	userDirsLocaleFile = "user-dirs.locale"
+)
+
+// userDirsHeader is the header of the user-dirs.dirs file created by SetUserDir, same as xdg-user-dirs-update.
+const userDirsHeader = `# This file is written by xdg-user-dirs-update
+# If you want to change or add directories, just edit the line you're
+# interested in. All local changes will be retained on the next run.
+# Format is XDG_xxx_DIR="$HOME/yyy", where yyy is a shell-escaped
+# homedir-relative path, or XDG_xxx_DIR="/yyy", where /yyy is an
+# absolute path. No other format is supported.
+# 
+`
+
+// UserDirKind represents a kind of the well-known user directory.
+type UserDirKind int
+
+const (
+	// UserDirDesktop is the kind of XDG_DESKTOP_DIR.
+	UserDirDesktop UserDirKind = iota
+	// UserDirDownload is the kind of XDG_DOWNLOAD_DIR.
+	UserDirDownload
+	// UserDirTemplates is the kind of XDG_TEMPLATES_DIR.
+	UserDirTemplates
+	// UserDirPublicShare is the kind of XDG_PUBLICSHARE_DIR.
+	UserDirPublicShare
+	// UserDirDocuments is the kind of XDG_DOCUMENTS_DIR.
+	UserDirDocuments
+	// UserDirMusic is the kind of XDG_MUSIC_DIR.
+	UserDirMusic
+	// UserDirPictures is the kind of XDG_PICTURES_DIR.
+	UserDirPictures
+	// UserDirVideos is the kind of XDG_VIDEOS_DIR.
+	UserDirVideos
+)
+
+// String returns the variable name of k such as "XDG_DOWNLOAD_DIR".
+func (k UserDirKind) String() string {
+	if key := k.key(); key != "" {
+		return "XDG_" + key + "_DIR"
+	}
+	return fmt.Sprintf("UserDirKind(%d)", int(k))
+}
+
+// key returns the key of k such as "DOWNLOAD", or empty if k is unknown.
+func (k UserDirKind) key() string {
+	switch k {
+	case UserDirDesktop:
+		return "DESKTOP"
+	case UserDirDownload:
+		return "DOWNLOAD"
+	case UserDirTemplates:
+		return "TEMPLATES"
+	case UserDirPublicShare:
+		return "PUBLICSHARE"
+	case UserDirDocuments:
+		return "DOCUMENTS"
+	case UserDirMusic:
+		return "MUSIC"
+	case UserDirPictures:
+		return "PICTURES"
+	case UserDirVideos:
+		return "VIDEOS"
+	default:
+		return ""
+	}
+}
+
+// UserDirectories is the well-known user directories defined by xdg-user-dirs, such as $HOME/Downloads.
+//
+// All paths are absolute.
+type UserDirectories struct {
+	Desktop     string // XDG_DESKTOP_DIR
+	Download    string // XDG_DOWNLOAD_DIR
+	Templates   string // XDG_TEMPLATES_DIR
+	PublicShare string // XDG_PUBLICSHARE_DIR
+	Documents   string // XDG_DOCUMENTS_DIR
+	Music       string // XDG_MUSIC_DIR
+	Pictures    string // XDG_PICTURES_DIR
+	Videos      string // XDG_VIDEOS_DIR
+}
+
+// UserDirs returns the well-known user directories, which are read from the user-dirs.dirs file searched same as
+// SearchConfigFile, such as $XDG_CONFIG_HOME/user-dirs.dirs.
+//
+// The file is the shell-like assignments such as XDG_DOWNLOAD_DIR="$HOME/Downloads" written by xdg-user-dirs-update.
+// The value is either "$HOME" optionally followed by the slash and the relative path, or the absolute path, and
+// the backslash escapes the next character. The unquoted value is accepted too. The malformed lines and the
+// unknown keys are skipped. The missing keys, or all keys if the file does not exist, fall back to the
+// defaults. The error is returned only if the file can't be read.
+//
+// The defaults are read from the user-dirs.defaults file searched same as SearchConfigFile, such as
+// /etc/xdg/user-dirs.defaults shipped by the distribution, which is used before the user first logs into the
+// desktop session. It is the simple assignments such as DOWNLOAD=Downloads, whose values are relative to the user
+// home directory. The keys missing from it, or all keys if it does not exist either, fall back to the English
+// names such as $HOME/Desktop.
+func UserDirs() (UserDirectories, error) {
+	return std.UserDirs()
+}
+
+// UserDirs returns the well-known user directories.
+//
+// See the package level UserDirs function for details.
+func (x *XDG) UserDirs() (UserDirectories, error) {
+	home := x.home()
+	dirs := defaultUserDirs(home)
+
+	data, err := x.readUserDirsFile(userDirsDefaultsFile)
+	if err != nil {
+		return dirs, err
+	}
+	parseUserDirsDefaults(data, home, &dirs)
+
+	data, err = x.readUserDirsFile(userDirsFile)
+	if err != nil {
+		return dirs, err
+	}
+	parseUserDirs(data, home, &dirs)
+	return dirs, nil
+}
+
+// UserDirsLocale returns the locale recorded in $XDG_CONFIG_HOME/user-dirs.locale, such as "fr_FR", which is the
+// locale the names in user-dirs.defaults were translated into when xdg-user-dirs-update first created the user
+// directories. It returns the empty string if the file does not exist.
+//
+// The tools which recreate the missing user directories should compare it with the current locale, and use
+// the names recorded in user-dirs.dirs returned by UserDirs rather than deriving the names again, so a second
+// "Downloads" is never created next to the existing "Téléchargements".
+func UserDirsLocale() (string, error) {
+	return std.UserDirsLocale()
+}
+
+// UserDirsLocale returns the locale recorded in user-dirs.locale.
+//
+// See the package level UserDirsLocale function for details.
+func (x *XDG) UserDirsLocale() (string, error) {
+	data, err := x.readFile(filepath.Join(x.ConfigHome(), userDirsLocaleFile))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	return strings.TrimSpace(line), nil
+}
+
+// readUserDirsFile reads the rel file searched same as SearchConfigFile. It returns nil if the file is not found.
+func (x *XDG) readUserDirsFile(rel string) ([]byte, error) {
+	path, err := x.SearchConfigFile(rel)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	data, err := x.readFile(path)
+	if err != nil && errors.Is(err, fs.ErrNotExist) {
+		// removed after the search
+		return nil, nil
+	}
+	return data, err
+}
+
+// defaultUserDirs returns the conventional defaults of the user directories under home.
+func defaultUserDirs(home string) UserDirectories {
+	return UserDirectories{
+		Desktop:     filepath.Join(home, "Desktop"),
+		Download:    filepath.Join(home, "Downloads"),
+		Templates:   filepath.Join(home, "Templates"),
+		PublicShare: filepath.Join(home, "Public"),
+		Documents:   filepath.Join(home, "Documents"),
+		Music:       filepath.Join(home, "Music"),
+		Pictures:    filepath.Join(home, "Pictures"),
+		Videos:      filepath.Join(home, "Videos"),
+	}
+}
+
+// field returns the field of dirs for the key such as "DOWNLOAD", or nil if the key is unknown.
+func (dirs *UserDirectories) field(key string) *string {
+	switch key {
+	case "DESKTOP":
+		return &dirs.Desktop
+	case "DOWNLOAD":
+		return &dirs.Download
+	case "TEMPLATES":
+		return &dirs.Templates
+	case "PUBLICSHARE":
+		return &dirs.PublicShare
+	case "DOCUMENTS":
+		return &dirs.Documents
+	case "MUSIC":
+		return &dirs.Music
+	case "PICTURES":
+		return &dirs.Pictures
+	case "VIDEOS":
+		return &dirs.Videos
+	}
+	return nil
+}
+
+// parseUserDirs parses the user-dirs.dirs content data, and sets the assigned directories to dirs.
+func parseUserDirs(data []byte, home string, dirs *UserDirectories) {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSpace(name)
+		if !strings.HasPrefix(name, "XDG_") || !strings.HasSuffix(name, "_DIR") || len(name) <= len("XDG__DIR") {
+			continue
+		}
+		field := dirs.field(name[len("XDG_") : len(name)-len("_DIR")])
+		if field == nil {
+			continue
+		}
+		if dir, ok := parseUserDirValue(strings.TrimSpace(value), home); ok {
+			*field = dir
+		}
+	}
+}
+
+// parseUserDirsDefaults parses the user-dirs.defaults content data, such as "DOWNLOAD=Downloads", and sets
+// the directories relative to home to dirs.
+func parseUserDirsDefaults(data []byte, home string, dirs *UserDirectories) {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		field := dirs.field(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+		if field == nil || value == "" {
+			continue
+		}
+		if filepath.IsAbs(value) {
+			*field = filepath.Clean(value)
+			continue
+		}
+		*field = filepath.Join(home, filepath.FromSlash(value))
+	}
+}
+
+// parseUserDirValue parses the value of assignment, such as "$HOME/Downloads", and returns the absolute path.
+func parseUserDirValue(value, home string) (string, bool) {
+	quoted := strings.HasPrefix(value, `"`)
+	if quoted {
+		value = value[1:]
+	}
+
+	var (
+		b      strings.Builder
+		closed bool
+	)
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == '\\' && i+1 < len(value):
+			i++
+			b.WriteByte(value[i])
+			continue
+		case c == '"' && quoted:
+			closed = true
+		default:
+			b.WriteByte(c)
+			continue
+		}
+		// only the trailing comment may follow the closing quote
+		if rest := strings.TrimSpace(value[i+1:]); rest != "" && rest[0] != '#' {
+			return "", false
+		}
+		break
+	}
+	if quoted && !closed {
+		return "", false
+	}
+
+	dir := b.String()
+	switch {
+	case dir == "$HOME":
+		return filepath.Clean(home), true
+	case strings.HasPrefix(dir, "$HOME/"):
+		return filepath.Join(home, filepath.FromSlash(dir[len("$HOME/"):])), true
+	case filepath.IsAbs(dir):
+		return filepath.Clean(dir), true
+	}
+	return "", false
+}
+
+// SetUserDir sets the user directory of kind to path in $XDG_CONFIG_HOME/user-dirs.dirs, same as
+// xdg-user-dirs-update --set, such as after the user changes the download location.
+//
+// The path must be absolute. The path under the user home directory is written as "$HOME/...", and others are
+// written as the absolute path, quoted and escaped. The existing assignment of kind is replaced in place, and
+// the other lines are preserved byte-for-byte. If the file does not exist, it is created with the standard header.
+// The file is replaced atomically, preserving its access mode.
+//
+// SetUserDir invalidates the cache of the user directory accessors such as DownloadDir, so the subsequent reads
+// see the change.
+func SetUserDir(kind UserDirKind, path string) error {
+	return std.SetUserDir(kind, path)
+}
+
+// SetUserDir sets the user directory of kind in user-dirs.dirs.
+//
+// See the package level SetUserDir function for details.
+func (x *XDG) SetUserDir(kind UserDirKind, path string) error {
+	key := kind.key()
+	if key == "" {
+		return fmt.Errorf("xdgbasedir: unknown user directory kind %v", kind)
+	}
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("xdgbasedir: user directory %s must be absolute: %q", kind, path)
+	}
+
+	file := filepath.Join(x.ConfigHome(), userDirsFile)
+	data, err := os.ReadFile(file)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		data = []byte(userDirsHeader)
+	case err != nil:
+		return err
+	}
+	line := kind.String() + "=" + formatUserDirValue(filepath.Clean(path), x.home()) + "\n"
+	data = replaceUserDir(data, kind.String(), line)
+
+	w, err := createAtomic(file, 0)
+	if err != nil {
+		return err
+	}
+	err = writeAtomic(w, data)
+	if x.userDirs != nil {
+		// the failed write may have replaced the file too
+		x.userDirs.clear()
+	}
+	return err
+}
+
+// formatUserDirValue formats the absolute path as the quoted value of assignment, such as "$HOME/Downloads".
+func formatUserDirValue(path, home string) string {
+	if rel, ok := relPath(filepath.Clean(home), path); home != "" && ok {
+		if rel == "." {
+			rel = ""
+		}
+		return `"$HOME/` + escapeUserDir(rel) + `"`
+	}
+	return `"` + escapeUserDir(filepath.ToSlash(path)) + `"`
+}
+
+// escapeUserDir escapes the characters of s which are special in the shell double quotes.
+func escapeUserDir(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\', '$', '`':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// replaceUserDir replaces the first assignment to name in the user-dirs.dirs content data with line, removing
+// the later ones, or appends line if there is no assignment.
+func replaceUserDir(data []byte, name, line string) []byte {
+	var (
+		out      []byte
+		replaced bool
+	)
+	for _, l := range bytes.SplitAfter(data, []byte("\n")) {
+		if lhs, _, ok := strings.Cut(strings.TrimSpace(string(l)), "="); ok && strings.TrimSpace(lhs) == name {
+			if !replaced {
+				out = append(out, line...)
+				replaced = true
+			}
+			continue
+		}
+		out = append(out, l...)
+	}
+	if !replaced {
+		if len(out) > 0 && out[len(out)-1] != '\n' {
+			out = append(out, '\n')
+		}
+		out = append(out, line...)
+	}
+	return out
+}
+
+// DesktopDir returns the XDG_DESKTOP_DIR user directory, which is $HOME/Desktop by default.
+//
+// The user directory accessors such as DownloadDir share the single parse of user-dirs.dirs, which is read at
+// the first call and cached, and is safe for concurrent use. The cache is keyed by the user home directory and
+// the configuration directories, so the changed environment is read again. Call Refresh after editing the file.
+// If the file can't be read, the conventional default is returned. Use UserDirs for the error.
+func DesktopDir() string {
+	return std.DesktopDir()
+}
+
+// DownloadDir returns the XDG_DOWNLOAD_DIR user directory, which is $HOME/Downloads by default.
+//
+// See DesktopDir for the cache.
+func DownloadDir() string {
+	return std.DownloadDir()
+}
+
+// DocumentsDir returns the XDG_DOCUMENTS_DIR user directory, which is $HOME/Documents by default.
+//
+// See DesktopDir for the cache.
+func DocumentsDir() string {
+	return std.DocumentsDir()
+}
+
+// MusicDir returns the XDG_MUSIC_DIR user directory, which is $HOME/Music by default.
+//
+// See DesktopDir for the cache.
+func MusicDir() string {
+	return std.MusicDir()
+}
+
+// PicturesDir returns the XDG_PICTURES_DIR user directory, which is $HOME/Pictures by default.
+//
+// See DesktopDir for the cache.
+func PicturesDir() string {
+	return std.PicturesDir()
+}
+
+// VideosDir returns the XDG_VIDEOS_DIR user directory, which is $HOME/Videos by default.
+//
+// See DesktopDir for the cache.
+func VideosDir() string {
+	return std.VideosDir()
+}
+
+// TemplatesDir returns the XDG_TEMPLATES_DIR user directory, which is $HOME/Templates by default.
+//
+// See DesktopDir for the cache.
+func TemplatesDir() string {
+	return std.TemplatesDir()
+}
+
+// PublicShareDir returns the XDG_PUBLICSHARE_DIR user directory, which is $HOME/Public by default.
+//
+// See DesktopDir for the cache.
+func PublicShareDir() string {
+	return std.PublicShareDir()
+}
+
+// DesktopDir returns the desktop user directory.
+//
+// See the package level DesktopDir function for details.
+func (x *XDG) DesktopDir() string {
+	return x.cachedUserDirs().Desktop
+}
+
+// DownloadDir returns the download user directory.
+//
+// See the package level DownloadDir function for details.
+func (x *XDG) DownloadDir() string {
+	return x.cachedUserDirs().Download
+}
+
+// DocumentsDir returns the documents user directory.
+//
+// See the package level DocumentsDir function for details.
+func (x *XDG) DocumentsDir() string {
+	return x.cachedUserDirs().Documents
+}
+
+// MusicDir returns the music user directory.
+//
+// See the package level MusicDir function for details.
+func (x *XDG) MusicDir() string {
+	return x.cachedUserDirs().Music
+}
+
+// PicturesDir returns the pictures user directory.
+//
+// See the package level PicturesDir function for details.
+func (x *XDG) PicturesDir() string {
+	return x.cachedUserDirs().Pictures
+}
+
+// VideosDir returns the videos user directory.
+//
+// See the package level VideosDir function for details.
+func (x *XDG) VideosDir() string {
+	return x.cachedUserDirs().Videos
+}
+
+// TemplatesDir returns the templates user directory.
+//
+// See the package level TemplatesDir function for details.
+func (x *XDG) TemplatesDir() string {
+	return x.cachedUserDirs().Templates
+}
+
+// PublicShareDir returns the public share user directory.
+//
+// See the package level PublicShareDir function for details.
+func (x *XDG) PublicShareDir() string {
+	return x.cachedUserDirs().PublicShare
+}
+
+// userDirsCache is the parsed user-dirs.dirs shared by the user directory accessors.
+type userDirsCache struct {
+	mu   sync.Mutex
+	key  string // the home and configuration directories which dirs is parsed for, empty if not parsed
+	dirs UserDirectories
+}
+
+// cachedUserDirs returns the user directories from the cache, parsing user-dirs.dirs if not cached yet.
+func (x *XDG) cachedUserDirs() UserDirectories {
+	c := x.userDirs
+	if c == nil {
+		// the zero XDG not created by New
+		dirs, _ := x.UserDirs()
+		return dirs
+	}
+
+	key := strings.Join(append([]string{x.home()}, x.ConfigDirsAll()...), "\x00")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.key == key {
+		return c.dirs
+	}
+	dirs, err := x.UserDirs()
+	if err != nil {
+		// the transient error such as permission denied is not cached
+		return dirs
+	}
+	c.key, c.dirs = key, dirs
+	return dirs
+}
+
+// clear forgets the cached user directories.
+func (c *userDirsCache) clear() {
+	c.mu.Lock()
+	c.key = ""
+	c.mu.Unlock()
+}
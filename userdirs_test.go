@@ -0,0 +1,402 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestUserDirs(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	configHome := filepath.Join(root, "config")
+	etc := filepath.Join(root, "etc")
+	x := New(WithHome(home), WithEnv(map[string]string{
+		"XDG_CONFIG_HOME": configHome,
+		"XDG_CONFIG_DIRS": etc,
+	}))
+	defaults := defaultUserDirs(home)
+
+	got, err := x.UserDirs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != defaults {
+		t.Errorf("UserDirs() without file = %+v, want %+v", got, defaults)
+	}
+
+	// the system file is used if the user file does not exist
+	writeFileContent(t, filepath.Join(etc, "user-dirs.dirs"), `XDG_MUSIC_DIR="$HOME/System Music"`+"\n")
+	got, err = x.UserDirs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, "System Music"); got.Music != want {
+		t.Errorf("UserDirs().Music = %q, want %q", got.Music, want)
+	}
+
+	writeFileContent(t, filepath.Join(configHome, "user-dirs.dirs"), `# This file is written by xdg-user-dirs-update
+# If you want to change or add directories, just edit the line you're
+# interested in. All local changes will be retained on the next run.
+XDG_DESKTOP_DIR="$HOME/"
+XDG_DOWNLOAD_DIR="$HOME/Downloads/incoming"
+  XDG_TEMPLATES_DIR = "$HOME/My \"Templates\""
+XDG_PUBLICSHARE_DIR="/srv/public"
+XDG_DOCUMENTS_DIR=$HOME/Docs
+XDG_PICTURES_DIR="$HOME/Pictures/\$HOME" # trailing comment
+XDG_VIDEOS_DIR="relative/Videos"
+XDG_MUSIC_DIR="$HOME/unterminated
+XDG_UNKNOWN_DIR="/unknown"
+XDG__DIR="/empty"
+garbage
+=
+`)
+	got, err = x.UserDirs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := UserDirectories{
+		Desktop:     home,
+		Download:    filepath.Join(home, "Downloads", "incoming"),
+		Templates:   filepath.Join(home, `My "Templates"`),
+		PublicShare: filepath.Clean("/srv/public"),
+		Documents:   filepath.Join(home, "Docs"),
+		Pictures:    filepath.Join(home, "Pictures", "$HOME"),
+		// the malformed lines fall back to the defaults, not the system file
+		Videos: defaults.Videos,
+		Music:  defaults.Music,
+	}
+	if !filepath.IsAbs("/srv/public") {
+		want.PublicShare = defaults.PublicShare
+	}
+	if got != want {
+		t.Errorf("UserDirs() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func Test_parseUserDirValue(t *testing.T) {
+	home := filepath.Join(string(filepath.Separator), "home", "foo")
+	tests := []struct {
+		value  string
+		want   string
+		wantOK bool
+	}{
+		{value: `"$HOME/Downloads"`, want: filepath.Join(home, "Downloads"), wantOK: true},
+		{value: `"$HOME"`, want: home, wantOK: true},
+		{value: `"$HOME/a\\b"`, want: filepath.Join(home, `a\b`), wantOK: true},
+		{value: `"$HOME/a" "b"`},
+		{value: `"$HOMEDIR/a"`},
+		{value: `""`},
+		{value: `"$HOME/a`},
+		{value: `~/Downloads`},
+	}
+	for _, tt := range tests {
+		got, ok := parseUserDirValue(tt.value, home)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseUserDirValue(%q) = %q, %v, want %q, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestUserDirAccessors(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	configHome := filepath.Join(root, "config")
+	x := New(WithHome(home), WithEnv(map[string]string{
+		"XDG_CONFIG_HOME": configHome,
+		"XDG_CONFIG_DIRS": filepath.Join(root, "etc"),
+	}))
+	writeFileContent(t, filepath.Join(configHome, "user-dirs.dirs"), `XDG_DESKTOP_DIR="$HOME/desk"
+XDG_DOWNLOAD_DIR="$HOME/dl"
+XDG_DOCUMENTS_DIR="$HOME/docs"
+XDG_MUSIC_DIR="$HOME/music"
+XDG_PICTURES_DIR="$HOME/pics"
+XDG_VIDEOS_DIR="$HOME/vids"
+XDG_TEMPLATES_DIR="$HOME/tmpl"
+`)
+
+	tests := []struct {
+		name string
+		get  func() string
+		want string
+	}{
+		{name: "Desktop", get: x.DesktopDir, want: filepath.Join(home, "desk")},
+		{name: "Download", get: x.DownloadDir, want: filepath.Join(home, "dl")},
+		{name: "Documents", get: x.DocumentsDir, want: filepath.Join(home, "docs")},
+		{name: "Music", get: x.MusicDir, want: filepath.Join(home, "music")},
+		{name: "Pictures", get: x.PicturesDir, want: filepath.Join(home, "pics")},
+		{name: "Videos", get: x.VideosDir, want: filepath.Join(home, "vids")},
+		{name: "Templates", get: x.TemplatesDir, want: filepath.Join(home, "tmpl")},
+		{name: "PublicShare missing key", get: x.PublicShareDir, want: filepath.Join(home, "Public")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.get(); got != tt.want {
+				t.Errorf("%sDir() = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+
+	// the edited file is not read again until the cache is invalidated
+	writeFileContent(t, filepath.Join(configHome, "user-dirs.dirs"), `XDG_DOWNLOAD_DIR="$HOME/incoming"`)
+	if got, want := x.DownloadDir(), filepath.Join(home, "dl"); got != want {
+		t.Errorf("cached DownloadDir() = %q, want %q", got, want)
+	}
+	x.userDirs.clear()
+	if got, want := x.DownloadDir(), filepath.Join(home, "incoming"); got != want {
+		t.Errorf("DownloadDir() after clear = %q, want %q", got, want)
+	}
+
+	// the clone with the other home has its own cache
+	other := filepath.Join(root, "other")
+	if got, want := x.With(WithHome(other)).DownloadDir(), filepath.Join(other, "incoming"); got != want {
+		t.Errorf("DownloadDir() of clone = %q, want %q", got, want)
+	}
+	// the changed environment is read again
+	y := x.With(WithEnv(map[string]string{"XDG_CONFIG_HOME": filepath.Join(root, "missing")}))
+	if got, want := y.DownloadDir(), filepath.Join(home, "Downloads"); got != want {
+		t.Errorf("DownloadDir() without file = %q, want %q", got, want)
+	}
+	if got, want := x.DownloadDir(), filepath.Join(home, "incoming"); got != want {
+		t.Errorf("DownloadDir() of original = %q, want %q", got, want)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if got := x.MusicDir(); got != filepath.Join(home, "Music") {
+					t.Errorf("concurrent MusicDir() = %q", got)
+					return
+				}
+				if j%10 == 0 {
+					x.userDirs.clear()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestUserDirAccessorsRefresh(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Cleanup(Refresh)
+
+	writeFileContent(t, filepath.Join(configHome, "user-dirs.dirs"), `XDG_VIDEOS_DIR="/videos/one"`)
+	if got, want := VideosDir(), filepath.Clean("/videos/one"); filepath.IsAbs("/videos/one") && got != want {
+		t.Errorf("VideosDir() = %q, want %q", got, want)
+	}
+	writeFileContent(t, filepath.Join(configHome, "user-dirs.dirs"), `XDG_VIDEOS_DIR="/videos/two"`)
+	Refresh()
+	if got, want := VideosDir(), filepath.Clean("/videos/two"); filepath.IsAbs("/videos/two") && got != want {
+		t.Errorf("VideosDir() after Refresh = %q, want %q", got, want)
+	}
+}
+
+func TestUserDirsDefaults(t *testing.T) {
+	const defaults = `# Default settings for user directories
+#
+# The values are relative pathnames from the home directory and
+# will be translated on a per-path-element basis into the users locale
+DESKTOP=Schreibtisch
+DOWNLOAD=Downloads/incoming
+TEMPLATES=
+PUBLICSHARE
+MUSIC = Musik
+UNKNOWN=Unknown
+`
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  func(home string) UserDirectories
+	}{
+		{
+			name: "neither",
+			want: defaultUserDirs,
+		},
+		{
+			name:  "only defaults",
+			files: map[string]string{"etc/user-dirs.defaults": defaults},
+			want: func(home string) UserDirectories {
+				want := defaultUserDirs(home)
+				want.Desktop = filepath.Join(home, "Schreibtisch")
+				want.Download = filepath.Join(home, "Downloads", "incoming")
+				want.Music = filepath.Join(home, "Musik")
+				return want
+			},
+		},
+		{
+			name: "both",
+			files: map[string]string{
+				"etc/user-dirs.defaults": defaults,
+				"config/user-dirs.dirs":  `XDG_DESKTOP_DIR="$HOME/Desktop"` + "\n" + `XDG_VIDEOS_DIR="$HOME/Filme"`,
+			},
+			want: func(home string) UserDirectories {
+				want := defaultUserDirs(home)
+				want.Desktop = filepath.Join(home, "Desktop")
+				want.Download = filepath.Join(home, "Downloads", "incoming")
+				want.Music = filepath.Join(home, "Musik")
+				want.Videos = filepath.Join(home, "Filme")
+				return want
+			},
+		},
+		{
+			name: "defaults overridden in the earlier config dir",
+			files: map[string]string{
+				"etc/user-dirs.defaults": defaults,
+				"opt/user-dirs.defaults": "DESKTOP=Bureau\n",
+			},
+			want: func(home string) UserDirectories {
+				want := defaultUserDirs(home)
+				want.Desktop = filepath.Join(home, "Bureau")
+				return want
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			home := filepath.Join(root, "home")
+			for rel, data := range tt.files {
+				writeFileContent(t, filepath.Join(root, filepath.FromSlash(rel)), data)
+			}
+			x := New(WithHome(home), WithEnv(map[string]string{
+				"XDG_CONFIG_HOME": filepath.Join(root, "config"),
+				"XDG_CONFIG_DIRS": filepath.Join(root, "opt") + string(filepath.ListSeparator) + filepath.Join(root, "etc"),
+			}))
+
+			got, err := x.UserDirs()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := tt.want(home); got != want {
+				t.Errorf("UserDirs() =\n%+v\nwant\n%+v", got, want)
+			}
+		})
+	}
+}
+
+func TestSetUserDir(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	configHome := filepath.Join(root, "config")
+	file := filepath.Join(configHome, "user-dirs.dirs")
+	x := New(WithHome(home), WithEnv(map[string]string{
+		"XDG_CONFIG_HOME": configHome,
+		"XDG_CONFIG_DIRS": filepath.Join(root, "etc"),
+	}))
+	abs := filepath.Join(root, `srv/"dl" $x`)
+	read := func() string {
+		t.Helper()
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	// the file is created with the header
+	if got := x.DownloadDir(); got != filepath.Join(home, "Downloads") {
+		t.Fatalf("DownloadDir() = %q", got)
+	}
+	if err := x.SetUserDir(UserDirDownload, filepath.Join(home, "Downloads", "in")); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := read(), userDirsHeader+`XDG_DOWNLOAD_DIR="$HOME/Downloads/in"`+"\n"; got != want {
+		t.Errorf("created file =\n%s\nwant\n%s", got, want)
+	}
+	if got, want := x.DownloadDir(), filepath.Join(home, "Downloads", "in"); got != want {
+		t.Errorf("DownloadDir() after SetUserDir = %q, want %q", got, want)
+	}
+
+	// the assignment is replaced in place, and the others are preserved
+	const orig = "# comment\r\nXDG_DESKTOP_DIR=\"$HOME/\"\r\n  XDG_DOWNLOAD_DIR = \"$HOME/old\"\nXDG_MUSIC_DIR=$HOME/Music\nXDG_DOWNLOAD_DIR=\"$HOME/dup\"\nXDG_VIDEOS_DIR=\"$HOME/Videos\""
+	writeFileContent(t, file, orig)
+	if err := x.SetUserDir(UserDirDownload, abs); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.SetUserDir(UserDirPictures, home); err != nil {
+		t.Fatal(err)
+	}
+	want := "# comment\r\nXDG_DESKTOP_DIR=\"$HOME/\"\r\n" +
+		`XDG_DOWNLOAD_DIR="` + escapeUserDir(filepath.ToSlash(abs)) + `"` + "\n" +
+		"XDG_MUSIC_DIR=$HOME/Music\nXDG_VIDEOS_DIR=\"$HOME/Videos\"\n" +
+		`XDG_PICTURES_DIR="$HOME/"` + "\n"
+	if got := read(); got != want {
+		t.Errorf("rewritten file =\n%s\nwant\n%s", got, want)
+	}
+	if got := x.DownloadDir(); got != abs {
+		t.Errorf("DownloadDir() = %q, want %q", got, abs)
+	}
+	if got := x.PicturesDir(); got != home {
+		t.Errorf("PicturesDir() = %q, want %q", got, home)
+	}
+
+	for _, tt := range []struct {
+		kind UserDirKind
+		path string
+	}{
+		{kind: UserDirMusic, path: "Music"},
+		{kind: UserDirKind(100), path: abs},
+	} {
+		if err := x.SetUserDir(tt.kind, tt.path); err == nil {
+			t.Errorf("SetUserDir(%v, %q) error = nil", tt.kind, tt.path)
+		}
+	}
+	if got := read(); got != want {
+		t.Errorf("file after the invalid SetUserDir =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUserDirsLocale(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	configHome := filepath.Join(root, "config")
+	x := New(WithHome(home), WithEnv(map[string]string{
+		"XDG_CONFIG_HOME": configHome,
+		"XDG_CONFIG_DIRS": filepath.Join(root, "etc"),
+	}))
+
+	got, err := x.UserDirsLocale()
+	if err != nil || got != "" {
+		t.Errorf("UserDirsLocale() without file = %q, %v, want empty", got, err)
+	}
+
+	// the directories were created in french at the first login, and the defaults are english now
+	writeFileContent(t, filepath.Join(configHome, "user-dirs.locale"), "fr_FR\n")
+	writeFileContent(t, filepath.Join(configHome, "user-dirs.dirs"), `XDG_DOWNLOAD_DIR="$HOME/Téléchargements"`+"\n")
+	writeFileContent(t, filepath.Join(root, "etc", "user-dirs.defaults"), "DOWNLOAD=Downloads\nMUSIC=Music\n")
+
+	got, err = x.UserDirsLocale()
+	if err != nil || got != "fr_FR" {
+		t.Errorf("UserDirsLocale() = %q, %v, want %q", got, err, "fr_FR")
+	}
+	dirs, err := x.UserDirs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the recorded name is used to recreate the missing directory, not the default
+	if want := filepath.Join(home, "Téléchargements"); dirs.Download != want {
+		t.Errorf("UserDirs().Download = %q, want %q", dirs.Download, want)
+	}
+	if want := filepath.Join(home, "Music"); dirs.Music != want {
+		t.Errorf("UserDirs().Music = %q, want %q", dirs.Music, want)
+	}
+
+	// the system file is not the record of the user
+	y := x.With(WithEnv(map[string]string{
+		"XDG_CONFIG_HOME": filepath.Join(root, "missing"),
+		"XDG_CONFIG_DIRS": configHome,
+	}))
+	if got, err := y.UserDirsLocale(); err != nil || got != "" {
+		t.Errorf("UserDirsLocale() of the config dirs = %q, %v, want empty", got, err)
+	}
+}
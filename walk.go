@@ -0,0 +1,217 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"sort"
+)
+
+// WalkEntry is the fs.DirEntry which WalkDataDirs passes to the callback.
+//
+// The callback learns which base directory provided the entry by the type assertion, such as d.(WalkEntry).Base.
+type WalkEntry struct {
+	fs.DirEntry
+	// Base is the base directory which provided the entry, such as "/usr/share".
+	Base string
+}
+
+// WalkDataDirs walks the merged tree of $XDG_DATA_HOME and each entry of $XDG_DATA_DIRS rooted at root, such as
+// "icons/hicolor", calling fn for each file or directory in the tree, including root.
+//
+// Each relative path is visited exactly once, and the entry is of the highest-precedence base directory, so the home
+// entries shadow the system entries. The directory listings are merged across the base directories same as DataFS,
+// and walked in lexical order. The path passed to fn is the slash separated path relative to the merged root,
+// and d is always the WalkEntry. The base directories which don't have root are skipped.
+//
+// The semantics of fn are same as fs.WalkDir. If reading the directory of a base directory fails, such as
+// permission denied on the system directory, fn is called with the WalkEntry of that base directory and the error,
+// and the walk continues with the other base directories unless fn returns the error.
+func WalkDataDirs(root string, fn fs.WalkDirFunc) error {
+	return std.WalkDataDirs(root, fn)
+}
+
+// WalkDataDirs walks the merged tree of the data directories.
+//
+// See the package level WalkDataDirs function for details.
+func (x *XDG) WalkDataDirs(root string, fn fs.WalkDirFunc) error {
+	return x.walkDirs(x.DataDirsAll(), root, fn)
+}
+
+// WalkConfig calls fn for each existing regular file of rel in $XDG_CONFIG_HOME and each entry of $XDG_CONFIG_DIRS,
+// such as WalkConfig("myapp/config.toml", fn) for merging the configuration fragments.
+//
+// The files are passed in precedence order, most-important-first same as SearchAllConfigFiles, so the merge
+// logic which wants the user file to win applies the later files first or ignores the keys already set.
+// If fn returns fs.SkipAll, the walk stops and WalkConfig returns nil, which is how fn overrides the rest with
+// the file. Any other error stops the walk and is returned as is. The error is also returned if rel is not
+// resolvable.
+func WalkConfig(rel string, fn func(path string) error) error {
+	return std.WalkConfig(rel, fn)
+}
+
+// WalkConfig calls fn for each existing file of rel in the configuration directories.
+//
+// See the package level WalkConfig function for details.
+func (x *XDG) WalkConfig(rel string, fn func(path string) error) error {
+	paths, err := x.SearchAllConfigFiles(rel)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := fn(path); err != nil {
+			if err == fs.SkipAll {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// walkLayer is the base directory of the merged tree.
+type walkLayer struct {
+	base string
+	fsys fs.FS
+}
+
+// layerError is the error of the layer.
+type layerError struct {
+	layer int
+	err   error
+}
+
+// walkDirs walks the merged tree of dirs rooted at root.
+func (x *XDG) walkDirs(dirs []string, root string, fn fs.WalkDirFunc) error {
+	if !validPath(root) {
+		return &fs.PathError{Op: "walk", Path: root, Err: fs.ErrInvalid}
+	}
+
+	layers := make([]walkLayer, len(dirs))
+	for i, dir := range dirs {
+		layers[i] = walkLayer{base: dir, fsys: x.dirFS(dir)}
+	}
+
+	var (
+		winner fs.FileInfo
+		stack  []int
+		errs   []layerError
+	)
+	for i, l := range layers {
+		fi, err := fs.Stat(l.fsys, root)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, layerError{layer: i, err: err})
+			}
+			if hidden(l.fsys, root) {
+				break
+			}
+			continue
+		}
+		if winner == nil {
+			winner = fi
+		} else if !fi.IsDir() {
+			break
+		}
+		stack = append(stack, i)
+		if !fi.IsDir() {
+			break
+		}
+	}
+
+	var err error
+	if winner == nil {
+		err = &fs.PathError{Op: "walk", Path: root, Err: fs.ErrNotExist}
+		if len(errs) > 0 {
+			err = errs[0].err
+		}
+		err = fn(root, nil, err)
+	} else {
+		d := WalkEntry{DirEntry: fs.FileInfoToDirEntry(winner), Base: layers[stack[0]].base}
+		err = walkDir(layers, root, d, stack, errs, fn)
+	}
+	if err == fs.SkipDir || err == fs.SkipAll {
+		return nil
+	}
+	return err
+}
+
+// walkChild is the merged entry of the directory.
+type walkChild struct {
+	entry   WalkEntry
+	stack   []int
+	stopped bool
+}
+
+// walkDir walks the name of the merged tree, which is provided by the stack layers in precedence order.
+// The errs are reported to fn after visiting name.
+func walkDir(layers []walkLayer, name string, d WalkEntry, stack []int, errs []layerError, fn fs.WalkDirFunc) error {
+	if err := fn(name, d, nil); err != nil || !d.IsDir() {
+		if err == fs.SkipDir && d.IsDir() {
+			err = nil
+		}
+		return err
+	}
+
+	// fs.SkipDir from the error report skips the directory entirely, same as fs.WalkDir
+	report := func(layer int, err error) error {
+		return fn(name, WalkEntry{DirEntry: d.DirEntry, Base: layers[layer].base}, err)
+	}
+	for _, e := range errs {
+		if err := report(e.layer, e.err); err != nil {
+			if err == fs.SkipDir {
+				return nil
+			}
+			return err
+		}
+	}
+
+	var names []string
+	children := make(map[string]*walkChild)
+	for _, i := range stack {
+		entries, err := fs.ReadDir(layers[i].fsys, name)
+		if err != nil {
+			if err := report(i, err); err != nil {
+				if err == fs.SkipDir {
+					return nil
+				}
+				return err
+			}
+			continue
+		}
+
+		for _, entry := range entries {
+			c, ok := children[entry.Name()]
+			if !ok {
+				children[entry.Name()] = &walkChild{entry: WalkEntry{DirEntry: entry, Base: layers[i].base}, stack: []int{i}}
+				names = append(names, entry.Name())
+				continue
+			}
+			// the later directories are merged into the directory until the non-directory hides them
+			if !c.entry.IsDir() || c.stopped {
+				continue
+			}
+			if entry.IsDir() {
+				c.stack = append(c.stack, i)
+			} else {
+				c.stopped = true
+			}
+		}
+	}
+
+	sort.Strings(names)
+	for _, n := range names {
+		c := children[n]
+		if err := walkDir(layers, path.Join(name, n), c.entry, c.stack, nil, fn); err != nil {
+			if err == fs.SkipDir {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// deniedFS is the fs.FS which denies reading the denied directory.
+//
+// The MapFS is not embedded, because its Sub method would bypass the denial.
+type deniedFS struct {
+	fsys   fstest.MapFS
+	denied string
+}
+
+// Open implements fs.FS.
+func (d deniedFS) Open(name string) (fs.File, error) {
+	if name == d.denied {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	return d.fsys.Open(name)
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (d deniedFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name == d.denied {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrPermission}
+	}
+	return d.fsys.ReadDir(name)
+}
+
+// Stat implements fs.StatFS.
+func (d deniedFS) Stat(name string) (fs.FileInfo, error) {
+	return d.fsys.Stat(name)
+}
+
+func TestWalkDataDirs(t *testing.T) {
+	root := string(filepath.Separator)
+	if runtime.GOOS == "windows" {
+		root = `C:\`
+	}
+	home := filepath.Join(root, "home", ".local", "share")
+	local := filepath.Join(root, "usr", "local", "share")
+	system := filepath.Join(root, "usr", "share")
+
+	fsys := deniedFS{
+		fsys: fstest.MapFS{
+			"home/.local/share/icons/hicolor/index.theme":      {Data: []byte("home")},
+			"home/.local/share/icons/hicolor/16x16/app.png":    {Data: []byte("home")},
+			"home/.local/share/icons/hicolor/scalable":         {Data: []byte("home file hides the system directory")},
+			"usr/local/share/icons/hicolor/32x32/app.png":      {Data: []byte("local")},
+			"usr/share/icons/hicolor/index.theme":              {Data: []byte("system")},
+			"usr/share/icons/hicolor/16x16/app.png":            {Data: []byte("system")},
+			"usr/share/icons/hicolor/16x16/other.png":          {Data: []byte("system")},
+			"usr/share/icons/hicolor/scalable/app.svg":         {Data: []byte("system")},
+			"usr/local/share/icons/hicolor/48x48/denied/a.png": {Data: []byte("local")},
+		},
+		denied: "usr/local/share/icons/hicolor/48x48",
+	}
+	x := New(WithFS(fsys), WithLookupEnv(mapLookupEnv(map[string]string{
+		"XDG_DATA_HOME": home,
+		"XDG_DATA_DIRS": strings.Join([]string{local, filepath.Join(root, "missing"), system}, string(filepath.ListSeparator)),
+	})))
+	if x.DataHome() != home {
+		t.Skip("the test environment is for absolute paths")
+	}
+
+	type visit struct {
+		path string
+		base string
+		dir  bool
+		err  bool
+	}
+	var got []visit
+	err := x.WalkDataDirs("icons/hicolor", func(path string, d fs.DirEntry, err error) error {
+		e, ok := d.(WalkEntry)
+		if !ok {
+			t.Fatalf("%s: d is %T, want WalkEntry", path, d)
+		}
+		if err != nil && !errors.Is(err, fs.ErrPermission) {
+			t.Errorf("%s: unexpected error %v", path, err)
+		}
+		got = append(got, visit{path: path, base: e.Base, dir: d.IsDir(), err: err != nil})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []visit{
+		{path: "icons/hicolor", base: home, dir: true},
+		{path: "icons/hicolor/16x16", base: home, dir: true},
+		{path: "icons/hicolor/16x16/app.png", base: home},
+		{path: "icons/hicolor/16x16/other.png", base: system},
+		{path: "icons/hicolor/32x32", base: local, dir: true},
+		{path: "icons/hicolor/32x32/app.png", base: local},
+		{path: "icons/hicolor/48x48", base: local, dir: true},
+		{path: "icons/hicolor/48x48", base: local, dir: true, err: true},
+		{path: "icons/hicolor/index.theme", base: home},
+		{path: "icons/hicolor/scalable", base: home},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkDataDirs() visited\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestWalkDataDirsSkip(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	system := filepath.Join(root, "system")
+	t.Setenv("XDG_DATA_HOME", home)
+	t.Setenv("XDG_DATA_DIRS", system)
+
+	writeFile(t, filepath.Join(home, "themes", "a", "index.theme"))
+	writeFile(t, filepath.Join(system, "themes", "a", "extra"))
+	writeFile(t, filepath.Join(system, "themes", "b", "index.theme"))
+	writeFile(t, filepath.Join(system, "themes", "c", "index.theme"))
+
+	tests := []struct {
+		name string
+		skip func(path string) error
+		want []string
+	}{
+		{
+			name: "skip dir",
+			skip: func(path string) error {
+				if path == "themes/a" {
+					return fs.SkipDir
+				}
+				return nil
+			},
+			want: []string{"themes", "themes/a", "themes/b", "themes/b/index.theme", "themes/c", "themes/c/index.theme"},
+		},
+		{
+			name: "skip all",
+			skip: func(path string) error {
+				if path == "themes/b" {
+					return fs.SkipAll
+				}
+				return nil
+			},
+			want: []string{"themes", "themes/a", "themes/a/extra", "themes/a/index.theme", "themes/b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := WalkDataDirs("themes", func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				got = append(got, path)
+				return tt.skip(path)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WalkDataDirs() visited %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	err := WalkDataDirs("missing", func(path string, d fs.DirEntry, err error) error { return err })
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("WalkDataDirs(missing) error = %v, want fs.ErrNotExist", err)
+	}
+	if err := WalkDataDirs("../escape", func(string, fs.DirEntry, error) error { return nil }); err == nil {
+		t.Error("WalkDataDirs(../escape) expected error")
+	}
+}
+
+func TestWalkConfig(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	local := filepath.Join(root, "etc", "local")
+	etc := filepath.Join(root, "etc", "xdg")
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_CONFIG_DIRS", strings.Join([]string{local, etc}, string(filepath.ListSeparator)))
+
+	writeFile(t, filepath.Join(home, "myapp", "config.toml"))
+	writeFile(t, filepath.Join(etc, "myapp", "config.toml"))
+	writeFile(t, filepath.Join(local, "myapp", "config.toml", "not a file"))
+
+	errBoom := errors.New("boom")
+	tests := []struct {
+		name    string
+		rel     string
+		stop    error
+		stopAt  int
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "precedence order",
+			rel:  "myapp/config.toml",
+			want: []string{filepath.Join(home, "myapp", "config.toml"), filepath.Join(etc, "myapp", "config.toml")},
+		},
+		{
+			name:   "stop",
+			rel:    "myapp/config.toml",
+			stop:   fs.SkipAll,
+			stopAt: 1,
+			want:   []string{filepath.Join(home, "myapp", "config.toml")},
+		},
+		{
+			name:    "error",
+			rel:     "myapp/config.toml",
+			stop:    errBoom,
+			stopAt:  1,
+			want:    []string{filepath.Join(home, "myapp", "config.toml")},
+			wantErr: errBoom,
+		},
+		{
+			name: "missing",
+			rel:  "myapp/missing.toml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := WalkConfig(tt.rel, func(path string) error {
+				got = append(got, path)
+				if len(got) == tt.stopAt {
+					return tt.stop
+				}
+				return nil
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("WalkConfig(%q) error = %v, want %v", tt.rel, err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("WalkConfig(%q) error = %v", tt.rel, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WalkConfig(%q) = %q, want %q", tt.rel, got, tt.want)
+			}
+		})
+	}
+
+	if err := WalkConfig("../config.toml", func(string) error { return nil }); err == nil {
+		t.Error("WalkConfig(../config.toml) expected error")
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// WatchOp is the kind of change reported by App.Watch.
+type WatchOp int
+
+const (
+	// WatchCreated means the file is found where no file was found.
+	WatchCreated WatchOp = iota + 1
+	// WatchModified means the modification time or size of the winning file is changed.
+	WatchModified
+	// WatchRemoved means no file is found anymore.
+	WatchRemoved
+	// WatchSourceChanged means the other file wins the search now, such as the user created the file which shadows
+	// the system file.
+	WatchSourceChanged
+)
+
+// String implements fmt.Stringer.
+func (op WatchOp) String() string {
+	switch op {
+	case WatchCreated:
+		return "Created"
+	case WatchModified:
+		return "Modified"
+	case WatchRemoved:
+		return "Removed"
+	case WatchSourceChanged:
+		return "SourceChanged"
+	}
+	return fmt.Sprintf("WatchOp(%d)", int(op))
+}
+
+// WatchEvent is the change of the searched file reported by App.Watch.
+type WatchEvent struct {
+	Op WatchOp
+	// Path is the winning file path, or the last one for WatchRemoved.
+	Path string
+	// OldPath is the previous winning file path for WatchSourceChanged.
+	OldPath string
+}
+
+// watchState is the result of the search at a time.
+type watchState struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// Watch polls the rel configuration file every interval by re-running the search same as SearchConfigFile, and
+// returns the channel of its changes and the stop func.
+//
+// The path, modification time and size of the winning file are compared with the previous poll, so creating
+// $XDG_CONFIG_HOME/<app>/<rel> which shadows the system file is reported as WatchSourceChanged even though the
+// system file is untouched. The file need not exist at the start. The changes within an interval are coalesced,
+// and the change which keeps the modification time and size is not detected. No notification API is used, so it
+// works on any filesystem including WithFS.
+//
+// The polling pauses until the pending event is received. The channel is closed after the stop func is called,
+// and calling the stop func twice is no-op.
+func (a *App) Watch(rel string, interval time.Duration) (<-chan WatchEvent, func(), error) {
+	if _, err := cleanRel(rel); err != nil {
+		return nil, nil, err
+	}
+	if interval <= 0 {
+		return nil, nil, fmt.Errorf("xdgbasedir: invalid watch interval %v", interval)
+	}
+	prev, err := a.watchState(rel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events := make(chan WatchEvent)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(events)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			cur, err := a.watchState(rel)
+			if err != nil {
+				// the transient error such as permission denied, and retried on the next poll
+				continue
+			}
+			ev, changed := watchChange(prev, cur)
+			prev = cur
+			if !changed {
+				continue
+			}
+			select {
+			case events <- ev:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+	return events, stop, nil
+}
+
+// watchState searches the rel configuration file. The path of the result is empty if no file is found.
+func (a *App) watchState(rel string) (watchState, error) {
+	path, err := a.SearchConfigFile(rel)
+	if err != nil {
+		var nf *NotFoundError
+		if errors.As(err, &nf) {
+			return watchState{}, nil
+		}
+		return watchState{}, err
+	}
+	fi, err := a.x.stat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			// removed after the search
+			return watchState{}, nil
+		}
+		return watchState{}, err
+	}
+	return watchState{path: path, modTime: fi.ModTime(), size: fi.Size()}, nil
+}
+
+// watchChange returns the event of the change from prev to cur.
+func watchChange(prev, cur watchState) (WatchEvent, bool) {
+	switch {
+	case prev.path == "" && cur.path == "":
+		return WatchEvent{}, false
+	case prev.path == "":
+		return WatchEvent{Op: WatchCreated, Path: cur.path}, true
+	case cur.path == "":
+		return WatchEvent{Op: WatchRemoved, Path: prev.path}, true
+	case prev.path != cur.path:
+		return WatchEvent{Op: WatchSourceChanged, Path: cur.path, OldPath: prev.path}, true
+	case !prev.modTime.Equal(cur.modTime) || prev.size != cur.size:
+		return WatchEvent{Op: WatchModified, Path: cur.path}, true
+	}
+	return WatchEvent{}, false
+}
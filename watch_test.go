@@ -0,0 +1,129 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestApp_Watch(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	etc := filepath.Join(root, "etc", "xdg")
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_CONFIG_DIRS", etc)
+
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := filepath.Join(home, "myapp", "config.toml")
+	system := filepath.Join(etc, "myapp", "config.toml")
+
+	events, stop, err := app.Watch("config.toml", 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	next := func(t *testing.T, want WatchEvent) {
+		t.Helper()
+
+		select {
+		case got := <-events:
+			if got != want {
+				t.Fatalf("Watch() event = %+v, want %+v", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %+v", want)
+		}
+	}
+
+	steps := []struct {
+		name   string
+		change func(t *testing.T)
+		want   WatchEvent
+	}{
+		{
+			name:   "created",
+			change: func(t *testing.T) { writeFileContent(t, system, "system") },
+			want:   WatchEvent{Op: WatchCreated, Path: system},
+		},
+		{
+			name:   "shadowed by the user file",
+			change: func(t *testing.T) { writeFileContent(t, user, "user") },
+			want:   WatchEvent{Op: WatchSourceChanged, Path: user, OldPath: system},
+		},
+		{
+			name:   "modified",
+			change: func(t *testing.T) { writeFileContent(t, user, "user modified") },
+			want:   WatchEvent{Op: WatchModified, Path: user},
+		},
+		{
+			name: "user file removed",
+			change: func(t *testing.T) {
+				if err := os.Remove(user); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: WatchEvent{Op: WatchSourceChanged, Path: system, OldPath: user},
+		},
+		{
+			name: "removed",
+			change: func(t *testing.T) {
+				if err := os.Remove(system); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: WatchEvent{Op: WatchRemoved, Path: system},
+		},
+	}
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			step.change(t)
+			next(t, step.want)
+		})
+	}
+
+	stop()
+	stop()
+	if _, ok := <-events; ok {
+		t.Error("Watch() channel is not closed after stop")
+	}
+}
+
+func TestApp_WatchInvalid(t *testing.T) {
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := app.Watch("../config.toml", time.Second); err == nil {
+		t.Error("Watch(../config.toml) expected error")
+	}
+	if _, _, err := app.Watch("config.toml", 0); err == nil {
+		t.Error("Watch() with zero interval expected error")
+	}
+}
+
+func TestWatchOpString(t *testing.T) {
+	tests := []struct {
+		op   WatchOp
+		want string
+	}{
+		{op: WatchCreated, want: "Created"},
+		{op: WatchModified, want: "Modified"},
+		{op: WatchRemoved, want: "Removed"},
+		{op: WatchSourceChanged, want: "SourceChanged"},
+		{op: 0, want: "WatchOp(0)"},
+	}
+	for _, tt := range tests {
+		if got := tt.op.String(); got != tt.want {
+			t.Errorf("WatchOp(%d).String() = %q, want %q", int(tt.op), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// WriteConfigFile writes data to the rel file under $XDG_CONFIG_HOME atomically, such as "myapp/config.toml".
+//
+// See App.WriteConfigFile for the semantics.
+func WriteConfigFile(rel string, data []byte, perm os.FileMode) error {
+	w, err := ConfigFileWriter(rel, perm)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(w, data)
+}
+
+// ConfigFileWriter returns the io.WriteCloser which replaces the rel file under $XDG_CONFIG_HOME atomically on Close.
+//
+// See App.ConfigFileWriter for the semantics.
+func ConfigFileWriter(rel string, perm os.FileMode) (io.WriteCloser, error) {
+	rel, err := cleanRel(rel)
+	if err != nil {
+		return nil, err
+	}
+	return createAtomic(filepath.Join(ConfigHome(), rel), perm)
+}
+
+// WriteConfigFile writes data to the rel file under $XDG_CONFIG_HOME/<app> atomically.
+//
+// The data is written to the temporary file in the same directory, and the temporary file is fsynced and renamed
+// over the target, and then the directory is fsynced. So the target is either the old content or the new content
+// even if the machine dies mid-write. The parent directories are created with 0700 as needed.
+// If perm is zero, the mode of existing file is preserved, or 0600 is used for the new file.
+func (a *App) WriteConfigFile(rel string, data []byte, perm os.FileMode) error {
+	w, err := a.ConfigFileWriter(rel, perm)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(w, data)
+}
+
+// ConfigFileWriter returns the io.WriteCloser which replaces the rel file under $XDG_CONFIG_HOME/<app> atomically on Close.
+//
+// It is the streaming variant of WriteConfigFile for the large files. The written data is invisible until Close.
+// If any Write fails, Close discards the temporary file and returns the error, and the target is left as is.
+func (a *App) ConfigFileWriter(rel string, perm os.FileMode) (io.WriteCloser, error) {
+	path, err := a.ConfigFile(rel)
+	if err != nil {
+		return nil, err
+	}
+	return createAtomic(path, perm)
+}
+
+// writeAtomic writes data to w and closes it.
+func writeAtomic(w io.WriteCloser, data []byte) error {
+	_, err := w.Write(data)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// atomicFile is the temporary file which is renamed over the target path on Close.
+type atomicFile struct {
+	f      *os.File
+	path   string
+	err    error
+	closed bool
+	// exclusive publishes the file only if the target path does not exist
+	exclusive bool
+}
+
+// createAtomic creates the temporary file for the path in the same directory, and its parent directories.
+func createAtomic(path string, perm os.FileMode) (*atomicFile, error) {
+	if perm == 0 {
+		perm = 0600
+		fi, err := os.Stat(path)
+		switch {
+		case err == nil:
+			perm = fi.Mode().Perm()
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, err
+		}
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return &atomicFile{f: f, path: path}, nil
+}
+
+// Write implements io.Writer.
+func (w *atomicFile) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.f.Write(p)
+	if err != nil {
+		w.err = err
+	}
+	return n, err
+}
+
+// Close implements io.Closer. It renames the temporary file over the target path.
+func (w *atomicFile) Close() error {
+	if w.closed {
+		return fs.ErrClosed
+	}
+	w.closed = true
+
+	err := w.err
+	if err == nil {
+		err = w.f.Sync()
+	}
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = w.publish()
+	}
+	if err != nil {
+		os.Remove(w.f.Name())
+		return err
+	}
+	return syncDir(filepath.Dir(w.path))
+}
+
+// publish moves the temporary file to the target path.
+func (w *atomicFile) publish() error {
+	if !w.exclusive {
+		return os.Rename(w.f.Name(), w.path)
+	}
+	// unlike rename, the hard link fails with fs.ErrExist if the target path was created in the meantime
+	if err := os.Link(w.f.Name(), w.path); err != nil {
+		return err
+	}
+	return os.Remove(w.f.Name())
+}
+
+// syncDir fsyncs the dir directory to persist the rename.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		// the directory can't be opened for fsync on windows, and the rename is persisted by NTFS journaling
+		return nil
+	}
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cerr := d.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,151 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// assertNoTemp fails if the temporary files are left in dir.
+func assertNoTemp(t *testing.T, dir string) {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(dir, ".*.tmp*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("temporary files are left: %v", matches)
+	}
+}
+
+func TestApp_WriteConfigFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(app.ConfigHome(), "conf.d", "config.toml")
+
+	tests := []struct {
+		name     string
+		data     string
+		perm     os.FileMode
+		wantPerm os.FileMode
+	}{
+		{
+			name:     "new file with default mode",
+			data:     "one",
+			wantPerm: 0600,
+		},
+		{
+			name:     "explicit mode",
+			data:     "two",
+			perm:     0640,
+			wantPerm: 0640,
+		},
+		{
+			name:     "preserve existing mode",
+			data:     "three",
+			wantPerm: 0640,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := app.WriteConfigFile("conf.d/config.toml", []byte(tt.data), tt.perm); err != nil {
+				t.Fatal(err)
+			}
+			if got := readString(t, path); got != tt.data {
+				t.Errorf("content = %q, want %q", got, tt.data)
+			}
+			fi, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if runtime.GOOS != "windows" && fi.Mode().Perm() != tt.wantPerm {
+				t.Errorf("mode = %#o, want %#o", fi.Mode().Perm(), tt.wantPerm)
+			}
+			assertNoTemp(t, filepath.Dir(path))
+		})
+	}
+
+	if err := app.WriteConfigFile("../escape", nil, 0); err == nil {
+		t.Error("WriteConfigFile() expected error for traversal path")
+	}
+}
+
+func TestApp_ConfigFileWriter(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	app, err := NewApp("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(app.ConfigHome(), "large.json")
+	writeFileContent(t, path, "old")
+
+	t.Run("invisible until close", func(t *testing.T) {
+		w, err := app.ConfigFileWriter("large.json", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range []string{"new", " ", "content"} {
+			if _, err := io.WriteString(w, s); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := readString(t, path); got != "old" {
+			t.Errorf("content before Close = %q, want old", got)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if got := readString(t, path); got != "new content" {
+			t.Errorf("content after Close = %q", got)
+		}
+		if err := w.Close(); err == nil {
+			t.Error("second Close expected error")
+		}
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		w, err := app.ConfigFileWriter("large.json", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeErr := errors.New("disk full")
+		w.(*atomicFile).err = writeErr
+
+		if _, err := io.WriteString(w, "partial"); err != writeErr {
+			t.Errorf("Write() error = %v, want %v", err, writeErr)
+		}
+		if err := w.Close(); err != writeErr {
+			t.Errorf("Close() error = %v, want %v", err, writeErr)
+		}
+		if got := readString(t, path); got != "new content" {
+			t.Errorf("content = %q, want left as is", got)
+		}
+		assertNoTemp(t, filepath.Dir(path))
+	})
+}
+
+func TestWriteConfigFile(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	if err := WriteConfigFile("myapp/config.toml", []byte("data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := readString(t, filepath.Join(configHome, "myapp", "config.toml")); got != "data" {
+		t.Errorf("content = %q, want data", got)
+	}
+	if err := WriteConfigFile(filepath.Join(configHome, "abs"), nil, 0600); err == nil {
+		t.Error("WriteConfigFile() expected error for absolute path")
+	}
+}
@@ -0,0 +1,405 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"encoding/json"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// XDG represents a configuration of the XDG base directories.
+//
+// The package level functions delegate to the default XDG which reads the real environment.
+// Use New to have the multiple configurations in one process.
+type XDG struct {
+	// lookupEnv retrieves the value of the environment variable. nil means the real environment.
+	lookupEnv func(key string) (string, bool)
+	// fsys is the filesystem for search operations. nil means the OS filesystem.
+	fsys fs.FS
+	// homeDir overrides the user home directory for the default paths. Empty means not overridden.
+	homeDir string
+	// negCache remembers the failed searches. nil means disabled.
+	negCache *negCache
+	// logger receives the warnings. nil means the standard logger of the log package.
+	logger *log.Logger
+	// userDirs caches the parsed user-dirs.dirs for the user directory accessors such as DownloadDir.
+	userDirs *userDirsCache
+	// finder searches the files for App instead of the base directories. nil means not overridden.
+	finder Finder
+}
+
+// Option configures the XDG.
+type Option func(*XDG)
+
+// WithLookupEnv returns the Option which injects the custom environment lookup function.
+//
+// All base directory accessors use fn instead of the real environment, including the user home directory
+// for the default paths and the tilde expansion. It makes the unit tests hermetic, and enables resolving
+// paths from a captured environment snapshot. The fn has the same semantics as os.LookupEnv.
+//
+// If fn has no home variable such as HOME, the user home directory is empty instead of the real one, so the
+// default paths are relative. Use WithHome to anchor them.
+func WithLookupEnv(fn func(key string) (string, bool)) Option {
+	return func(x *XDG) {
+		x.lookupEnv = fn
+	}
+}
+
+// WithEnv returns the Option which seeds the environment from env, falling back to the real environment for
+// the keys not present in env.
+//
+// The keys are the standard XDG_* names such as "XDG_CONFIG_HOME", plus "HOME" for the user home directory,
+// which is also used for %USERPROFILE% on windows. The present key with the empty value is treated as empty,
+// so it can be used to unset the real environment. env is copied, so the later modification does not affect.
+//
+// If the lookup function is already configured by the preceding WithLookupEnv or WithEnv, such as the XDG
+// cloned by With, the keys not present in env fall back to it instead of the real environment.
+func WithEnv(env map[string]string) Option {
+	m := make(map[string]string, len(env))
+	for k, v := range env {
+		m[k] = v
+	}
+	return func(x *XDG) {
+		next := x.lookupEnv
+		if next == nil {
+			next = os.LookupEnv
+		}
+		x.lookupEnv = func(key string) (string, bool) {
+			if v, ok := m[key]; ok {
+				return v, true
+			}
+			if key == homeEnv() {
+				if v, ok := m["HOME"]; ok {
+					return v, true
+				}
+			}
+			return next(key)
+		}
+	}
+}
+
+// WithFS returns the Option which injects the filesystem for the search and open operations.
+//
+// The fsys is treated as rooted at the filesystem root. The absolute path is mapped to fsys by removing
+// the leading slash, and the volume name on windows, such as "/etc/xdg" to "etc/xdg".
+// It makes it possible to verify the search order with fstest.MapFS without touching the disk.
+func WithFS(fsys fs.FS) Option {
+	return func(x *XDG) {
+		x.fsys = fsys
+	}
+}
+
+// WithFinder returns the Option which makes the App of the XDG search the files through f, such as
+// xdgtest.MapFinder in the unit tests, instead of the base directories.
+//
+// The App methods SearchDataFile, SearchConfigFile, FindDataFiles, FindConfigFiles, ReadDataFile and
+// ReadConfigFile pass the slash separated path under the application directory, such as "myapp/config.toml",
+// to f. The XDG methods themselves still search the base directories, so f can be the XDG of another
+// configuration.
+func WithFinder(f Finder) Option {
+	return func(x *XDG) {
+		x.finder = f
+	}
+}
+
+// WithHome returns the Option which overrides the user home directory with dir.
+//
+// The default paths derived from the user home directory, such as $HOME/.config and $HOME/.local/share,
+// and the tilde expansion are anchored at dir instead. It is useful for resolving the paths for a chroot
+// or another user's home without setting $HOME. The XDG environment variables still take precedence
+// over dir when set, same as the spec. On windows, only the RuntimeDir default is derived from dir,
+// because the other defaults are derived from %APPDATA% and %LOCALAPPDATA%.
+func WithHome(dir string) Option {
+	return func(x *XDG) {
+		x.homeDir = dir
+	}
+}
+
+// WithLogger returns the Option which injects the logger for the warnings, such as the fallback of
+// RuntimeDirOrFallback. The nil l restores the standard logger of the log package. Pass
+// log.New(io.Discard, "", 0) to silence the warnings.
+func WithLogger(l *log.Logger) Option {
+	return func(x *XDG) {
+		x.logger = l
+	}
+}
+
+// std is the default XDG used by the package level functions.
+var std = New()
+
+// New returns a new XDG which reads the base directories from the real environment, unless overridden by opts.
+//
+// The opts are applied in order. If the multiple opts configure the same setting, such as two WithLookupEnv,
+// the last one wins. The different settings compose regardless of the order:
+//
+//   - WithHome takes precedence over the home directory of the environment, such as $HOME of WithLookupEnv,
+//     for the default paths and the tilde expansion.
+//   - The XDG environment variables take precedence over WithHome, same as the spec.
+//   - WithFS only affects the search and open operations, not the resolved paths.
+//   - WithEnv layers over the preceding WithLookupEnv or WithEnv, so the later env map takes precedence
+//     for its keys. WithLookupEnv after WithEnv replaces it.
+//
+// The application scoped paths are derived by XDG.App from the configured XDG.
+func New(opts ...Option) *XDG {
+	x := &XDG{userDirs: new(userDirsCache)}
+	for _, opt := range opts {
+		opt(x)
+	}
+	return x
+}
+
+// With returns a copy of x with opts applied, leaving x untouched.
+//
+// It is useful for tweaking the process default configuration for a subsystem, such as only the cache home:
+//
+//	x := xdgbasedir.New().With(xdgbasedir.WithEnv(map[string]string{"XDG_CACHE_HOME": dir}))
+//
+// The injected lookup function and fs.FS are shared with x, because they are not modified by XDG. The cached
+// user directories are not shared, because opts may change them.
+func (x *XDG) With(opts ...Option) *XDG {
+	c := *x
+	c.userDirs = new(userDirsCache)
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &c
+}
+
+// getenv retrieves the value of the environment variable named by the key.
+func (x *XDG) getenv(key string) string {
+	v, _ := x.lookupenv(key)
+	return v
+}
+
+// lookupenv retrieves the value of the environment variable named by the key, and reports whether it is present.
+func (x *XDG) lookupenv(key string) (string, bool) {
+	if x.lookupEnv == nil {
+		return os.LookupEnv(key)
+	}
+	return x.lookupEnv(key)
+}
+
+// warnf logs the warning to the injected logger, or the standard logger if not injected.
+func (x *XDG) warnf(format string, args ...interface{}) {
+	if x.logger == nil {
+		log.Printf(format, args...)
+		return
+	}
+	x.logger.Printf(format, args...)
+}
+
+// home returns the user home directory for the default paths.
+//
+// The WithHome override is used first. The injected environment is hermetic, so it returns empty if the lookup
+// has no home variable, rather than the real user home directory. The real environment uses the cached user home
+// directory. See Refresh.
+func (x *XDG) home() string {
+	if x.homeDir != "" {
+		return x.homeDir
+	}
+	if x.lookupEnv != nil {
+		return x.getenv(homeEnv())
+	}
+	return userHome()
+}
+
+// lookup returns the env environment variable value which is expanded the user home directory,
+// or the value of fallback function if it is either not set or empty.
+func (x *XDG) lookup(env string, fallback func() string) string {
+	if v := x.getenv(env); v != "" {
+		return x.expandUser(v)
+	}
+	return fallback()
+}
+
+// DataHome return the XDG_DATA_HOME based directory path.
+//
+// See the package level DataHome function for details.
+func (x *XDG) DataHome() string {
+	return x.lookup(EnvDataHome, x.defaultDataHome)
+}
+
+// ConfigHome return the XDG_CONFIG_HOME based directory path.
+//
+// See the package level ConfigHome function for details.
+func (x *XDG) ConfigHome() string {
+	return x.lookup(EnvConfigHome, x.defaultConfigHome)
+}
+
+// DataDirs return the XDG_DATA_DIRS based directory path.
+//
+// See the package level DataDirs function for details.
+func (x *XDG) DataDirs() string {
+	return x.lookup(EnvDataDirs, x.defaultDataDirs)
+}
+
+// DataDirsAll returns the preference-ordered list of data directories.
+//
+// See the package level DataDirsAll function for details.
+func (x *XDG) DataDirsAll() []string {
+	return dirList(x.DataHome(), x.DataDirs())
+}
+
+// ConfigDirs return the XDG_CONFIG_DIRS based directory path.
+//
+// See the package level ConfigDirs function for details.
+func (x *XDG) ConfigDirs() string {
+	return x.lookup(EnvConfigDirs, x.defaultConfigDirs)
+}
+
+// ConfigDirsAll returns the preference-ordered list of configuration directories.
+//
+// See the package level ConfigDirsAll function for details.
+func (x *XDG) ConfigDirsAll() []string {
+	return dirList(x.ConfigHome(), x.ConfigDirs())
+}
+
+// ExistingDataDirs returns the data directories which exist.
+//
+// See the package level ExistingDataDirs function for details.
+func (x *XDG) ExistingDataDirs() []string {
+	return x.existingDirs(x.DataDirsAll())
+}
+
+// ExistingConfigDirs returns the configuration directories which exist.
+//
+// See the package level ExistingConfigDirs function for details.
+func (x *XDG) ExistingConfigDirs() []string {
+	return x.existingDirs(x.ConfigDirsAll())
+}
+
+// existingDirs returns the entries of dirs which exist as the directory on the filesystem of x.
+func (x *XDG) existingDirs(dirs []string) []string {
+	c := existsConfig{require: requireDir}
+	var list []string
+	for _, dir := range dirs {
+		if x.probe(dir, &c) == nil {
+			list = append(list, dir)
+		}
+	}
+	return list
+}
+
+// CacheHome return the XDG_CACHE_HOME based directory path.
+//
+// See the package level CacheHome function for details.
+func (x *XDG) CacheHome() string {
+	return x.lookup(EnvCacheHome, x.defaultCacheHome)
+}
+
+// StateHome return the XDG_STATE_HOME based directory path.
+//
+// See the package level StateHome function for details.
+func (x *XDG) StateHome() string {
+	return x.lookup(EnvStateHome, x.defaultStateHome)
+}
+
+// BinHome return the XDG_BIN_HOME based directory path.
+//
+// See the package level BinHome function for details.
+func (x *XDG) BinHome() string {
+	return x.lookup(EnvBinHome, x.defaultBinHome)
+}
+
+// RuntimeDir return the XDG_RUNTIME_DIR based directory path.
+//
+// See the package level RuntimeDir function for details.
+func (x *XDG) RuntimeDir() string {
+	return x.lookup(EnvRuntimeDir, x.defaultRuntimeDir)
+}
+
+// MarshalJSON implements json.Marshaler. It returns the JSON object of all resolved base directories such as:
+//
+//	{"dataHome":"/home/foo/.local/share","configHome":"/home/foo/.config","cacheHome":"/home/foo/.cache",
+//	"stateHome":"/home/foo/.local/state","binHome":"/home/foo/.local/bin","runtimeDir":"/run/user/1000",
+//	"dataDirs":["/usr/local/share","/usr/share"],"configDirs":["/etc/xdg"]}
+//
+// The dataDirs and configDirs are normalized same as DataDirsAll, excluding the home directory.
+func (x *XDG) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		DataHome   string   `json:"dataHome"`
+		ConfigHome string   `json:"configHome"`
+		CacheHome  string   `json:"cacheHome"`
+		StateHome  string   `json:"stateHome"`
+		BinHome    string   `json:"binHome"`
+		RuntimeDir string   `json:"runtimeDir"`
+		DataDirs   []string `json:"dataDirs"`
+		ConfigDirs []string `json:"configDirs"`
+	}{
+		DataHome:   x.DataHome(),
+		ConfigHome: x.ConfigHome(),
+		CacheHome:  x.CacheHome(),
+		StateHome:  x.StateHome(),
+		BinHome:    x.BinHome(),
+		RuntimeDir: x.RuntimeDir(),
+		DataDirs:   normalizeDirs(filepath.SplitList(x.DataDirs())),
+		ConfigDirs: normalizeDirs(filepath.SplitList(x.ConfigDirs())),
+	})
+}
+
+// String returns the human-readable multi-line summary of all resolved base directories such as:
+//
+//	data-home=/home/foo/.local/share
+//	config-home=/home/foo/.config
+//	data-dirs=/usr/local/share:/usr/share
+//	config-dirs=/etc/xdg
+//	cache-home=/home/foo/.cache
+//	state-home=/home/foo/.local/state
+//	bin-home=/home/foo/.local/bin
+//	runtime-dir=/run/user/1000
+//
+// The keys and order are stable, so it is suitable for the bug reports.
+func (x *XDG) String() string {
+	var b strings.Builder
+	for _, kind := range kinds {
+		dir, _ := x.Dir(kind)
+		b.WriteString(kind.String() + "=" + dir + "\n")
+	}
+	return b.String()
+}
+
+// stat returns the fs.FileInfo of the name file on the filesystem of x. It follows symlinks.
+func (x *XDG) stat(name string) (fs.FileInfo, error) {
+	if x.fsys == nil {
+		return os.Stat(name)
+	}
+	return fs.Stat(x.fsys, fsPath(name))
+}
+
+// readFile reads the name file on the filesystem of x.
+func (x *XDG) readFile(name string) ([]byte, error) {
+	if x.fsys == nil {
+		return os.ReadFile(name)
+	}
+	return fs.ReadFile(x.fsys, fsPath(name))
+}
+
+// dirFS returns the fs.FS rooted at the dir on the filesystem of x.
+func (x *XDG) dirFS(dir string) fs.FS {
+	if x.fsys == nil {
+		return os.DirFS(dir)
+	}
+	sub, err := fs.Sub(x.fsys, fsPath(dir))
+	if err != nil {
+		// unreachable because fsPath always returns the valid path
+		panic(err)
+	}
+	return sub
+}
+
+// fsPath converts the absolute OS path name to the slash separated path of the injected fs.FS.
+func fsPath(name string) string {
+	name = filepath.ToSlash(strings.TrimPrefix(name, filepath.VolumeName(name)))
+	name = strings.TrimLeft(path.Clean("/"+name), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
@@ -0,0 +1,350 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgbasedir
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestXDG(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join("/tmp", "config"))
+	t.Setenv("XDG_DATA_HOME", "")
+
+	x := New()
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "DataHome", got: x.DataHome(), want: DataHome()},
+		{name: "ConfigHome", got: x.ConfigHome(), want: filepath.Join("/tmp", "config")},
+		{name: "DataDirs", got: x.DataDirs(), want: DataDirs()},
+		{name: "ConfigDirs", got: x.ConfigDirs(), want: ConfigDirs()},
+		{name: "CacheHome", got: x.CacheHome(), want: CacheHome()},
+		{name: "StateHome", got: x.StateHome(), want: StateHome()},
+		{name: "RuntimeDir", got: x.RuntimeDir(), want: RuntimeDir()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("%s() = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+
+	if got, want := x.DataDirsAll(), DataDirsAll(); !reflect.DeepEqual(got, want) {
+		t.Errorf("DataDirsAll() = %v, want %v", got, want)
+	}
+	if got, want := x.ConfigDirsAll(), ConfigDirsAll(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ConfigDirsAll() = %v, want %v", got, want)
+	}
+}
+
+// mapLookupEnv returns the environment lookup function backed by env.
+func mapLookupEnv(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+func TestXDGInstances(t *testing.T) {
+	// two different configurations in one process
+	x1 := New(WithLookupEnv(mapLookupEnv(map[string]string{"XDG_CONFIG_HOME": filepath.Join("/tmp", "one")})))
+	x2 := New(WithLookupEnv(mapLookupEnv(map[string]string{"XDG_CONFIG_HOME": filepath.Join("/tmp", "two")})))
+
+	if got, want := x1.ConfigHome(), filepath.Join("/tmp", "one"); got != want {
+		t.Errorf("x1.ConfigHome() = %v, want %v", got, want)
+	}
+	if got, want := x2.ConfigHome(), filepath.Join("/tmp", "two"); got != want {
+		t.Errorf("x2.ConfigHome() = %v, want %v", got, want)
+	}
+}
+
+func TestWithLookupEnv(t *testing.T) {
+	// the real environment must not affect the result
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join("/tmp", "real"))
+	t.Setenv("XDG_DATA_DIRS", filepath.Join("/tmp", "real"))
+
+	homeEnvKey := homeEnv()
+	home := filepath.Join("/tmp", "home")
+	x := New(WithLookupEnv(mapLookupEnv(map[string]string{
+		homeEnvKey:        home,
+		"XDG_DATA_HOME":   "~/data",
+		"XDG_CACHE_HOME":  "",
+		"XDG_RUNTIME_DIR": filepath.Join("/run", "user", "1000"),
+	})))
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "tilde expansion by injected home", got: x.DataHome(), want: filepath.Join(home, "data")},
+		{name: "default of injected home", got: x.ConfigHome(), want: x.defaultConfigHome()},
+		{name: "not from real environment", got: x.DataDirs(), want: x.defaultDataDirs()},
+		{name: "empty value", got: x.CacheHome(), want: x.defaultCacheHome()},
+		{name: "set value", got: x.RuntimeDir(), want: filepath.Join("/run", "user", "1000")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+
+	if runtime.GOOS != "windows" {
+		if got, want := x.ConfigHome(), filepath.Join(home, ".config"); got != want {
+			t.Errorf("ConfigHome() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestWithHome(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the defaults are derived from %APPDATA% on windows")
+	}
+
+	chroot := filepath.Join("/srv", "chroot", "home", "gopher")
+	x := New(WithHome(chroot), WithLookupEnv(mapLookupEnv(map[string]string{
+		homeEnv():        filepath.Join("/home", "real"),
+		"XDG_CACHE_HOME": filepath.Join("/tmp", "cache"),
+		"XDG_DATA_HOME":  "~/data",
+	})))
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "default anchored at home", got: x.ConfigHome(), want: filepath.Join(chroot, ".config")},
+		{name: "nested default anchored at home", got: x.StateHome(), want: filepath.Join(chroot, ".local", "state")},
+		{name: "env takes precedence", got: x.CacheHome(), want: filepath.Join("/tmp", "cache")},
+		{name: "tilde expansion", got: x.DataHome(), want: filepath.Join(chroot, "data")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXDG_MarshalJSON(t *testing.T) {
+	root := string(filepath.Separator)
+	if runtime.GOOS == "windows" {
+		root = `C:\`
+	}
+	dir := func(elem ...string) string { return filepath.Join(append([]string{root}, elem...)...) }
+
+	x := New(WithLookupEnv(mapLookupEnv(map[string]string{
+		"XDG_DATA_HOME":   dir("data"),
+		"XDG_CONFIG_HOME": dir("config"),
+		"XDG_CACHE_HOME":  dir("cache"),
+		"XDG_STATE_HOME":  dir("state"),
+		"XDG_BIN_HOME":    dir("bin"),
+		"XDG_RUNTIME_DIR": dir("run"),
+		"XDG_DATA_DIRS":   strings.Join([]string{dir("usr", "local", "share"), "relative", dir("usr", "share")}, string(filepath.ListSeparator)),
+		"XDG_CONFIG_DIRS": dir("etc", "xdg"),
+	})))
+
+	got, err := json.Marshal(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := json.Marshal(map[string]interface{}{
+		"dataHome":   dir("data"),
+		"configHome": dir("config"),
+		"cacheHome":  dir("cache"),
+		"stateHome":  dir("state"),
+		"binHome":    dir("bin"),
+		"runtimeDir": dir("run"),
+		"dataDirs":   []string{dir("usr", "local", "share"), dir("usr", "share")},
+		"configDirs": []string{dir("etc", "xdg")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotMap, wantMap map[string]interface{}
+	if err := json.Unmarshal(got, &gotMap); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(want, &wantMap); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotMap, wantMap) {
+		t.Errorf("MarshalJSON() = %s, want %s", got, want)
+	}
+}
+
+func TestXDG_String(t *testing.T) {
+	x := New(WithLookupEnv(mapLookupEnv(map[string]string{
+		"XDG_DATA_HOME":   "/data",
+		"XDG_CONFIG_HOME": "/config",
+		"XDG_DATA_DIRS":   "/usr/local/share:/usr/share",
+		"XDG_CONFIG_DIRS": "/etc/xdg",
+		"XDG_CACHE_HOME":  "/cache",
+		"XDG_STATE_HOME":  "/state",
+		"XDG_BIN_HOME":    "/bin",
+		"XDG_RUNTIME_DIR": "/run/user/1000",
+	})))
+
+	want := `data-home=/data
+config-home=/config
+data-dirs=/usr/local/share:/usr/share
+config-dirs=/etc/xdg
+cache-home=/cache
+state-home=/state
+bin-home=/bin
+runtime-dir=/run/user/1000
+`
+	if got := x.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestNewOptionOrder(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the defaults are derived from %APPDATA% on windows")
+	}
+
+	env1 := WithLookupEnv(mapLookupEnv(map[string]string{"XDG_CONFIG_HOME": "/one"}))
+	env2 := WithLookupEnv(mapLookupEnv(map[string]string{"XDG_CONFIG_HOME": "/two", homeEnv(): "/home/env"}))
+	home := WithHome("/home/opt")
+
+	tests := []struct {
+		name           string
+		opts           []Option
+		wantConfigHome string
+		wantCacheHome  string
+	}{
+		{
+			name:           "last lookup wins",
+			opts:           []Option{env2, env1},
+			wantConfigHome: "/one",
+			// env1 has no HOME, and the real home directory is never used
+			wantCacheHome: ".cache",
+		},
+		{
+			name:           "home before lookup",
+			opts:           []Option{home, env2},
+			wantConfigHome: "/two",
+			wantCacheHome:  filepath.Join("/home/opt", ".cache"),
+		},
+		{
+			name:           "home after lookup",
+			opts:           []Option{env2, home},
+			wantConfigHome: "/two",
+			wantCacheHome:  filepath.Join("/home/opt", ".cache"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := New(tt.opts...)
+			if got := x.ConfigHome(); got != tt.wantConfigHome {
+				t.Errorf("ConfigHome() = %v, want %v", got, tt.wantConfigHome)
+			}
+			if got := x.CacheHome(); got != tt.wantCacheHome {
+				t.Errorf("CacheHome() = %v, want %v", got, tt.wantCacheHome)
+			}
+		})
+	}
+}
+
+func TestWithEnv(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", filepath.Join("/tmp", "real-cache"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join("/tmp", "real-state"))
+
+	env := map[string]string{
+		"HOME":            filepath.Join("/tmp", "home"),
+		"XDG_CONFIG_HOME": filepath.Join("/tmp", "config"),
+		"XDG_STATE_HOME":  "",
+		"XDG_DATA_HOME":   "~/data",
+	}
+	x := New(WithEnv(env))
+	env["XDG_CONFIG_HOME"] = filepath.Join("/tmp", "modified")
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "from map", got: x.ConfigHome(), want: filepath.Join("/tmp", "config")},
+		{name: "fallback to real environment", got: x.CacheHome(), want: filepath.Join("/tmp", "real-cache")},
+		{name: "empty value unsets", got: x.StateHome(), want: x.defaultStateHome()},
+		{name: "home for tilde expansion", got: x.DataHome(), want: filepath.Join("/tmp", "home", "data")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+
+	if runtime.GOOS != "windows" {
+		if got, want := x.StateHome(), filepath.Join("/tmp", "home", ".local", "state"); got != want {
+			t.Errorf("StateHome() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestXDG_With(t *testing.T) {
+	x := New(WithLookupEnv(mapLookupEnv(map[string]string{
+		"XDG_CONFIG_HOME": filepath.Join("/tmp", "config"),
+		"XDG_CACHE_HOME":  filepath.Join("/tmp", "cache"),
+	})))
+	c := x.With(WithEnv(map[string]string{"XDG_CACHE_HOME": filepath.Join("/tmp", "subsystem")}), WithHome(filepath.Join("/tmp", "home")))
+
+	if got, want := c.CacheHome(), filepath.Join("/tmp", "subsystem"); got != want {
+		t.Errorf("clone CacheHome() = %v, want %v", got, want)
+	}
+	if got, want := c.ConfigHome(), filepath.Join("/tmp", "config"); got != want {
+		t.Errorf("clone ConfigHome() = %v, want inherited %v", got, want)
+	}
+	if got, want := x.CacheHome(), filepath.Join("/tmp", "cache"); got != want {
+		t.Errorf("original CacheHome() = %v, want %v", got, want)
+	}
+	if x.homeDir != "" {
+		t.Errorf("original homeDir = %q, want untouched", x.homeDir)
+	}
+
+	// no options returns the equivalent copy
+	if c := x.With(); c == x || c.String() != x.String() {
+		t.Errorf("With() = %p %q, want copy of %p %q", c, c, x, x)
+	}
+}
+
+func TestWithEnvLayered(t *testing.T) {
+	x := New(
+		WithLookupEnv(mapLookupEnv(map[string]string{"XDG_CONFIG_HOME": "/lookup", "XDG_DATA_HOME": "/lookup"})),
+		WithEnv(map[string]string{"XDG_CONFIG_HOME": "/env1", "XDG_CACHE_HOME": "/env1"}),
+		WithEnv(map[string]string{"XDG_CACHE_HOME": "/env2"}),
+	)
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "from lookup", got: x.DataHome(), want: "/lookup"},
+		{name: "first env overrides lookup", got: x.ConfigHome(), want: "/env1"},
+		{name: "last env overrides first", got: x.CacheHome(), want: "/env2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgafero
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	xdgbasedir "github.com/zchee/go-xdgbasedir"
+)
+
+// WithFs returns the xdgbasedir.Option which searches and opens the files on fsys, such as afero.NewMemMapFs
+// for testing the config loading end-to-end.
+//
+// The fsys is treated as rooted at the filesystem root same as xdgbasedir.WithFS, so the files are placed at
+// their absolute paths such as "/etc/xdg/myapp/config.toml".
+func WithFs(fsys afero.Fs) xdgbasedir.Option {
+	return xdgbasedir.WithFS(afero.NewIOFS(afero.NewBasePathFs(fsys, string(filepath.Separator))))
+}
+
+// DataFs returns the read-only afero.Fs over the merged view of data directories of x. See xdgbasedir.DataFS.
+//
+// The name is relative to the merged root, such as "myapp/schema.sql". The leading slash is ignored.
+func DataFs(x *xdgbasedir.XDG) afero.Fs {
+	return readOnlyFs{afero.FromIOFS{FS: x.DataFS()}}
+}
+
+// ConfigFs returns the read-only afero.Fs over the merged view of configuration directories of x.
+// See xdgbasedir.ConfigFS.
+//
+// The name is relative to the merged root, such as "myapp/config.toml". The leading slash is ignored.
+func ConfigFs(x *xdgbasedir.XDG) afero.Fs {
+	return readOnlyFs{afero.FromIOFS{FS: x.ConfigFS()}}
+}
+
+// readOnlyFs is the afero.Fs which converts the afero style name to the fs.FS path.
+type readOnlyFs struct {
+	afero.FromIOFS
+}
+
+var _ afero.Fs = readOnlyFs{}
+
+// Open implements afero.Fs.
+func (r readOnlyFs) Open(name string) (afero.File, error) {
+	return r.FromIOFS.Open(fsName(name))
+}
+
+// OpenFile implements afero.Fs. Only the read-only flag is supported.
+func (r readOnlyFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_APPEND|os.O_CREATE|os.O_TRUNC) != 0 {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	return r.Open(name)
+}
+
+// Stat implements afero.Fs.
+func (r readOnlyFs) Stat(name string) (os.FileInfo, error) {
+	return r.FromIOFS.Stat(fsName(name))
+}
+
+// Name implements afero.Fs.
+func (r readOnlyFs) Name() string {
+	return "xdgafero"
+}
+
+// fsName converts the afero style name, which may be rooted, to the fs.FS path.
+func fsName(name string) string {
+	name = strings.TrimLeft(path.Clean("/"+filepath.ToSlash(name)), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
@@ -0,0 +1,120 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgafero
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+
+	xdgbasedir "github.com/zchee/go-xdgbasedir"
+)
+
+func setupMemFs(t *testing.T) *xdgbasedir.XDG {
+	t.Helper()
+
+	mem := afero.NewMemMapFs()
+	for name, data := range map[string]string{
+		"/home/gopher/.config/myapp/config.toml": "home",
+		"/etc/xdg/myapp/config.toml":             "etc",
+		"/etc/xdg/myapp/system.toml":             "etc",
+		"/usr/share/myapp/schema.sql":            "share",
+	} {
+		if err := afero.WriteFile(mem, filepath.FromSlash(name), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return xdgbasedir.New(
+		WithFs(mem),
+		xdgbasedir.WithEnv(map[string]string{
+			"XDG_CONFIG_HOME": filepath.FromSlash("/home/gopher/.config"),
+			"XDG_CONFIG_DIRS": filepath.FromSlash("/etc/xdg"),
+			"XDG_DATA_HOME":   filepath.FromSlash("/home/gopher/.local/share"),
+			"XDG_DATA_DIRS":   filepath.FromSlash("/usr/share"),
+		}),
+	)
+}
+
+func TestWithFs(t *testing.T) {
+	x := setupMemFs(t)
+	if !filepath.IsAbs(x.ConfigHome()) {
+		t.Skip("the test environment is for unix paths")
+	}
+	app, err := x.App("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, path, err := app.ReadConfigFile("system.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.FromSlash("/etc/xdg/myapp/system.toml"); path != want || string(data) != "etc" {
+		t.Errorf("ReadConfigFile() = %q, %q, want %q, %q", data, path, "etc", want)
+	}
+}
+
+func TestConfigFs(t *testing.T) {
+	x := setupMemFs(t)
+	if !filepath.IsAbs(x.ConfigHome()) {
+		t.Skip("the test environment is for unix paths")
+	}
+	fsys := ConfigFs(x)
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "myapp/config.toml", want: "home"},
+		{name: "/myapp/config.toml", want: "home"},
+		{name: "myapp/system.toml", want: "etc"},
+		{name: "myapp/missing.toml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := afero.ReadFile(fsys, tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadFile(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ReadFile(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := fsys.OpenFile("myapp/config.toml", os.O_WRONLY, 0); !os.IsPermission(err) {
+		t.Errorf("OpenFile(O_WRONLY) error = %v, want permission error", err)
+	}
+	if err := afero.WriteFile(fsys, "myapp/new.toml", nil, 0600); err == nil {
+		t.Error("WriteFile() expected error")
+	}
+}
+
+func TestDataFs(t *testing.T) {
+	x := setupMemFs(t)
+	if !filepath.IsAbs(x.DataHome()) {
+		t.Skip("the test environment is for unix paths")
+	}
+
+	got, err := afero.ReadFile(DataFs(x), "myapp/schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "share" {
+		t.Errorf("ReadFile() = %q, want %q", got, "share")
+	}
+
+	fi, err := DataFs(x).Stat("/myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fi.IsDir() {
+		t.Error("Stat(/myapp) is not a directory")
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package xdgafero implements the adapter between the XDG base directories and the github.com/spf13/afero filesystem.
+//
+// It is the separate package, so the xdgbasedir package itself stays dependency-free.
+package xdgafero // import "github.com/zchee/go-xdgbasedir/xdgafero"
@@ -0,0 +1,12 @@
+module github.com/zchee/go-xdgbasedir/xdgafero
+
+go 1.20
+
+require (
+	github.com/spf13/afero v1.11.0
+	github.com/zchee/go-xdgbasedir v0.0.0-00010101000000-000000000000
+)
+
+require golang.org/x/text v0.14.0 // indirect
+
+replace github.com/zchee/go-xdgbasedir => ../
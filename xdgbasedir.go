@@ -5,7 +5,9 @@
 package xdgbasedir
 
 import (
+	"errors"
 	"os"
+	"os/user"
 	"path/filepath"
 	"runtime"
 	"sync"
@@ -23,21 +25,45 @@ const (
 // Mode mode of directory structure. This config only available darwin.
 //
 // If it is set to `Unix`, it refers to the same path as linux. If it is set to `Native`, it refers to the Apple FileSystemProgrammingGuide path.
-// By default, `Unix`.
+// By default, `Unix`. Mode should be set before the first use of accessors.
 var Mode = Unix
 
-// initOnce for run initDir once on darwin.
-var initOnce sync.Once
+var (
+	// homeMu guards usrHome.
+	homeMu sync.Mutex
+	// usrHome is the cached user home directory for the default paths.
+	usrHome string
+)
+
+// The environment variable names which the package understands.
+const (
+	EnvDataHome   = "XDG_DATA_HOME"
+	EnvConfigHome = "XDG_CONFIG_HOME"
+	EnvDataDirs   = "XDG_DATA_DIRS"
+	EnvConfigDirs = "XDG_CONFIG_DIRS"
+	EnvCacheHome  = "XDG_CACHE_HOME"
+	EnvStateHome  = "XDG_STATE_HOME"
+	EnvBinHome    = "XDG_BIN_HOME"
+	EnvRuntimeDir = "XDG_RUNTIME_DIR"
+)
+
+// ErrNoHome is returned when the user home directory cannot be determined.
+var ErrNoHome = errors.New("xdg: cannot determine home directory")
 
 // DataHome return the XDG_DATA_HOME based directory path.
 //
 // $XDG_DATA_HOME defines the base directory relative to which user specific data files should be stored.
 // If $XDG_DATA_HOME is either not set or empty, a default equal to $HOME/.local/share should be used.
 func DataHome() string {
-	if env := os.Getenv("XDG_DATA_HOME"); env != "" {
-		return expandUser(env)
-	}
-	return dataHome()
+	return std.DataHome()
+}
+
+// AppDataHome returns the app directory path under the XDG_DATA_HOME, which is filepath.Join(DataHome(), app).
+//
+// The app is trimmed and validated same as the segment of NewApp. If app is not a valid application name, such as
+// containing the path separators or "..", AppDataHome returns the error instead of the DataHome itself.
+func AppDataHome(app string) (string, error) {
+	return appHome(DataHome(), app)
 }
 
 // ConfigHome return the XDG_CONFIG_HOME based directory path.
@@ -45,10 +71,14 @@ func DataHome() string {
 // $XDG_CONFIG_HOME defines the base directory relative to which user specific configuration files should be stored.
 // If $XDG_CONFIG_HOME is either not set or empty, a default equal to $HOME/.config should be used.
 func ConfigHome() string {
-	if env := os.Getenv("XDG_CONFIG_HOME"); env != "" {
-		return expandUser(env)
-	}
-	return configHome()
+	return std.ConfigHome()
+}
+
+// AppConfigHome returns the app directory path under the XDG_CONFIG_HOME, which is filepath.Join(ConfigHome(), app).
+//
+// If app is not a valid application name, AppConfigHome returns the error. See AppDataHome.
+func AppConfigHome(app string) (string, error) {
+	return appHome(ConfigHome(), app)
 }
 
 // DataDirs return the XDG_DATA_DIRS based directory path.
@@ -57,10 +87,23 @@ func ConfigHome() string {
 // to the $XDG_DATA_HOME base directory. The directories in $XDG_DATA_DIRS should be seperated with a colon ':'.
 // If $XDG_DATA_DIRS is either not set or empty, a value equal to /usr/local/share/:/usr/share/ should be used.
 func DataDirs() string {
-	if env := os.Getenv("XDG_DATA_DIRS"); env != "" {
-		return expandUser(env)
-	}
-	return dataDirs()
+	return std.DataDirs()
+}
+
+// DataDirsAll returns the preference-ordered list of data directories, which is the $XDG_DATA_HOME followed by
+// each entry of $XDG_DATA_DIRS.
+//
+// The empty and relative entries are skipped, and the duplicated entries are removed.
+func DataDirsAll() []string {
+	return std.DataDirsAll()
+}
+
+// ExistingDataDirs returns the entries of DataDirsAll which exist as the directory, in the same order, so the
+// callers avoid the futile opens in the data directories which can't have any resource.
+//
+// See ExistingConfigDirs for the semantics.
+func ExistingDataDirs() []string {
+	return std.ExistingDataDirs()
 }
 
 // ConfigDirs return the XDG_CONFIG_DIRS based directory path.
@@ -69,21 +112,89 @@ func DataDirs() string {
 // to the $XDG_CONFIG_HOME base directory. The directories in $XDG_CONFIG_DIRS should be seperated with a colon ':'.
 // If $XDG_CONFIG_DIRS is either not set or empty, a value equal to /etc/xdg should be used.
 func ConfigDirs() string {
-	if env := os.Getenv("XDG_CONFIG_DIRS"); env != "" {
-		return expandUser(env)
-	}
-	return configDirs()
+	return std.ConfigDirs()
+}
+
+// ConfigDirsAll returns the preference-ordered list of configuration directories, which is the $XDG_CONFIG_HOME
+// followed by each entry of $XDG_CONFIG_DIRS.
+//
+// The empty and relative entries are skipped, and the duplicated entries are removed.
+func ConfigDirsAll() []string {
+	return std.ConfigDirsAll()
+}
+
+// ExistingConfigDirs returns the entries of ConfigDirsAll which exist as the directory, in the same order,
+// such as for the diagnostics listing the real configuration locations.
+//
+// The entries which don't exist or exist but aren't directories are skipped. The symlinks are followed.
+func ExistingConfigDirs() []string {
+	return std.ExistingConfigDirs()
 }
 
 // CacheHome return the XDG_CACHE_HOME based directory path.
 //
 // $XDG_CACHE_HOME defines the base directory relative to which user specific non-essential data files should be stored.
 // If $XDG_CACHE_HOME is either not set or empty, a default equal to $HOME/.cache should be used.
+//
+// On darwin the default depends on Mode: $HOME/.cache for Unix, and $HOME/Library/Caches for Native.
+// Use NativeCacheHome to get the system cache directory regardless of Mode.
 func CacheHome() string {
-	if env := os.Getenv("XDG_CACHE_HOME"); env != "" {
-		return expandUser(env)
-	}
-	return cacheHome()
+	return std.CacheHome()
+}
+
+// AppCacheHome returns the app directory path under the XDG_CACHE_HOME, which is filepath.Join(CacheHome(), app).
+//
+// If app is not a valid application name, AppCacheHome returns the error. See AppDataHome.
+func AppCacheHome(app string) (string, error) {
+	return appHome(CacheHome(), app)
+}
+
+// StateHome return the XDG_STATE_HOME based directory path.
+//
+// $XDG_STATE_HOME defines the base directory relative to which user-specific state files should be stored.
+// The state data should persist between application restarts, but is not important or portable enough to the user
+// that it should be stored in $XDG_DATA_HOME, such as actions history and logs.
+// If $XDG_STATE_HOME is either not set or empty, a default equal to $HOME/.local/state should be used.
+func StateHome() string {
+	return std.StateHome()
+}
+
+// AppStateHome returns the app directory path under the XDG_STATE_HOME, which is filepath.Join(StateHome(), app).
+//
+// If app is not a valid application name, AppStateHome returns the error. See AppDataHome.
+func AppStateHome(app string) (string, error) {
+	return appHome(StateHome(), app)
+}
+
+// BinHome return the XDG_BIN_HOME based directory path.
+//
+// The specification says user-specific executable files may be stored in $HOME/.local/bin, and $XDG_BIN_HOME is
+// the widely used but unofficial variable to override it. If $XDG_BIN_HOME is either not set or empty,
+// a default equal to $HOME/.local/bin is used.
+func BinHome() string {
+	return std.BinHome()
+}
+
+// NativeConfigHome returns the platform native configuration directory by os.UserConfigDir, such as
+// $HOME/Library/Application Support on darwin and %AppData% on windows.
+//
+// It is the stdlib convention, and differs from the strict XDG ConfigHome on darwin and windows. It is useful for
+// the applications which let the users choose between the XDG and platform conventions. On the other platforms,
+// it is $XDG_CONFIG_HOME or $HOME/.config same as ConfigHome. It returns the error if neither is defined.
+func NativeConfigHome() (string, error) {
+	return os.UserConfigDir()
+}
+
+// NativeCacheHome returns the platform native cache directory by os.UserCacheDir, such as $HOME/Library/Caches
+// on darwin and %LocalAppData% on windows.
+//
+// With the default Mode Unix, CacheHome is $HOME/.cache on darwin, which is what the XDG aware tools expect, but
+// the files there are not purged by the system and are included in the backups. The applications which want the
+// darwin cache behavior without switching Mode for the other directories use NativeCacheHome instead. With Mode
+// Native, the defaults agree, but NativeCacheHome ignores $XDG_CACHE_HOME on darwin. On the other platforms, it is
+// $XDG_CACHE_HOME or $HOME/.cache same as CacheHome. It returns the error if neither is defined.
+func NativeCacheHome() (string, error) {
+	return os.UserCacheDir()
 }
 
 // RuntimeDir return the XDG_RUNTIME_DIR based directory path.
@@ -95,27 +206,128 @@ func CacheHome() string {
 // TODO(zchee): XDG_RUNTIME_DIR seems to change depending on the each distro or init system such as systemd.
 // Also In macOS, normal user haven't permission for write to this directory.
 // xref:
+//
 //	http://serverfault.com/questions/388840/good-default-for-xdg-runtime-dir/727994#727994
 func RuntimeDir() string {
-	if env := os.Getenv("XDG_RUNTIME_DIR"); env != "" {
-		return expandUser(env)
+	return std.RuntimeDir()
+}
+
+// Dump returns the effective values of all XDG base directories, including the defaults.
+//
+// The map is keyed by the canonical environment variable name such as "XDG_DATA_HOME".
+// It is useful for debugging output and bug reports.
+func Dump() map[string]string {
+	return map[string]string{
+		EnvDataHome:   DataHome(),
+		EnvConfigHome: ConfigHome(),
+		EnvDataDirs:   DataDirs(),
+		EnvConfigDirs: ConfigDirs(),
+		EnvCacheHome:  CacheHome(),
+		EnvStateHome:  StateHome(),
+		EnvBinHome:    BinHome(),
+		EnvRuntimeDir: RuntimeDir(),
+	}
+}
+
+// Refresh clears the cached user home directory, so the next accessor call re-reads the environment and user record.
+//
+// The user home directory is resolved at the first use of default paths and cached for subsequent calls.
+// Call Refresh after changing the environment, such as $HOME, in tests or daemons that re-exec. Refresh also
+// clears the user directories cached by the package level accessors such as DownloadDir, so the edited
+// user-dirs.dirs is read again.
+func Refresh() {
+	homeMu.Lock()
+	usrHome = ""
+	homeMu.Unlock()
+	std.userDirs.clear()
+}
+
+// Reset restores the package to its initial state, so the next call recomputes everything from scratch.
+//
+// It clears the cached user home directory same as Refresh, and also sets Mode back to the default Unix.
+// The directory lists are never cached, so they always reflect the current environment. Reset is primarily
+// for tests which change the environment and Mode between cases.
+func Reset() {
+	Refresh()
+	Mode = Unix
+}
+
+// userHome returns the cached user home directory resolved by HomeDir, or empty if it is not available.
+func userHome() string {
+	homeMu.Lock()
+	defer homeMu.Unlock()
+
+	if usrHome == "" {
+		// the error is ErrNoHome, and the caller handles the empty home same as HomeDir fails
+		usrHome, _ = HomeDir()
+	}
+	return usrHome
+}
+
+// userCurrent is the user.Current function. It is a variable for testing.
+var userCurrent = user.Current
+
+// HomeDir returns the user home directory.
+//
+// HomeDir checks the $HOME environment variable first ($USERPROFILE on windows, $home on plan9).
+// If it is either not set or empty, HomeDir falls back to the home directory of the current user record.
+// HomeDir returns ErrNoHome if neither is available. The error of user record lookup is retrievable via errors.Unwrap.
+func HomeDir() (string, error) {
+	if dir := os.Getenv(homeEnv()); dir != "" {
+		return dir, nil
+	}
+
+	u, err := userCurrent()
+	if err != nil {
+		return "", &homeError{err: err}
+	}
+	if u.HomeDir == "" {
+		return "", ErrNoHome
+	}
+	return u.HomeDir, nil
+}
+
+// homeError represents an error of the user home directory lookup. It matches to ErrNoHome.
+type homeError struct {
+	err error
+}
+
+// Error implements error.
+func (e *homeError) Error() string { return ErrNoHome.Error() + ": " + e.err.Error() }
+
+// Unwrap returns the underlying error.
+func (e *homeError) Unwrap() error { return e.err }
+
+// Is reports whether the target is ErrNoHome.
+func (e *homeError) Is(target error) bool { return target == ErrNoHome }
+
+// homeEnv returns the environment variable name of user home directory for the current GOOS.
+func homeEnv() string {
+	switch runtime.GOOS {
+	case "windows":
+		return "USERPROFILE"
+	case "plan9":
+		return "home"
+	default:
+		return "HOME"
 	}
-	return runtimeDir()
 }
 
 // expandUser expands shell's user home directory tilde expansion from s.
 func expandUser(s string) string {
+	return std.expandUser(s)
+}
+
+// expandUser expands shell's user home directory tilde expansion from s, using the environment of x.
+func (x *XDG) expandUser(s string) string {
 	if len(s) < 2 || s[0] != '~' || !os.IsPathSeparator(s[1]) {
 		return s
 	}
 
-	env := "HOME"
-	if runtime.GOOS == "windows" {
-		env = "USERPROFILE"
-	} else if runtime.GOOS == "plan9" {
-		env = "home"
+	home := x.homeDir
+	if home == "" {
+		home = x.getenv(homeEnv())
 	}
-	home := os.Getenv(env)
 	if home == "" {
 		return s
 	}
@@ -129,6 +341,6 @@ func expandUser(s string) string {
 		if env == "HOME" {
 			return home
 		}
-		return os.Getenv(env)
+		return x.getenv(env)
 	})
 }
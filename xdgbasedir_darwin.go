@@ -2,6 +2,7 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+//go:build darwin
 // +build darwin
 
 package xdgbasedir
@@ -10,67 +11,60 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
-
-	"github.com/zchee/go-xdgbasedir/home"
 )
 
-var (
-	defaultDataHome   string
-	defaultConfigHome string
-	defaultDataDirs   string
-	defaultConfigDirs string
-	defaultCacheHome  string
-	defaultRuntimeDir string
-)
+// ref: https://developer.apple.com/library/content/documentation/FileManagement/Conceptual/FileSystemProgrammingGuide/MacOSXDirectories/MacOSXDirectories.html
+
+func (x *XDG) defaultDataHome() string {
+	if Mode == Native {
+		return filepath.Join(x.home(), "Library", "Application Support")
+	}
+	return filepath.Join(x.home(), ".local", "share")
+}
 
-func initDir() {
-	initOnce.Do(func() {
-		switch Mode {
-		case Unix:
-			defaultDataHome = filepath.Join(home.Dir(), ".local", "share")
-			defaultConfigHome = filepath.Join(home.Dir(), ".config")
-			defaultDataDirs = filepath.Join("/usr", "local", "share") + string(filepath.ListSeparator) + filepath.Join("/usr", "share")
-			defaultConfigDirs = filepath.Join("/etc", "xdg")
-			defaultCacheHome = filepath.Join(home.Dir(), ".cache")
-			defaultRuntimeDir = filepath.Join("/run", "user", strconv.Itoa(os.Getuid()))
-		case Native:
-			// ref: https://developer.apple.com/library/content/documentation/FileManagement/Conceptual/FileSystemProgrammingGuide/MacOSXDirectories/MacOSXDirectories.html
-			defaultDataHome = filepath.Join(home.Dir(), "Library", "Application Support")
-			defaultConfigHome = filepath.Join(home.Dir(), "Library", "Preferences")
-			defaultDataDirs = defaultDataHome
-			defaultConfigDirs = defaultConfigHome
-			defaultCacheHome = filepath.Join(home.Dir(), "Library", "Caches")
-			defaultRuntimeDir = defaultDataHome
-		}
-	})
+func (x *XDG) defaultConfigHome() string {
+	if Mode == Native {
+		return filepath.Join(x.home(), "Library", "Preferences")
+	}
+	return filepath.Join(x.home(), ".config")
 }
 
-func dataHome() string {
-	initDir()
-	return defaultDataHome
+func (x *XDG) defaultDataDirs() string {
+	if Mode == Native {
+		return x.defaultDataHome()
+	}
+	return filepath.Join("/usr", "local", "share") + string(filepath.ListSeparator) + filepath.Join("/usr", "share")
 }
 
-func configHome() string {
-	initDir()
-	return defaultConfigHome
+func (x *XDG) defaultConfigDirs() string {
+	if Mode == Native {
+		return x.defaultConfigHome()
+	}
+	return filepath.Join("/etc", "xdg")
 }
 
-func dataDirs() string {
-	initDir()
-	return defaultDataDirs
+func (x *XDG) defaultCacheHome() string {
+	if Mode == Native {
+		return filepath.Join(x.home(), "Library", "Caches")
+	}
+	return filepath.Join(x.home(), ".cache")
 }
 
-func configDirs() string {
-	initDir()
-	return defaultConfigDirs
+func (x *XDG) defaultStateHome() string {
+	if Mode == Native {
+		return x.defaultDataHome()
+	}
+	return filepath.Join(x.home(), ".local", "state")
 }
 
-func cacheHome() string {
-	initDir()
-	return defaultCacheHome
+func (x *XDG) defaultBinHome() string {
+	// macOS has no native per-user executable directory
+	return filepath.Join(x.home(), ".local", "bin")
 }
 
-func runtimeDir() string {
-	initDir()
-	return defaultRuntimeDir
+func (x *XDG) defaultRuntimeDir() string {
+	if Mode == Native {
+		return x.defaultDataHome()
+	}
+	return filepath.Join("/run", "user", strconv.Itoa(os.Getuid()))
 }
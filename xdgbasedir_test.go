@@ -5,12 +5,14 @@
 package xdgbasedir
 
 import (
+	"errors"
 	"os"
 	"os/user"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strconv"
-	"sync"
+	"strings"
 	"testing"
 
 	"github.com/zchee/go-xdgbasedir/home"
@@ -217,6 +219,202 @@ func TestCacheHome(t *testing.T) {
 	}
 }
 
+func TestStateHome(t *testing.T) {
+	var testDefaultStateHome string
+	switch runtime.GOOS {
+	case "windows":
+		testDefaultStateHome = filepath.Join(home.Dir(), "AppData", "Local")
+	default:
+		testDefaultStateHome = filepath.Join(home.Dir(), ".local", "state")
+	}
+
+	tests := []struct {
+		name string
+		env  string
+		want string
+	}{
+		{
+			name: "set env based specification",
+			env:  testDefaultStateHome,
+			want: testDefaultStateHome,
+		},
+		{
+			name: "set env based different from specification",
+			env:  filepath.Join("/tmp", "state"),
+			want: filepath.Join("/tmp", "state"),
+		},
+		{
+			name: "empty env",
+			env:  "",
+			want: testDefaultStateHome,
+		},
+	}
+	for _, tt := range tests {
+		os.Setenv("XDG_STATE_HOME", tt.env)
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StateHome(); got != tt.want {
+				t.Errorf("StateHome() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNativeConfigHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(homeEnv(), home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("AppData", filepath.Join(home, "AppData", "Roaming"))
+	Refresh()
+	t.Cleanup(Refresh)
+
+	got, err := NativeConfigHome()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("NativeConfigHome() = %v, want %v", got, want)
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		if want := filepath.Join(home, "Library", "Application Support"); got != want {
+			t.Errorf("NativeConfigHome() = %v, want %v", got, want)
+		}
+	case "windows", "ios", "plan9":
+	default:
+		if got != ConfigHome() {
+			t.Errorf("NativeConfigHome() = %v, want ConfigHome() %v", got, ConfigHome())
+		}
+	}
+}
+
+func TestNativeCacheHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(homeEnv(), home)
+	t.Setenv("XDG_CACHE_HOME", "")
+	t.Setenv("LocalAppData", filepath.Join(home, "AppData", "Local"))
+	Refresh()
+	t.Cleanup(Refresh)
+
+	got, err := NativeCacheHome()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.UserCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("NativeCacheHome() = %v, want %v", got, want)
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		if want := filepath.Join(home, "Library", "Caches"); got != want {
+			t.Errorf("NativeCacheHome() = %v, want %v", got, want)
+		}
+		t.Cleanup(Reset)
+		Mode = Unix
+		if got == CacheHome() {
+			t.Errorf("Mode Unix: NativeCacheHome() = CacheHome() %v, want the different directory", got)
+		}
+		Mode = Native
+		if got != CacheHome() {
+			t.Errorf("Mode Native: NativeCacheHome() = %v, want CacheHome() %v", got, CacheHome())
+		}
+	case "windows", "ios", "plan9":
+	default:
+		if got != CacheHome() {
+			t.Errorf("NativeCacheHome() = %v, want CacheHome() %v", got, CacheHome())
+		}
+	}
+}
+
+func TestExistingDataDirs(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	local := filepath.Join(root, "usr", "local", "share")
+	system := filepath.Join(root, "usr", "share")
+	for _, dir := range []string{local, system} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("XDG_DATA_HOME", home)
+	t.Setenv("XDG_DATA_DIRS", strings.Join([]string{system, local}, string(filepath.ListSeparator)))
+
+	if got, want := ExistingDataDirs(), []string{system, local}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExistingDataDirs() = %v, want %v", got, want)
+	}
+}
+
+func TestExistingConfigDirs(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	missing := filepath.Join(root, "missing")
+	file := filepath.Join(root, "file")
+	local := filepath.Join(root, "etc", "local")
+	etc := filepath.Join(root, "etc", "xdg")
+	for _, dir := range []string{home, local, etc} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_CONFIG_DIRS", strings.Join([]string{etc, missing, file, local}, string(filepath.ListSeparator)))
+
+	if got, want := ExistingConfigDirs(), []string{home, etc, local}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExistingConfigDirs() = %v, want %v", got, want)
+	}
+}
+
+func TestBinHome(t *testing.T) {
+	var testDefaultBinHome string
+	switch runtime.GOOS {
+	case "windows":
+		testDefaultBinHome = filepath.Join(home.Dir(), "AppData", "Local", "Programs")
+	default:
+		testDefaultBinHome = filepath.Join(home.Dir(), ".local", "bin")
+	}
+
+	tests := []struct {
+		name string
+		env  string
+		want string
+	}{
+		{
+			name: "set env based specification",
+			env:  testDefaultBinHome,
+			want: testDefaultBinHome,
+		},
+		{
+			name: "set env based different from specification",
+			env:  filepath.Join("/tmp", "bin"),
+			want: filepath.Join("/tmp", "bin"),
+		},
+		{
+			name: "empty env",
+			env:  "",
+			want: testDefaultBinHome,
+		},
+	}
+	for _, tt := range tests {
+		t.Setenv(EnvBinHome, tt.env)
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BinHome(); got != tt.want {
+				t.Errorf("BinHome() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRuntimeDir(t *testing.T) {
 	var testDefaultRuntimeDir string
 	switch runtime.GOOS {
@@ -264,7 +462,7 @@ func TestNativeMode(t *testing.T) {
 	}
 
 	Mode = Native
-	initOnce = sync.Once{}
+	defer func() { Mode = Unix }()
 
 	tests := []struct {
 		name string
@@ -296,6 +494,11 @@ func TestNativeMode(t *testing.T) {
 			fn:   CacheHome(),
 			want: filepath.Join(home.Dir(), "Library", "Caches"),
 		},
+		{
+			name: "StateHome",
+			fn:   StateHome(),
+			want: filepath.Join(home.Dir(), "Library", "Application Support"),
+		},
 		{
 			name: "RuntimeDir",
 			fn:   RuntimeDir(),
@@ -311,6 +514,237 @@ func TestNativeMode(t *testing.T) {
 	}
 }
 
+func TestDump(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Cleanup(Refresh)
+	Refresh()
+
+	want := map[string]string{
+		"XDG_DATA_HOME":   filepath.Join(root, "data"),
+		"XDG_CONFIG_HOME": filepath.Join(root, "config"),
+		"XDG_DATA_DIRS":   filepath.Join(root, "share1") + string(filepath.ListSeparator) + filepath.Join(root, "share2"),
+		"XDG_CONFIG_DIRS": filepath.Join(root, "etc"),
+		"XDG_CACHE_HOME":  filepath.Join(root, "cache"),
+		"XDG_STATE_HOME":  filepath.Join(root, "state"),
+		"XDG_BIN_HOME":    filepath.Join(root, "bin"),
+		"XDG_RUNTIME_DIR": filepath.Join(root, "run"),
+	}
+	for env, dir := range want {
+		t.Setenv(env, dir)
+	}
+	if runtime.GOOS != "windows" {
+		// the unset variable is dumped as the default
+		t.Setenv("XDG_CACHE_HOME", "")
+		want["XDG_CACHE_HOME"] = filepath.Join(home, ".cache")
+	}
+
+	if got := Dump(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Dump() = %v, want %v", got, want)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Cleanup(Refresh)
+
+	before := filepath.Join(t.TempDir(), "before")
+	t.Setenv("HOME", before)
+	t.Setenv("USERPROFILE", before)
+	Refresh()
+	if got, want := ConfigHome(), std.defaultConfigHome(); got != want {
+		t.Fatalf("ConfigHome() = %v, want %v", got, want)
+	}
+	if got := userHome(); got != before {
+		t.Fatalf("userHome() = %v, want %v", got, before)
+	}
+
+	after := filepath.Join(t.TempDir(), "after")
+	t.Setenv("HOME", after)
+	t.Setenv("USERPROFILE", after)
+	if got := userHome(); got != before {
+		t.Errorf("userHome() before Refresh = %v, want cached %v", got, before)
+	}
+
+	Refresh()
+	if got := userHome(); got != after {
+		t.Errorf("userHome() after Refresh = %v, want %v", got, after)
+	}
+	if runtime.GOOS != "windows" {
+		if got, want := ConfigHome(), filepath.Join(after, ".config"); got != want {
+			t.Errorf("ConfigHome() after Refresh = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestReset(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Cleanup(Reset)
+
+	var got []string
+	for _, dir := range []string{"first", "second"} {
+		home := filepath.Join(t.TempDir(), dir)
+		t.Setenv("HOME", home)
+		t.Setenv("USERPROFILE", home)
+		Mode = Native
+
+		Reset()
+		if Mode != Unix {
+			t.Errorf("Mode after Reset = %v, want Unix", Mode)
+		}
+		if h := userHome(); h != home {
+			t.Errorf("userHome() after Reset = %v, want %v", h, home)
+		}
+		got = append(got, ConfigHome())
+	}
+	if runtime.GOOS != "windows" && got[0] == got[1] {
+		t.Errorf("ConfigHome() = %v for both HOME", got[0])
+	}
+}
+
+func TestHomeDir(t *testing.T) {
+	env := homeEnv()
+	defer func() { userCurrent = user.Current }()
+
+	tests := []struct {
+		name    string
+		env     string
+		usr     func() (*user.User, error)
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "set env",
+			env:  filepath.Join("/tmp", "home"),
+			usr:  func() (*user.User, error) { return nil, errors.New("should not be called") },
+			want: filepath.Join("/tmp", "home"),
+		},
+		{
+			name: "empty env with user record",
+			env:  "",
+			usr:  func() (*user.User, error) { return &user.User{HomeDir: filepath.Join("/home", "gopher")}, nil },
+			want: filepath.Join("/home", "gopher"),
+		},
+		{
+			name:    "empty env and no user record",
+			env:     "",
+			usr:     func() (*user.User, error) { return nil, user.UnknownUserIdError(1000) },
+			wantErr: true,
+		},
+		{
+			name:    "empty env and empty user home",
+			env:     "",
+			usr:     func() (*user.User, error) { return &user.User{}, nil },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(env, tt.env)
+			if tt.env == "" {
+				os.Unsetenv(env)
+			}
+			userCurrent = tt.usr
+
+			got, err := HomeDir()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HomeDir() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrNoHome) {
+				t.Errorf("HomeDir() error = %v, want ErrNoHome", err)
+			}
+			if got != tt.want {
+				t.Errorf("HomeDir() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHomeDirUnwrap(t *testing.T) {
+	env := homeEnv()
+	t.Setenv(env, "")
+	os.Unsetenv(env)
+
+	lookupErr := user.UnknownUserIdError(1000)
+	userCurrent = func() (*user.User, error) { return nil, lookupErr }
+	defer func() { userCurrent = user.Current }()
+
+	_, err := HomeDir()
+	if !errors.Is(err, ErrNoHome) {
+		t.Errorf("HomeDir() error = %v, want ErrNoHome", err)
+	}
+	if got := errors.Unwrap(err); got != lookupErr {
+		t.Errorf("errors.Unwrap(HomeDir()) = %v, want %v", got, lookupErr)
+	}
+}
+
+func TestUserHomeNoHome(t *testing.T) {
+	env := homeEnv()
+	t.Setenv(env, "")
+	os.Unsetenv(env)
+	userCurrent = func() (*user.User, error) { return nil, user.UnknownUserIdError(1000) }
+	defer func() { userCurrent = user.Current }()
+	Refresh()
+	t.Cleanup(Refresh)
+
+	// userHome agrees with HomeDir on when the home directory is not available
+	if got := userHome(); got != "" {
+		t.Errorf("userHome() = %q, want empty", got)
+	}
+	if _, err := HomeDir(); !errors.Is(err, ErrNoHome) {
+		t.Errorf("HomeDir() error = %v, want ErrNoHome", err)
+	}
+}
+
+func TestAppHome(t *testing.T) {
+	dataHome := filepath.Join("/tmp", "data")
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	configHome := filepath.Join("/tmp", "config")
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	cacheHome := filepath.Join("/tmp", "cache")
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+	stateHome := filepath.Join("/tmp", "state")
+	t.Setenv("XDG_STATE_HOME", stateHome)
+
+	tests := []struct {
+		name string
+		fn   func(app string) (string, error)
+		app  string
+		want string
+	}{
+		{name: "AppDataHome", fn: AppDataHome, app: "myapp", want: filepath.Join(dataHome, "myapp")},
+		{name: "AppDataHome trimmed", fn: AppDataHome, app: " myapp\t", want: filepath.Join(dataHome, "myapp")},
+		{name: "AppDataHome separator", fn: AppDataHome, app: "my/app"},
+		{name: "AppDataHome traversal", fn: AppDataHome, app: ".."},
+		{name: "AppDataHome empty", fn: AppDataHome, app: ""},
+		{name: "AppDataHome blank", fn: AppDataHome, app: "  "},
+		{name: "AppConfigHome", fn: AppConfigHome, app: "myapp", want: filepath.Join(configHome, "myapp")},
+		{name: "AppConfigHome separator", fn: AppConfigHome, app: `my\app`},
+		{name: "AppConfigHome traversal", fn: AppConfigHome, app: " .. "},
+		{name: "AppCacheHome", fn: AppCacheHome, app: "myapp", want: filepath.Join(cacheHome, "myapp")},
+		{name: "AppCacheHome separator", fn: AppCacheHome, app: "../myapp"},
+		{name: "AppCacheHome current", fn: AppCacheHome, app: "."},
+		{name: "AppStateHome", fn: AppStateHome, app: "myapp", want: filepath.Join(stateHome, "myapp")},
+		{name: "AppStateHome separator", fn: AppStateHome, app: "logs/myapp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fn(tt.app)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("app %q = %v, want error", tt.app, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("app %q = %v, %v, want %v", tt.app, got, err, tt.want)
+			}
+		})
+	}
+}
+
 func Test_expandUser(t *testing.T) {
 	usr, err := user.Current()
 	if err != nil {
@@ -385,6 +819,18 @@ func BenchmarkCacheHome(b *testing.B) {
 	}
 }
 
+func BenchmarkStateHome(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		StateHome()
+	}
+}
+
+func BenchmarkBinHome(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		BinHome()
+	}
+}
+
 func BenchmarkRuntimeDir(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		RuntimeDir()
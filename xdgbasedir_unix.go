@@ -2,8 +2,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// +build !darwin
-// +build !windows
+//go:build !darwin && !windows
+// +build !darwin,!windows
 
 package xdgbasedir
 
@@ -11,39 +11,36 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
-
-	"github.com/zchee/go-xdgbasedir/home"
 )
 
-var (
-	defaultDataHome   = filepath.Join(home.Dir(), ".local", "share")
-	defaultConfigHome = filepath.Join(home.Dir(), ".config")
-	defaultDataDirs   = filepath.Join("/usr", "local", "share") + string(filepath.ListSeparator) + filepath.Join("/usr", "share")
-	defaultConfigDirs = filepath.Join("/etc", "xdg")
-	defaultCacheHome  = filepath.Join(home.Dir(), ".cache")
-	defaultRuntimeDir = filepath.Join("/run", "user", strconv.Itoa(os.Getuid()))
-)
+func (x *XDG) defaultDataHome() string {
+	return filepath.Join(x.home(), ".local", "share")
+}
+
+func (x *XDG) defaultConfigHome() string {
+	return filepath.Join(x.home(), ".config")
+}
 
-func dataHome() string {
-	return defaultDataHome
+func (x *XDG) defaultDataDirs() string {
+	return filepath.Join("/usr", "local", "share") + string(filepath.ListSeparator) + filepath.Join("/usr", "share")
 }
 
-func configHome() string {
-	return defaultConfigHome
+func (x *XDG) defaultConfigDirs() string {
+	return filepath.Join("/etc", "xdg")
 }
 
-func dataDirs() string {
-	return defaultDataDirs
+func (x *XDG) defaultCacheHome() string {
+	return filepath.Join(x.home(), ".cache")
 }
 
-func configDirs() string {
-	return defaultConfigDirs
+func (x *XDG) defaultStateHome() string {
+	return filepath.Join(x.home(), ".local", "state")
 }
 
-func cacheHome() string {
-	return defaultCacheHome
+func (x *XDG) defaultBinHome() string {
+	return filepath.Join(x.home(), ".local", "bin")
 }
 
-func runtimeDir() string {
-	return defaultRuntimeDir
+func (x *XDG) defaultRuntimeDir() string {
+	return filepath.Join("/run", "user", strconv.Itoa(os.Getuid()))
 }
@@ -2,48 +2,51 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+//go:build windows
 // +build windows
 
 package xdgbasedir
 
 import (
-	"os"
 	"path/filepath"
-
-	"github.com/zchee/go-xdgbasedir/home"
 )
 
-var (
-	appData           = filepath.FromSlash(os.Getenv("APPDATA"))
-	localAppData      = filepath.FromSlash(os.Getenv("LOCALAPPDATA"))
-	defaultDataHome   = appData
-	defaultConfigHome = appData
-	defaultDataDirs   = appData
-	defaultConfigDirs = appData
-	defaultCacheHome  = filepath.Join(localAppData, "cache")
-	defaultRuntimeDir = home.Dir()
-)
+func (x *XDG) appData() string {
+	return filepath.FromSlash(x.getenv("APPDATA"))
+}
+
+func (x *XDG) localAppData() string {
+	return filepath.FromSlash(x.getenv("LOCALAPPDATA"))
+}
+
+func (x *XDG) defaultDataHome() string {
+	return x.appData()
+}
+
+func (x *XDG) defaultConfigHome() string {
+	return x.appData()
+}
 
-func dataHome() string {
-	return defaultDataHome
+func (x *XDG) defaultDataDirs() string {
+	return x.appData()
 }
 
-func configHome() string {
-	return defaultConfigHome
+func (x *XDG) defaultConfigDirs() string {
+	return x.appData()
 }
 
-func dataDirs() string {
-	return defaultDataDirs
+func (x *XDG) defaultCacheHome() string {
+	return filepath.Join(x.localAppData(), "cache")
 }
 
-func configDirs() string {
-	return defaultConfigDirs
+func (x *XDG) defaultStateHome() string {
+	return x.localAppData()
 }
 
-func cacheHome() string {
-	return defaultCacheHome
+func (x *XDG) defaultBinHome() string {
+	return filepath.Join(x.localAppData(), "Programs")
 }
 
-func runtimeDir() string {
-	return defaultRuntimeDir
+func (x *XDG) defaultRuntimeDir() string {
+	return x.home()
 }
@@ -0,0 +1,6 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package xdgtest implements the test helpers for the code which uses the XDG base directories.
+package xdgtest // import "github.com/zchee/go-xdgbasedir/xdgtest"
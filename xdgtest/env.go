@@ -0,0 +1,74 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgtest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	xdgbasedir "github.com/zchee/go-xdgbasedir"
+)
+
+// SetDataHome sets $XDG_DATA_HOME to dir for the duration of the test t.
+func SetDataHome(t testing.TB, dir string) {
+	t.Helper()
+	t.Setenv(xdgbasedir.EnvDataHome, dir)
+}
+
+// SetConfigHome sets $XDG_CONFIG_HOME to dir for the duration of the test t.
+func SetConfigHome(t testing.TB, dir string) {
+	t.Helper()
+	t.Setenv(xdgbasedir.EnvConfigHome, dir)
+}
+
+// SetCacheHome sets $XDG_CACHE_HOME to dir for the duration of the test t.
+func SetCacheHome(t testing.TB, dir string) {
+	t.Helper()
+	t.Setenv(xdgbasedir.EnvCacheHome, dir)
+}
+
+// SetStateHome sets $XDG_STATE_HOME to dir for the duration of the test t.
+func SetStateHome(t testing.TB, dir string) {
+	t.Helper()
+	t.Setenv(xdgbasedir.EnvStateHome, dir)
+}
+
+// SetBinHome sets $XDG_BIN_HOME to dir for the duration of the test t.
+func SetBinHome(t testing.TB, dir string) {
+	t.Helper()
+	t.Setenv(xdgbasedir.EnvBinHome, dir)
+}
+
+// SetRuntimeDir sets $XDG_RUNTIME_DIR to dir for the duration of the test t.
+func SetRuntimeDir(t testing.TB, dir string) {
+	t.Helper()
+	t.Setenv(xdgbasedir.EnvRuntimeDir, dir)
+}
+
+// SetDataDirs sets $XDG_DATA_DIRS to dirs joined by the os.PathListSeparator for the duration of the test t.
+func SetDataDirs(t testing.TB, dirs ...string) {
+	t.Helper()
+	t.Setenv(xdgbasedir.EnvDataDirs, strings.Join(dirs, string(filepath.ListSeparator)))
+}
+
+// SetConfigDirs sets $XDG_CONFIG_DIRS to dirs joined by the os.PathListSeparator for the duration of the test t.
+func SetConfigDirs(t testing.TB, dirs ...string) {
+	t.Helper()
+	t.Setenv(xdgbasedir.EnvConfigDirs, strings.Join(dirs, string(filepath.ListSeparator)))
+}
+
+// Unsetenv unsets the keys environment variables for the duration of the test t, such as to test the defaults.
+func Unsetenv(t testing.TB, keys ...string) {
+	t.Helper()
+	for _, key := range keys {
+		// t.Setenv registers the cleanup which restores the prior value, or unsets it if it was not set
+		t.Setenv(key, "")
+		if err := os.Unsetenv(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	xdgbasedir "github.com/zchee/go-xdgbasedir"
+)
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		set  func(testing.TB, string)
+		get  func() string
+	}{
+		{name: "DataHome", key: xdgbasedir.EnvDataHome, set: SetDataHome, get: xdgbasedir.DataHome},
+		{name: "ConfigHome", key: xdgbasedir.EnvConfigHome, set: SetConfigHome, get: xdgbasedir.ConfigHome},
+		{name: "CacheHome", key: xdgbasedir.EnvCacheHome, set: SetCacheHome, get: xdgbasedir.CacheHome},
+		{name: "StateHome", key: xdgbasedir.EnvStateHome, set: SetStateHome, get: xdgbasedir.StateHome},
+		{name: "BinHome", key: xdgbasedir.EnvBinHome, set: SetBinHome, get: xdgbasedir.BinHome},
+		{name: "RuntimeDir", key: xdgbasedir.EnvRuntimeDir, set: SetRuntimeDir, get: xdgbasedir.RuntimeDir},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, prior := range []struct {
+				value string
+				ok    bool
+			}{
+				{value: "", ok: false},
+				{value: "", ok: true},
+				{value: "/prior", ok: true},
+			} {
+				// the outer Setenv restores the real environment after the test
+				t.Setenv(tt.key, "")
+				if prior.ok {
+					os.Setenv(tt.key, prior.value)
+				} else {
+					os.Unsetenv(tt.key)
+				}
+
+				dir := filepath.Join(t.TempDir(), "dir")
+				t.Run("set", func(t *testing.T) {
+					tt.set(t, dir)
+					if got := tt.get(); got != dir {
+						t.Errorf("%s() = %q, want %q", tt.name, got, dir)
+					}
+				})
+
+				value, ok := os.LookupEnv(tt.key)
+				if value != prior.value || ok != prior.ok {
+					t.Errorf("restored %s = %q, %v, want %q, %v", tt.key, value, ok, prior.value, prior.ok)
+				}
+			}
+		})
+	}
+}
+
+func TestSetDirs(t *testing.T) {
+	dirs := []string{filepath.Join(t.TempDir(), "a"), filepath.Join(t.TempDir(), "b")}
+
+	t.Run("set", func(t *testing.T) {
+		SetDataDirs(t, dirs...)
+		SetConfigDirs(t, dirs...)
+
+		if got := xdgbasedir.New().DataDirsAll(); len(got) != 3 || got[1] != dirs[0] || got[2] != dirs[1] {
+			t.Errorf("DataDirsAll() = %v, want $XDG_DATA_HOME and %v", got, dirs)
+		}
+		if got := xdgbasedir.New().ConfigDirsAll(); len(got) != 3 || got[1] != dirs[0] || got[2] != dirs[1] {
+			t.Errorf("ConfigDirsAll() = %v, want $XDG_CONFIG_HOME and %v", got, dirs)
+		}
+	})
+}
+
+func TestUnsetenv(t *testing.T) {
+	t.Setenv(xdgbasedir.EnvCacheHome, "/prior")
+
+	t.Run("unset", func(t *testing.T) {
+		Unsetenv(t, xdgbasedir.EnvCacheHome)
+		if _, ok := os.LookupEnv(xdgbasedir.EnvCacheHome); ok {
+			t.Errorf("%s is set", xdgbasedir.EnvCacheHome)
+		}
+	})
+
+	if got := os.Getenv(xdgbasedir.EnvCacheHome); got != "/prior" {
+		t.Errorf("restored %s = %q, want %q", xdgbasedir.EnvCacheHome, got, "/prior")
+	}
+}
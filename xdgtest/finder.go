@@ -0,0 +1,58 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgtest
+
+import (
+	xdgbasedir "github.com/zchee/go-xdgbasedir"
+)
+
+// MapFinder is the xdgbasedir.Finder backed by the in-memory maps, for the unit tests of the code which accepts
+// xdgbasedir.Finder, or for xdgbasedir.WithFinder. It never touches the filesystem or the environment.
+//
+// Data and Config map the searched relative path, such as "myapp/config.toml", to the existing file paths in
+// the search order, most important first:
+//
+//	f := xdgtest.MapFinder{
+//		Config: map[string][]string{
+//			"myapp/config.toml": {"/home/gopher/.config/myapp/config.toml", "/etc/xdg/myapp/config.toml"},
+//		},
+//	}
+//
+// The relative path is matched as is, not cleaned. The path not in the map is not found, and the Search*File
+// methods return the *xdgbasedir.NotFoundError same as xdgbasedir.XDG. The zero MapFinder finds nothing.
+type MapFinder struct {
+	Data   map[string][]string
+	Config map[string][]string
+}
+
+var _ xdgbasedir.Finder = MapFinder{}
+
+// SearchDataFile returns the first path of rel in Data.
+func (f MapFinder) SearchDataFile(rel string) (string, error) {
+	return searchMap(f.Data, rel)
+}
+
+// SearchConfigFile returns the first path of rel in Config.
+func (f MapFinder) SearchConfigFile(rel string) (string, error) {
+	return searchMap(f.Config, rel)
+}
+
+// FindDataFiles returns the copy of all paths of rel in Data.
+func (f MapFinder) FindDataFiles(rel string) ([]string, error) {
+	return append([]string(nil), f.Data[rel]...), nil
+}
+
+// FindConfigFiles returns the copy of all paths of rel in Config.
+func (f MapFinder) FindConfigFiles(rel string) ([]string, error) {
+	return append([]string(nil), f.Config[rel]...), nil
+}
+
+// searchMap returns the first path of rel in m.
+func searchMap(m map[string][]string, rel string) (string, error) {
+	if paths := m[rel]; len(paths) > 0 {
+		return paths[0], nil
+	}
+	return "", &xdgbasedir.NotFoundError{Name: rel}
+}
@@ -0,0 +1,125 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgtest
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"testing"
+
+	xdgbasedir "github.com/zchee/go-xdgbasedir"
+)
+
+func TestMapFinder(t *testing.T) {
+	var f xdgbasedir.Finder = MapFinder{
+		Data: map[string][]string{
+			"mime/globs2": {"/usr/share/mime/globs2"},
+		},
+		Config: map[string][]string{
+			"myapp/config.toml": {"/home/gopher/.config/myapp/config.toml", "/etc/xdg/myapp/config.toml"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		search  func(string) (string, error)
+		all     func(string) ([]string, error)
+		rel     string
+		want    string
+		wantAll []string
+	}{
+		{
+			name:    "config",
+			search:  f.SearchConfigFile,
+			all:     f.FindConfigFiles,
+			rel:     "myapp/config.toml",
+			want:    "/home/gopher/.config/myapp/config.toml",
+			wantAll: []string{"/home/gopher/.config/myapp/config.toml", "/etc/xdg/myapp/config.toml"},
+		},
+		{
+			name:    "data",
+			search:  f.SearchDataFile,
+			all:     f.FindDataFiles,
+			rel:     "mime/globs2",
+			want:    "/usr/share/mime/globs2",
+			wantAll: []string{"/usr/share/mime/globs2"},
+		},
+		{
+			name:   "config not found",
+			search: f.SearchConfigFile,
+			all:    f.FindConfigFiles,
+			rel:    "mime/globs2",
+		},
+		{
+			name:   "data not found",
+			search: f.SearchDataFile,
+			all:    f.FindDataFiles,
+			rel:    "myapp/config.toml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.search(tt.rel)
+			if tt.want == "" {
+				var nf *xdgbasedir.NotFoundError
+				if !errors.Is(err, fs.ErrNotExist) || !errors.As(err, &nf) || nf.Name != tt.rel {
+					t.Errorf("search(%q) error = %v, want *NotFoundError", tt.rel, err)
+				}
+			} else if err != nil || got != tt.want {
+				t.Errorf("search(%q) = %q, %v, want %q", tt.rel, got, err, tt.want)
+			}
+
+			all, err := tt.all(tt.rel)
+			if err != nil || !reflect.DeepEqual(all, tt.wantAll) {
+				t.Errorf("all(%q) = %v, %v, want %v", tt.rel, all, err, tt.wantAll)
+			}
+		})
+	}
+
+	// the result is the copy
+	all, _ := f.FindConfigFiles("myapp/config.toml")
+	all[0] = "modified"
+	if got, _ := f.SearchConfigFile("myapp/config.toml"); got == "modified" {
+		t.Error("FindConfigFiles() result shares the map")
+	}
+}
+
+func TestMapFinderApp(t *testing.T) {
+	f := MapFinder{
+		Data: map[string][]string{
+			"myapp/themes/dark.yaml": {"/usr/share/myapp/themes/dark.yaml"},
+		},
+		Config: map[string][]string{
+			"myapp/config.toml": {"/home/gopher/.config/myapp/config.toml", "/etc/xdg/myapp/config.toml"},
+		},
+	}
+	app, err := xdgbasedir.New(xdgbasedir.WithFinder(f)).App("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := app.SearchConfigFile("config.toml"); err != nil || got != "/home/gopher/.config/myapp/config.toml" {
+		t.Errorf("SearchConfigFile() = %q, %v", got, err)
+	}
+	if got, err := app.FindConfigFiles("./config.toml"); err != nil || !reflect.DeepEqual(got, f.Config["myapp/config.toml"]) {
+		t.Errorf("FindConfigFiles() = %q, %v, want %q", got, err, f.Config["myapp/config.toml"])
+	}
+	if got, err := app.SearchDataFile("themes/dark.yaml"); err != nil || got != "/usr/share/myapp/themes/dark.yaml" {
+		t.Errorf("SearchDataFile() = %q, %v", got, err)
+	}
+	if got, err := app.FindDataFiles("missing"); err != nil || len(got) != 0 {
+		t.Errorf("FindDataFiles(missing) = %q, %v, want empty", got, err)
+	}
+	if _, err := app.SearchConfigFile("missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("SearchConfigFile(missing) error = %v, want fs.ErrNotExist", err)
+	}
+	if _, err := app.SearchConfigFile("../escape"); err == nil {
+		t.Error("SearchConfigFile(../escape) expected error")
+	}
+
+	// App is the Finder scoped to the application directory
+	var _ xdgbasedir.Finder = app
+}
@@ -0,0 +1,76 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgtest
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	xdgbasedir "github.com/zchee/go-xdgbasedir"
+)
+
+// Setup stands up the temporary fake XDG environment for the integration tests of the code which uses the XDG
+// paths, and returns the XDG which resolves it and the cleanup func.
+//
+// Setup creates the data, config, cache, state, bin and runtime directories, and the single entry data and config
+// search directories under the new temporary directory, and points the XDG environment variables at them.
+// The runtime directory is created with 0700, so the strict runtime helpers accept it. The returned XDG resolves
+// the home directory to the "home" directory under the same temporary directory, so the defaults never refer
+// to the real home directory.
+//
+// The cleanup restores the prior environment variables precisely and removes the temporary directory. It is also
+// registered by t.Cleanup, so calling it is only needed to tear down the environment before the test ends.
+// Same as t.Setenv, Setup must not be used in the parallel tests.
+func Setup(t testing.TB) (*xdgbasedir.XDG, func()) {
+	t.Helper()
+
+	root, err := os.MkdirTemp("", "xdgtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var once sync.Once
+	snapshot := xdgbasedir.Snapshot()
+	cleanup := func() {
+		once.Do(func() {
+			snapshot.Restore()
+			os.RemoveAll(root)
+		})
+	}
+	t.Cleanup(cleanup)
+
+	for _, env := range []struct {
+		key string
+		dir string
+	}{
+		{key: xdgbasedir.EnvDataHome, dir: "data"},
+		{key: xdgbasedir.EnvConfigHome, dir: "config"},
+		{key: xdgbasedir.EnvCacheHome, dir: "cache"},
+		{key: xdgbasedir.EnvStateHome, dir: "state"},
+		{key: xdgbasedir.EnvBinHome, dir: "bin"},
+		{key: xdgbasedir.EnvRuntimeDir, dir: "runtime"},
+		{key: xdgbasedir.EnvDataDirs, dir: "share"},
+		{key: xdgbasedir.EnvConfigDirs, dir: "etc"},
+	} {
+		dir := filepath.Join(root, env.dir)
+		if err := os.Mkdir(dir, 0700); err != nil {
+			cleanup()
+			t.Fatal(err)
+		}
+		if err := os.Setenv(env.key, dir); err != nil {
+			cleanup()
+			t.Fatal(err)
+		}
+	}
+
+	home := filepath.Join(root, "home")
+	if err := os.Mkdir(home, 0700); err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	return xdgbasedir.New(xdgbasedir.WithHome(home)), cleanup
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgtest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	xdgbasedir "github.com/zchee/go-xdgbasedir"
+)
+
+func TestSetup(t *testing.T) {
+	// the outer Setenv restores the real environment after the test
+	t.Setenv(xdgbasedir.EnvConfigHome, "/prior")
+	t.Setenv(xdgbasedir.EnvDataHome, "")
+	os.Unsetenv(xdgbasedir.EnvDataHome)
+
+	x, cleanup := Setup(t)
+	root := filepath.Dir(x.ConfigHome())
+
+	for name, dir := range map[string]string{
+		"DataHome":   x.DataHome(),
+		"ConfigHome": x.ConfigHome(),
+		"CacheHome":  x.CacheHome(),
+		"StateHome":  x.StateHome(),
+		"BinHome":    x.BinHome(),
+		"RuntimeDir": x.RuntimeDir(),
+	} {
+		if !strings.HasPrefix(dir, root) {
+			t.Errorf("%s() = %q, want under %q", name, dir, root)
+		}
+		if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+			t.Errorf("%s() %q is not created: %v", name, dir, err)
+		}
+	}
+	if got := xdgbasedir.ConfigHome(); got != x.ConfigHome() {
+		t.Errorf("package level ConfigHome() = %q, want %q", got, x.ConfigHome())
+	}
+
+	app, err := x.App("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.RuntimeFile("app.sock"); err != nil {
+		t.Errorf("RuntimeFile() error = %v", err)
+	}
+
+	cleanup()
+	cleanup()
+
+	if got := os.Getenv(xdgbasedir.EnvConfigHome); got != "/prior" {
+		t.Errorf("restored %s = %q, want %q", xdgbasedir.EnvConfigHome, got, "/prior")
+	}
+	if _, ok := os.LookupEnv(xdgbasedir.EnvDataHome); ok {
+		t.Errorf("restored %s is set", xdgbasedir.EnvDataHome)
+	}
+	if _, err := os.Stat(root); !os.IsNotExist(err) {
+		t.Errorf("temporary directory %q is not removed: %v", root, err)
+	}
+}
@@ -0,0 +1,13 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package xdgwatch delivers the change events of the resolved XDG configuration files, such as to reload
+// "myapp/config.toml" as soon as the user saves it.
+//
+// WatchConfig resolves the file by the search order of xdgbasedir.SearchConfigFile, and reports its Modified and
+// Removed events as the kernel notifies them via inotify, kqueue or ReadDirectoryChangesW. Those notification APIs
+// are reached through github.com/fsnotify/fsnotify, which is why the package is its own module. Use the polling
+// xdgbasedir.App.Watch instead to follow the shadowing across the configuration directories, or to watch a file
+// which does not exist yet.
+package xdgwatch // import "github.com/zchee/go-xdgbasedir/xdgwatch"
@@ -0,0 +1,12 @@
+module github.com/zchee/go-xdgbasedir/xdgwatch
+
+go 1.20
+
+require (
+	github.com/fsnotify/fsnotify v1.7.0
+	github.com/zchee/go-xdgbasedir v0.0.0-00010101000000-000000000000
+)
+
+require golang.org/x/sys v0.15.0 // indirect
+
+replace github.com/zchee/go-xdgbasedir => ../
@@ -0,0 +1,133 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgwatch
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+
+	xdgbasedir "github.com/zchee/go-xdgbasedir"
+)
+
+// Op is the kind of change of the watched file.
+type Op int
+
+const (
+	// Modified means the file is written, or created again such as by the atomic save which renames the temporary
+	// file over the path.
+	Modified Op = iota + 1
+	// Removed means the file is removed or renamed away.
+	Removed
+)
+
+// String implements fmt.Stringer.
+func (op Op) String() string {
+	switch op {
+	case Modified:
+		return "Modified"
+	case Removed:
+		return "Removed"
+	}
+	return "Op(0)"
+}
+
+// Event is the change of the watched configuration file.
+type Event struct {
+	// Path is the watched file path.
+	Path string
+	// Op is the kind of change. It is zero if Err is set.
+	Op Op
+	// Err is the error of the underlying watcher, such as the event queue overflow.
+	Err error
+}
+
+// WatchConfig watches the rel configuration file, such as "myapp/config.toml", and returns the channel of its
+// changes and the cancel func which stops the watcher.
+//
+// The file is resolved once by xdgbasedir.SearchConfigFile, so the file must exist. Its parent directory is watched,
+// so the file removed and created again, such as the atomic save via rename, keeps being watched. The events are
+// not coalesced, and a single save may emit several Modified events. The channel is closed after the cancel func
+// is called, and calling the cancel func twice is no-op.
+func WatchConfig(rel string) (<-chan Event, func(), error) {
+	return WatchConfigWith(xdgbasedir.New(), rel)
+}
+
+// WatchConfigWith is WatchConfig which resolves the rel configuration file by f, such as the *xdgbasedir.XDG
+// configured by the options, the *xdgbasedir.App for rel relative to the application directory, or
+// xdgtest.MapFinder in the unit tests.
+func WatchConfigWith(f xdgbasedir.Finder, rel string) (<-chan Event, func(), error) {
+	path, err := f.SearchConfigFile(rel)
+	if err != nil {
+		return nil, nil, err
+	}
+	return watchFile(path)
+}
+
+// watchFile watches the path file via its parent directory.
+func watchFile(path string) (<-chan Event, func(), error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := w.Add(filepath.Dir(path)); err != nil {
+		w.Close()
+		return nil, nil, err
+	}
+
+	events := make(chan Event)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(events)
+
+		for {
+			var ev Event
+			select {
+			case <-done:
+				return
+			case e, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(e.Name) != path {
+					continue
+				}
+				switch {
+				case e.Has(fsnotify.Create), e.Has(fsnotify.Write):
+					ev = Event{Path: path, Op: Modified}
+				case e.Has(fsnotify.Remove), e.Has(fsnotify.Rename):
+					ev = Event{Path: path, Op: Removed}
+				default:
+					continue
+				}
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				ev = Event{Path: path, Err: err}
+			}
+
+			select {
+			case events <- ev:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			close(done)
+			w.Close()
+			wg.Wait()
+		})
+	}
+	return events, cancel, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2018 The go-xdgbasedir Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdgwatch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	xdgbasedir "github.com/zchee/go-xdgbasedir"
+	"github.com/zchee/go-xdgbasedir/xdgtest"
+)
+
+// waitOp waits for the op event of path on events, skipping the other events.
+func waitOp(t *testing.T, events <-chan Event, path string, op Op) {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				t.Fatalf("events closed while waiting for %v", op)
+			}
+			if ev.Err != nil {
+				t.Fatal(ev.Err)
+			}
+			if ev.Path != path {
+				t.Fatalf("event path = %q, want %q", ev.Path, path)
+			}
+			if ev.Op == op {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %v", op)
+		}
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_CONFIG_DIRS", filepath.Join(home, "etc"))
+
+	path := filepath.Join(home, "myapp", "config.toml")
+	if err := xdgbasedir.WriteConfigFile("myapp/config.toml", []byte("v1"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	sibling := filepath.Join(home, "myapp", "other.toml")
+
+	events, cancel, err := WatchConfig("myapp/config.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	if err := os.WriteFile(sibling, []byte("ignored"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("v2"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	waitOp(t, events, path, Modified)
+
+	// the atomic save renames the temporary file over the path
+	if err := xdgbasedir.WriteConfigFile("myapp/config.toml", []byte("v3"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	waitOp(t, events, path, Modified)
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	waitOp(t, events, path, Removed)
+
+	if err := os.WriteFile(path, []byte("v4"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	waitOp(t, events, path, Modified)
+
+	cancel()
+	cancel()
+	for range events {
+	}
+}
+
+func TestWatchConfigNotFound(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_CONFIG_DIRS", filepath.Join(home, "etc"))
+
+	if _, _, err := WatchConfig("myapp/missing.toml"); err == nil {
+		t.Error("WatchConfig() expected error")
+	}
+}
+
+func TestWatchConfigWith(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("v1"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	f := xdgtest.MapFinder{Config: map[string][]string{"myapp/config.toml": {path}}}
+
+	events, cancel, err := WatchConfigWith(f, "myapp/config.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	if err := os.WriteFile(path, []byte("v2"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	waitOp(t, events, path, Modified)
+
+	if _, _, err := WatchConfigWith(f, "myapp/missing.toml"); err == nil {
+		t.Error("WatchConfigWith() expected error")
+	}
+}